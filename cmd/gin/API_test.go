@@ -37,12 +37,17 @@ func TestCRUDEmployee(t *testing.T) {
 		Addr:                 "127.0.0.1:3306",
 		DBName:               "esmdb",
 		AllowNativePasswords: true,
+		ParseTime:            true,
 	}
 	empStore, err := NewEmployeeStore(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
-	empHandler := NewEmployeeHandler(empStore)
+	scaleStore, err := NewSkillScaleStore(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	empHandler := NewEmployeeHandler(empStore, scaleStore, nil)
 
 	mockResponse := `{
     "rows_affected": 1
@@ -226,6 +231,7 @@ func TestCRUDProject(t *testing.T) {
 		Addr:                 "127.0.0.1:3306",
 		DBName:               "esmdb",
 		AllowNativePasswords: true,
+		ParseTime:            true,
 	}
 	projStore, err := NewProjectStore(cfg)
 	if err != nil {
@@ -311,6 +317,7 @@ func TestCRUDClient(t *testing.T) {
 		Addr:                 "127.0.0.1:3306",
 		DBName:               "esmdb",
 		AllowNativePasswords: true,
+		ParseTime:            true,
 	}
 	clientStore, err := NewClientStore(cfg)
 	if err != nil {
@@ -386,6 +393,7 @@ func TestCRUDSkill(t *testing.T) {
 		Addr:                 "127.0.0.1:3306",
 		DBName:               "esmdb",
 		AllowNativePasswords: true,
+		ParseTime:            true,
 	}
 	skillStore, err := NewSkillStore(cfg)
 	if err != nil {
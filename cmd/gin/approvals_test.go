@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// reviewingChangeStore fails every review with the configured error. The principal ada is the employee 2, grace is
+// not an employee.
+type reviewingChangeStore struct {
+	skillChangeStore
+	err error
+}
+
+func (s reviewingChangeStore) Caller(ctx context.Context, principal string) (int64, error) {
+	if principal == "ada" {
+		return 2, nil
+	}
+	return -1, sql.ErrNoRows
+}
+
+func (s reviewingChangeStore) Review(ctx context.Context, requestId int64, review instances.SkillReview, approve bool) (instances.SkillChangeRequest, error) {
+	if s.err != nil {
+		return instances.SkillChangeRequest{}, s.err
+	}
+	return instances.SkillChangeRequest{RequestId: requestId, Status: instances.ChangeRejected, ReviewerId: &review.ReviewerId}, nil
+}
+
+func TestRejectChangeStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		principal string
+		body      string
+		err       error
+		want      int
+		contains  string
+	}{
+		{name: "rejected", principal: "ada", body: `{"comment": "no evidence"}`, want: http.StatusOK, contains: `"reviewer_id": 2`},
+		{name: "reviewer in the body", principal: "ada", body: `{"reviewer_id": 5}`, want: http.StatusOK, contains: `"reviewer_id": 2`},
+		{name: "anonymous", body: `{"reviewer_id": 2}`, want: http.StatusUnauthorized},
+		{name: "not an employee", principal: "grace", body: `{}`, want: http.StatusForbidden},
+		{name: "unknown request", principal: "ada", body: `{}`, err: sql.ErrNoRows, want: http.StatusNotFound},
+		{name: "already reviewed", principal: "ada", body: `{}`, err: errChangeReviewed, want: http.StatusConflict},
+		{name: "not an owner", principal: "ada", body: `{}`, err: errNotApprover, want: http.StatusForbidden},
+		{name: "own change", principal: "ada", body: `{}`, err: errOwnChange, want: http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewSkillChangeHandler(reviewingChangeStore{err: tc.err}, nil)
+			eng := gin.New()
+			eng.Use(func(context *gin.Context) {
+				if tc.principal != "" {
+					context.Set(principalKey, tc.principal)
+				}
+			})
+			eng.POST("/v1/skillChangeRequests/:id/reject", h.rejectChange)
+
+			req, _ := http.NewRequest("POST", "/v1/skillChangeRequests/7/reject", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tc.contains)
+		})
+	}
+}
+
+func TestNeedsApproval(t *testing.T) {
+	h := NewEmployeeHandler(nil, nil, reviewingChangeStore{})
+	for _, tc := range []struct {
+		name       string
+		principal  string
+		employeeId int64
+		source     string
+		want       bool
+	}{
+		{name: "self-assessed", principal: "ada", employeeId: 2, source: instances.AssessmentSelf, want: true},
+		{name: "own level as the manager", principal: "ada", employeeId: 2, source: instances.AssessmentManager, want: true},
+		{name: "manager", principal: "ada", employeeId: 1, source: instances.AssessmentManager},
+		{name: "peer", principal: "ada", employeeId: 1, source: instances.AssessmentPeer},
+		{name: "no source", principal: "ada", employeeId: 1, want: true},
+		{name: "self-assessed for another employee", principal: "ada", employeeId: 1, source: instances.AssessmentSelf, want: true},
+		{name: "anonymous", employeeId: 1, source: instances.AssessmentManager, want: true},
+		{name: "not an employee", principal: "grace", employeeId: 1, source: instances.AssessmentCertification},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			context, _ := gin.CreateTestContext(httptest.NewRecorder())
+			context.Request, _ = http.NewRequest("PUT", "/", nil)
+			if tc.principal != "" {
+				context.Set(principalKey, tc.principal)
+			}
+			pending, err := h.needsApproval(context, tc.employeeId, instances.EmployeeSkill{SkillId: 7, SkillLevel: 3, Source: tc.source})
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.want, pending)
+			}
+		})
+	}
+
+	pending, err := NewEmployeeHandler(nil, nil, nil).needsApproval(&gin.Context{}, 2, instances.EmployeeSkill{SkillId: 7})
+	assert.NoError(t, err)
+	assert.False(t, pending, "without the approval workflow")
+}
+
+func TestReviewAuthenticatedByBearerToken(t *testing.T) {
+	tokens, err := loadTokens(writeTokenFile(t, "ada s3cret\ngrace 0ther\n"))
+	assert.NoError(t, err)
+	eng := gin.New()
+	eng.Use(authenticate(tokens))
+	eng.POST("/v1/skillChangeRequests/:id/reject", NewSkillChangeHandler(reviewingChangeStore{}, nil).rejectChange)
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{name: "skill owner", authorization: "Bearer s3cret", want: http.StatusOK},
+		{name: "not an employee", authorization: "Bearer 0ther", want: http.StatusForbidden},
+		{name: "anonymous", want: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/v1/skillChangeRequests/7/reject", strings.NewReader(`{}`))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestSkillApprovalNeedsTokens(t *testing.T) {
+	t.Setenv("SKILL_APPROVAL", "true")
+	t.Setenv("AUTH_TOKEN_FILE", "")
+	_, err := loadConfig()
+	assert.ErrorContains(t, err, "AUTH_TOKEN_FILE")
+
+	t.Setenv("AUTH_TOKEN_FILE", "/etc/esm/tokens")
+	cfg, err := loadConfig()
+	if assert.NoError(t, err) {
+		assert.True(t, cfg.SkillApproval)
+	}
+}
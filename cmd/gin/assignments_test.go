@@ -0,0 +1,43 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithDefaults(t *testing.T) {
+	ep := withDefaults(instances.EmployeeProject{ProjectId: 1, ProjectRole: "Developer"})
+	assert.Equal(t, today(), *ep.StartDate)
+	assert.Nil(t, ep.EndDate)
+	assert.Equal(t, 100, ep.Allocation)
+
+	start := instances.NewDate(2024, 1, 15)
+	ep = withDefaults(instances.EmployeeProject{StartDate: &start, Allocation: 50})
+	assert.Equal(t, start, *ep.StartDate)
+	assert.Equal(t, 50, ep.Allocation)
+}
+
+func TestKeepStint(t *testing.T) {
+	start, end := instances.NewDate(2024, 1, 1), instances.NewDate(2024, 6, 30)
+	current := instances.EmployeeProject{AssignmentId: 3, ProjectId: 5, ProjectRole: "Developer", StartDate: &start,
+		EndDate: &end, Allocation: 50}
+
+	// a new allocation alone leaves the ended stint ended
+	ep := keepStint(current, instances.EmployeeProject{AssignmentId: 3, Allocation: 80})
+	assert.Equal(t, instances.EmployeeProject{AssignmentId: 3, ProjectId: 5, ProjectRole: "Developer", StartDate: &start,
+		EndDate: &end, Allocation: 80}, ep)
+
+	later := instances.NewDate(2024, 9, 30)
+	ep = keepStint(current, instances.EmployeeProject{AssignmentId: 3, ProjectRole: "Lead", EndDate: &later})
+	assert.Equal(t, "Lead", ep.ProjectRole)
+	assert.Equal(t, later, *ep.EndDate)
+	assert.Equal(t, 50, ep.Allocation)
+}
+
+func TestAssignmentCondition(t *testing.T) {
+	assert.Empty(t, assignmentCondition(""))
+	assert.Contains(t, assignmentCondition(instances.AssignmentPast), "b.end_date < CURDATE()")
+	assert.Contains(t, assignmentCondition(instances.AssignmentUpcoming), "b.start_date > CURDATE()")
+	assert.Contains(t, assignmentCondition(instances.AssignmentCurrent), "b.end_date IS NULL")
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// errInvalidToken is returned for a bearer token that is not in the token file
+var errInvalidToken = errors.New("invalid bearer token")
+
+// tokenPrincipals maps the SHA-256 of each bearer token to the principal it authenticates. Tokens are hashed so that
+// looking them up takes no longer for a nearly correct token than for any other.
+type tokenPrincipals map[[sha256.Size]byte]string
+
+// loadTokens reads a token file: one "<principal> <token>" per line, blank lines and lines starting with # are
+// skipped. The principal of an employee is their email.
+func loadTokens(path string) (tokenPrincipals, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := make(tokenPrincipals)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a principal and a token", path, n)
+		}
+		key := sha256.Sum256([]byte(fields[1]))
+		if _, ok := tokens[key]; ok {
+			return nil, fmt.Errorf("%s:%d: the token of %s is used twice", path, n, fields[0])
+		}
+		tokens[key] = fields[0]
+	}
+	return tokens, scanner.Err()
+}
+
+// authenticate is a gin middleware that stores the principal of the bearer token of a request under principalKey.
+// Requests without a token stay anonymous, requests with an unknown token are rejected.
+func authenticate(tokens tokenPrincipals) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		principal, known := tokens[sha256.Sum256([]byte(token))]
+		if !ok || !known {
+			c.Header("WWW-Authenticate", `Bearer realm="esm"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": errInvalidToken.Error()})
+			return
+		}
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTokenFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTokens(t *testing.T) {
+	tokens, err := loadTokens(writeTokenFile(t, "# callers\nada@example.com s3cret\n\nreporting-job 0ther\n"))
+	if assert.NoError(t, err) {
+		assert.Len(t, tokens, 2)
+	}
+	_, err = loadTokens(writeTokenFile(t, "ada@example.com\n"))
+	assert.ErrorContains(t, err, ":1: expected a principal and a token")
+	_, err = loadTokens(writeTokenFile(t, "ada@example.com s3cret\ngrace@example.com s3cret\n"))
+	assert.ErrorContains(t, err, "used twice")
+}
+
+func TestAuthenticatedAccessLog(t *testing.T) {
+	tokens, err := loadTokens(writeTokenFile(t, "ada@example.com s3cret\n"))
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "json", "info")
+	assert.NoError(t, err)
+
+	eng := gin.New()
+	eng.Use(requestLogger(logger), authenticate(tokens))
+	eng.GET("/employees/:id", func(context *gin.Context) {
+		context.Status(http.StatusNoContent)
+	})
+
+	for _, tc := range []struct {
+		name          string
+		authorization string
+		want          int
+		principal     any
+	}{
+		{name: "valid token", authorization: "Bearer s3cret", want: http.StatusNoContent, principal: "ada@example.com"},
+		{name: "anonymous", want: http.StatusNoContent},
+		{name: "unknown token", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "other scheme", authorization: "Basic s3cret", want: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req, _ := http.NewRequest("GET", "/employees/1", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+
+			var accessLine map[string]any
+			assert.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &accessLine))
+			assert.Equal(t, tc.principal, accessLine["principal"])
+		})
+	}
+}
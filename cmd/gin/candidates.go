@@ -0,0 +1,87 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"sort"
+)
+
+// The score of a candidate is out of 100 and made of three parts:
+//   - skills: the share of the project's requirements the employee fills. A skill below the minimum level counts
+//     for half of its level's share of the minimum, e.g. level 2 of a required 4 counts as a quarter.
+//   - experience: other projects of the same focus area the employee worked on, full points from three projects on
+//   - availability: the share of the employee's time not allocated over the period
+const (
+	skillWeight              = 60
+	experienceWeight         = 15
+	availabilityWeight       = 25
+	relevantProjectsForScore = 3
+)
+
+// rankCandidates scores every employee of pool against the requirements and returns the best first. experience
+// maps employees to their relevant projects, allocation to their average allocation over the period.
+func rankCandidates(reqs []instances.ProjectRequirement, pool []instances.EmployeeMatch, experience map[int64][]int64,
+	allocation map[int64]float64) []instances.Candidate {
+	candidates := make([]instances.Candidate, 0, len(pool))
+	for _, m := range pool {
+		levels := make(map[int64]int64, len(m.Skills))
+		for _, skill := range m.Skills {
+			levels[int64(skill.SkillId)] = int64(skill.SkillLevel)
+		}
+
+		c := instances.Candidate{
+			Employee:         m.Employee,
+			Matched:          []instances.SkillMatch{},
+			Missing:          []instances.SkillMatch{},
+			RelevantProjects: experience[m.Employee.EmployeeId],
+		}
+		var filled float64
+		for _, req := range reqs {
+			level := levels[req.SkillId]
+			match := instances.SkillMatch{SkillId: req.SkillId, Skill: req.Skill, MinLevel: req.MinLevel, Level: level}
+			switch {
+			case level > 0 && level >= req.MinLevel:
+				filled++
+				c.Matched = append(c.Matched, match)
+			case level > 0:
+				filled += float64(level) / float64(req.MinLevel) / 2
+				c.Missing = append(c.Missing, match)
+			default:
+				c.Missing = append(c.Missing, match)
+			}
+		}
+		if len(reqs) > 0 {
+			c.SkillScore = round1(skillWeight * filled / float64(len(reqs)))
+		}
+		if c.RelevantProjects == nil {
+			c.RelevantProjects = []int64{}
+		}
+		c.ExperienceScore = round1(experienceWeight * float64(min(len(c.RelevantProjects), relevantProjectsForScore)) /
+			relevantProjectsForScore)
+		c.Availability = round1(max(0, 100-allocation[m.Employee.EmployeeId]))
+		c.AvailabilityScore = round1(availabilityWeight * c.Availability / 100)
+		c.Score = round1(c.SkillScore + c.ExperienceScore + c.AvailabilityScore)
+		candidates = append(candidates, c)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Employee.EmployeeId < candidates[j].Employee.EmployeeId
+	})
+	return candidates
+}
+
+// averageAllocation returns the average allocation of each employee with stints over the period, in percent
+func averageAllocation(stints []stint, from, to instances.Date) map[int64]float64 {
+	n := days(from, to)
+	result := make(map[int64]float64)
+	for employeeId, load := range dailyAllocation(stints, from, to) {
+		sum := 0
+		for _, percent := range load {
+			sum += percent
+		}
+		result[employeeId] = float64(sum) / float64(n)
+	}
+	return result
+}
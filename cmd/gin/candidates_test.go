@@ -0,0 +1,44 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRankCandidates(t *testing.T) {
+	const golang, terraform = 1, 2
+	reqs := []instances.ProjectRequirement{
+		{SkillId: golang, Skill: "Go", MinLevel: 4, Headcount: 2},
+		{SkillId: terraform, Skill: "Terraform", MinLevel: 2, Headcount: 1},
+	}
+	pool := []instances.EmployeeMatch{
+		// a senior Go developer, fully booked
+		member(10, instances.Skill{SkillId: golang, SkillLevel: 5}),
+		// a junior with both skills and time on their hands
+		member(11, instances.Skill{SkillId: golang, SkillLevel: 2}, instances.Skill{SkillId: terraform, SkillLevel: 3}),
+	}
+	experience := map[int64][]int64{10: {3, 4, 5, 6}}
+	allocation := map[int64]float64{10: 100, 11: 20}
+
+	ranked := rankCandidates(reqs, pool, experience, allocation)
+	assert.Len(t, ranked, 2)
+
+	junior := ranked[0]
+	assert.Equal(t, int64(11), junior.Employee.EmployeeId)
+	// Terraform fills a half, Go 2 of 4 a quarter of the other half
+	assert.Equal(t, 37.5, junior.SkillScore)
+	assert.Equal(t, 80.0, junior.Availability)
+	assert.Equal(t, 20.0, junior.AvailabilityScore)
+	assert.Zero(t, junior.ExperienceScore)
+	assert.Equal(t, 57.5, junior.Score)
+	assert.Equal(t, []instances.SkillMatch{{SkillId: golang, Skill: "Go", MinLevel: 4, Level: 2}}, junior.Missing)
+
+	senior := ranked[1]
+	assert.Equal(t, 30.0, senior.SkillScore)
+	assert.Equal(t, 15.0, senior.ExperienceScore)
+	assert.Zero(t, senior.AvailabilityScore)
+	assert.Equal(t, 45.0, senior.Score)
+	assert.Len(t, senior.Matched, 1)
+	assert.Equal(t, int64(0), senior.Missing[0].Level)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// maxReportDays bounds the period of a capacity report, the allocation is computed for every day of it
+const maxReportDays = 366
+
+// stint is the part of an assignment the capacity report reads. A nil Start or End is an open end.
+type stint struct {
+	EmployeeId int64
+	FocusArea  string
+	Start      *instances.Date
+	End        *instances.Date
+	Allocation int
+}
+
+// reportPeriod returns the period of a capacity report, the current month unless the filter sets it
+func reportPeriod(filter instances.CapacityFilter, now time.Time) (instances.Date, instances.Date, error) {
+	from := instances.NewDate(now.Year(), now.Month(), 1)
+	if filter.From != nil {
+		from = *filter.From
+	}
+	to := instances.Date{Time: from.AddDate(0, 1, -1)}
+	if filter.To != nil {
+		to = *filter.To
+	}
+	if err := validation.Period("from", from, "to", &to); err != nil {
+		return from, to, err
+	}
+	if days(from, to) > maxReportDays {
+		return from, to, validation.Errors{{
+			Field:   "to",
+			Rule:    "max",
+			Param:   fmt.Sprint(maxReportDays),
+			Message: fmt.Sprintf("the period must not be longer than %d days", maxReportDays),
+		}}
+	}
+	return from, to, nil
+}
+
+// days counts the days from from to to, both included
+func days(from, to instances.Date) int {
+	return int(to.Sub(from.Time).Hours()/24) + 1
+}
+
+// clip returns the part of the stint within from and to, ok is false if they do not overlap
+func (s stint) clip(from, to instances.Date) (instances.Date, instances.Date, bool) {
+	start, end := from, to
+	if s.Start != nil && s.Start.After(from.Time) {
+		start = *s.Start
+	}
+	if s.End != nil && s.End.Before(to.Time) {
+		end = *s.End
+	}
+	return start, end, !end.Before(start.Time)
+}
+
+// dailyAllocation sums the allocation of each employee on each day of the period
+func dailyAllocation(stints []stint, from, to instances.Date) map[int64][]int {
+	n := days(from, to)
+	load := make(map[int64][]int)
+	for _, s := range stints {
+		start, end, ok := s.clip(from, to)
+		if !ok {
+			continue
+		}
+		if load[s.EmployeeId] == nil {
+			load[s.EmployeeId] = make([]int, n)
+		}
+		first := days(from, start) - 1
+		for i := first; i < first+days(start, end); i++ {
+			load[s.EmployeeId][i] += s.Allocation
+		}
+	}
+	return load
+}
+
+// round1 rounds a percentage or headcount to one decimal
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// utilization computes the allocation of every employee over the period, in the order of employees
+func utilization(employees []instances.Employee, stints []stint, from, to instances.Date) []instances.Utilization {
+	load := dailyAllocation(stints, from, to)
+	n := days(from, to)
+	result := make([]instances.Utilization, 0, len(employees))
+	for _, emp := range employees {
+		u := instances.Utilization{Employee: emp}
+		sum := 0
+		for _, percent := range load[emp.EmployeeId] {
+			sum += percent
+			u.PeakAllocation = max(u.PeakAllocation, percent)
+			if percent > 100 {
+				u.OverbookedDays++
+			}
+		}
+		u.Allocation = round1(float64(sum) / float64(n))
+		u.Overbooked = u.OverbookedDays > 0
+		result = append(result, u)
+	}
+	return result
+}
+
+// focusAreaUtilization computes for each month of the period how many full-time employees the projects of each
+// focus area keep busy. Months cut by the period only count the days within it.
+func focusAreaUtilization(stints []stint, headcount int, from, to instances.Date) []instances.FocusAreaUtilization {
+	result := []instances.FocusAreaUtilization{}
+	for monthStart := instances.NewDate(from.Year(), from.Month(), 1); !monthStart.After(to.Time); {
+		start, end := monthStart, instances.Date{Time: monthStart.AddDate(0, 1, -1)}
+		if start.Before(from.Time) {
+			start = from
+		}
+		if end.After(to.Time) {
+			end = to
+		}
+
+		// allocation days per focus area, 100 is one employee for one day
+		allocated := make(map[string]int)
+		for _, s := range stints {
+			if stintStart, stintEnd, ok := s.clip(start, end); ok {
+				allocated[s.FocusArea] += s.Allocation * days(stintStart, stintEnd)
+			}
+		}
+		areas := make([]string, 0, len(allocated))
+		for area := range allocated {
+			areas = append(areas, area)
+		}
+		sort.Strings(areas)
+		for _, area := range areas {
+			fte := float64(allocated[area]) / float64(100*days(start, end))
+			row := instances.FocusAreaUtilization{
+				Month:     monthStart.Format("2006-01"),
+				FocusArea: area,
+				FTE:       round1(fte),
+			}
+			if headcount > 0 {
+				row.Utilization = round1(100 * fte / float64(headcount))
+			}
+			result = append(result, row)
+		}
+		monthStart = instances.Date{Time: monthStart.AddDate(0, 1, 0)}
+	}
+	return result
+}
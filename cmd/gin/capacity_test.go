@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) *instances.Date {
+	d := instances.NewDate(year, month, day)
+	return &d
+}
+
+var testStints = []stint{
+	// employee 1 is on two projects at once for the first ten days of March
+	{EmployeeId: 1, FocusArea: "Backend", Start: date(2025, 1, 1), Allocation: 100},
+	{EmployeeId: 1, FocusArea: "Data", Start: date(2025, 3, 1), End: date(2025, 3, 10), Allocation: 50},
+	// employee 2 rolls off at the end of February
+	{EmployeeId: 2, FocusArea: "Data", End: date(2025, 2, 28), Allocation: 50},
+}
+
+var testEmployees = []instances.Employee{
+	{EmployeeId: 1, Name: "Ada", Lastname: "Lovelace"},
+	{EmployeeId: 2, Name: "Alan", Lastname: "Turing"},
+	{EmployeeId: 3, Name: "Grace", Lastname: "Hopper"},
+}
+
+func TestReportPeriod(t *testing.T) {
+	now := time.Date(2025, time.February, 14, 12, 0, 0, 0, time.UTC)
+	from, to, err := reportPeriod(instances.CapacityFilter{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2025-02-01", from.String())
+	assert.Equal(t, "2025-02-28", to.String())
+
+	_, _, err = reportPeriod(instances.CapacityFilter{From: date(2025, 3, 1), To: date(2025, 2, 1)}, now)
+	assert.Error(t, err)
+	_, _, err = reportPeriod(instances.CapacityFilter{From: date(2025, 1, 1), To: date(2026, 1, 2)}, now)
+	assert.Error(t, err)
+}
+
+func TestUtilization(t *testing.T) {
+	rows := utilization(testEmployees, testStints, *date(2025, 3, 1), *date(2025, 3, 31))
+	assert.Len(t, rows, 3)
+
+	assert.Equal(t, 150, rows[0].PeakAllocation)
+	assert.Equal(t, 10, rows[0].OverbookedDays)
+	assert.True(t, rows[0].Overbooked)
+	// (31*100 + 10*50) / 31
+	assert.Equal(t, 116.1, rows[0].Allocation)
+
+	assert.Zero(t, rows[1].Allocation)
+	assert.False(t, rows[1].Overbooked)
+	assert.Zero(t, rows[2].PeakAllocation)
+}
+
+func TestFocusAreaUtilization(t *testing.T) {
+	rows := focusAreaUtilization(testStints, len(testEmployees), *date(2025, 2, 15), *date(2025, 3, 31))
+	assert.Equal(t, []instances.FocusAreaUtilization{
+		{Month: "2025-02", FocusArea: "Backend", FTE: 1, Utilization: 33.3},
+		{Month: "2025-02", FocusArea: "Data", FTE: 0.5, Utilization: 16.7},
+		{Month: "2025-03", FocusArea: "Backend", FTE: 1, Utilization: 33.3},
+		// 10 days at 50% in a month of 31 days
+		{Month: "2025-03", FocusArea: "Data", FTE: 0.2, Utilization: 5.4},
+	}, rows)
+}
+
+// fixedReportStore serves the test employees and stints
+type fixedReportStore struct {
+	reportStore
+}
+
+func (fixedReportStore) Employees(context.Context) ([]instances.Employee, error) {
+	return testEmployees, nil
+}
+
+func (fixedReportStore) Stints(context.Context, instances.Date, instances.Date) ([]stint, error) {
+	return testStints, nil
+}
+
+func TestUtilizationCSV(t *testing.T) {
+	router := gin.New()
+	router.GET("/v1/reports/utilization", NewReportHandler(fixedReportStore{}).getUtilization)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/reports/utilization?from=2025-03-01&to=2025-03-31&overbooked=true", nil)
+	req.Header.Set("Accept", "text/csv")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+	assert.Equal(t, "employee_id,name,lastname,focus_area,allocation,peak_allocation,overbooked_days,overbooked\n"+
+		"1,Ada,Lovelace,,116.1,150,10,true\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/v1/reports/utilization?from=2025-03-32", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// config holds the runtime settings of the server. Everything is read from environment variables so that the
+// database credentials (DBUSER, DBPASS) and the rest of the settings are configured in the same way.
+type config struct {
+	// LogLevel is one of debug, info, warn, error
+	LogLevel string
+	// LogFormat is either text or json
+	LogFormat string
+
+	// TracesExporter is one of none, otlp, stdout, file. The OTLP exporter itself is configured
+	// by the standard OTEL_EXPORTER_OTLP_* variables.
+	TracesExporter string
+	// TraceFile is where the file exporter writes spans
+	TraceFile string
+
+	// MigrateOnStart applies pending schema migrations when the server starts
+	MigrateOnStart bool
+	// DBMaxOpenConns limits the open connections of each store's pool, 0 means unlimited
+	DBMaxOpenConns int
+
+	// SkillApproval makes skill levels pending until a skill owner approves them, unless an authenticated caller
+	// assesses another employee. It needs AuthTokenFile, reviewers are authenticated callers.
+	SkillApproval bool
+
+	// AuthTokenFile lists the bearer tokens of the callers, one "<principal> <token>" per line. Without it every
+	// request is anonymous and access log lines name no principal.
+	AuthTokenFile string
+
+	// CVTemplateDir holds cv.md.tmpl and cv.html.tmpl replacing the built-in CV templates, each is optional
+	CVTemplateDir string
+
+	// HTTPAddr is the address the server listens on
+	HTTPAddr          string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	MaxBodyBytes      int64
+	// TLSCertFile and TLSKeyFile enable https when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+	// ShutdownDelay is how long the server keeps serving with a failing readiness probe before it stops
+	// accepting connections, ShutdownTimeout how long it then waits for in-flight requests
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+}
+
+func loadConfig() (config, error) {
+	cfg := config{
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "text"),
+
+		TracesExporter: getEnv("OTEL_TRACES_EXPORTER", "none"),
+		TraceFile:      getEnv("TRACE_FILE", "traces.jsonl"),
+
+		AuthTokenFile: os.Getenv("AUTH_TOKEN_FILE"),
+		CVTemplateDir: os.Getenv("CV_TEMPLATE_DIR"),
+
+		HTTPAddr:    getEnv("HTTP_ADDR", "localhost:9090"),
+		TLSCertFile: os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:  os.Getenv("TLS_KEY_FILE"),
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return config{}, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	var err error
+	if cfg.MigrateOnStart, err = getEnvBool("MIGRATE_ON_START", true); err != nil {
+		return config{}, err
+	}
+	if cfg.DBMaxOpenConns, err = getEnvInt("DB_MAX_OPEN_CONNS", 20); err != nil {
+		return config{}, err
+	}
+	if cfg.SkillApproval, err = getEnvBool("SKILL_APPROVAL", false); err != nil {
+		return config{}, err
+	}
+	// pending levels are reviewed by authenticated skill owners and managers, without tokens nobody could
+	if cfg.SkillApproval && cfg.AuthTokenFile == "" {
+		return config{}, fmt.Errorf("SKILL_APPROVAL needs AUTH_TOKEN_FILE to identify the reviewers")
+	}
+	if cfg.ReadTimeout, err = getEnvDuration("HTTP_READ_TIMEOUT", 15*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.ReadHeaderTimeout, err = getEnvDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.WriteTimeout, err = getEnvDuration("HTTP_WRITE_TIMEOUT", 30*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.IdleTimeout, err = getEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return config{}, err
+	}
+	if cfg.MaxHeaderBytes, err = getEnvInt("HTTP_MAX_HEADER_BYTES", 1<<20); err != nil {
+		return config{}, err
+	}
+	maxBody, err := getEnvInt("HTTP_MAX_BODY_BYTES", 1<<20)
+	if err != nil {
+		return config{}, err
+	}
+	cfg.MaxBodyBytes = int64(maxBody)
+	if cfg.ShutdownDelay, err = getEnvDuration("SHUTDOWN_DELAY", 0); err != nil {
+		return config{}, err
+	}
+	if cfg.ShutdownTimeout, err = getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second); err != nil {
+		return config{}, err
+	}
+	return cfg, nil
+}
+
+// getEnv returns the value of the environment variable key, or def if it is not set or empty
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvBool(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %q is not a boolean", key, v)
+	}
+	return b, nil
+}
+
+func getEnvInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a number", key, v)
+	}
+	return i, nil
+}
+
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not a duration", key, v)
+	}
+	return d, nil
+}
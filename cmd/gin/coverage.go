@@ -0,0 +1,73 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"sort"
+)
+
+// coverage matches the team of a project against its requirements. An employee fills at most one requirement of
+// each skill, the requirements with the highest minimum level are filled first, so a senior is not counted as the
+// junior the project also asked for. Requirements of different skills can be filled by the same employee.
+func coverage(projectId int64, reqs []instances.ProjectRequirement, team []instances.EmployeeMatch) instances.ProjectCoverage {
+	order := make([]int, len(reqs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := reqs[order[a]], reqs[order[b]]
+		if ra.SkillId != rb.SkillId {
+			return ra.SkillId < rb.SkillId
+		}
+		return ra.MinLevel > rb.MinLevel
+	})
+
+	type member struct {
+		employee instances.Employee
+		level    int
+	}
+	// used records which employees already fill a requirement of a skill
+	used := make(map[int64]map[int64]bool)
+	result := instances.ProjectCoverage{
+		ProjectId:    projectId,
+		Complete:     true,
+		Requirements: make([]instances.RequirementCoverage, len(reqs)),
+		Gaps:         []instances.RequirementCoverage{},
+	}
+	for _, i := range order {
+		req := reqs[i]
+		if used[req.SkillId] == nil {
+			used[req.SkillId] = make(map[int64]bool)
+		}
+		var qualified []member
+		for _, m := range team {
+			for _, skill := range m.Skills {
+				if int64(skill.SkillId) == req.SkillId && int64(skill.SkillLevel) >= req.MinLevel &&
+					!used[req.SkillId][m.Employee.EmployeeId] {
+					qualified = append(qualified, member{m.Employee, skill.SkillLevel})
+				}
+			}
+		}
+		sort.SliceStable(qualified, func(a, b int) bool {
+			return qualified[a].level > qualified[b].level
+		})
+
+		cov := instances.RequirementCoverage{Requirement: req, Employees: []instances.Employee{}}
+		for _, m := range qualified {
+			if cov.Covered == req.Headcount {
+				break
+			}
+			used[req.SkillId][m.employee.EmployeeId] = true
+			cov.Employees = append(cov.Employees, m.employee)
+			cov.Covered++
+		}
+		cov.Missing = req.Headcount - cov.Covered
+		result.Requirements[i] = cov
+	}
+	for _, cov := range result.Requirements {
+		if cov.Missing > 0 {
+			result.Complete = false
+			result.Gaps = append(result.Gaps, cov)
+		}
+	}
+	return result
+}
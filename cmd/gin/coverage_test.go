@@ -0,0 +1,39 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func member(id int64, skills ...instances.Skill) instances.EmployeeMatch {
+	return instances.EmployeeMatch{Employee: instances.Employee{EmployeeId: id}, Skills: skills}
+}
+
+func TestCoverage(t *testing.T) {
+	const golang, terraform = 1, 2
+	reqs := []instances.ProjectRequirement{
+		{RequirementId: 1, SkillId: golang, MinLevel: 2, Headcount: 1},
+		{RequirementId: 2, SkillId: golang, MinLevel: 4, Headcount: 2},
+		{RequirementId: 3, SkillId: terraform, MinLevel: 0, Headcount: 1},
+	}
+	team := []instances.EmployeeMatch{
+		member(10, instances.Skill{SkillId: golang, SkillLevel: 5}, instances.Skill{SkillId: terraform, SkillLevel: 1}),
+		member(11, instances.Skill{SkillId: golang, SkillLevel: 4}),
+	}
+
+	cov := coverage(7, reqs, team)
+	assert.False(t, cov.Complete)
+	// both seniors fill the senior requirement, nobody is left for the junior one
+	assert.Equal(t, 2, cov.Requirements[1].Covered)
+	assert.Equal(t, 0, cov.Requirements[0].Covered)
+	assert.Equal(t, 1, cov.Requirements[0].Missing)
+	// the same employee can fill a requirement of another skill
+	assert.Equal(t, []instances.Employee{{EmployeeId: 10}}, cov.Requirements[2].Employees)
+
+	assert.Len(t, cov.Gaps, 1)
+	assert.Equal(t, int64(1), cov.Gaps[0].Requirement.RequirementId)
+
+	team = append(team, member(12, instances.Skill{SkillId: golang, SkillLevel: 2}))
+	assert.True(t, coverage(7, reqs, team).Complete)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/pdf"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/cv.md.tmpl templates/cv.html.tmpl
+var builtinCVTemplates embed.FS
+
+// cvContentTypes are the content types of the CV formats
+var cvContentTypes = map[string]string{
+	instances.CVMarkdown: "text/markdown; charset=utf-8",
+	instances.CVHTML:     "text/html; charset=utf-8",
+	instances.CVPDF:      "application/pdf",
+}
+
+var cvExtensions = map[string]string{
+	instances.CVMarkdown: ".md",
+	instances.CVHTML:     ".html",
+	instances.CVPDF:      ".pdf",
+}
+
+// cvFuncs are the functions CV templates may call besides the builtin ones
+var cvFuncs = map[string]any{
+	"level":  skillLevel,
+	"period": period,
+}
+
+// skillLevel writes the level of a skill with its label if it has one, e.g. "4 (Advanced)"
+func skillLevel(skill instances.Skill) string {
+	if skill.SkillLevelLabel == "" {
+		return strconv.Itoa(skill.SkillLevel)
+	}
+	return fmt.Sprintf("%d (%s)", skill.SkillLevel, skill.SkillLevelLabel)
+}
+
+// period writes the months of a stint, e.g. "2023-04 to present"
+func period(start *instances.Date, end *instances.Date) string {
+	from, to := "unknown", "present"
+	if start != nil {
+		from = start.Format("2006-01")
+	}
+	if end != nil {
+		to = end.Format("2006-01")
+	}
+	return from + " to " + to
+}
+
+// cvTemplates renders CVs. PDFs are laid out from the Markdown, so a custom Markdown template changes both.
+type cvTemplates struct {
+	markdown *texttemplate.Template
+	html     *htmltemplate.Template
+}
+
+// loadCVTemplates parses the CV templates, taking cv.md.tmpl and cv.html.tmpl from dir where they exist and the
+// builtin ones otherwise
+func loadCVTemplates(dir string) (cvTemplates, error) {
+	md, err := readCVTemplate(dir, "cv.md.tmpl")
+	if err != nil {
+		return cvTemplates{}, err
+	}
+	html, err := readCVTemplate(dir, "cv.html.tmpl")
+	if err != nil {
+		return cvTemplates{}, err
+	}
+	var t cvTemplates
+	if t.markdown, err = texttemplate.New("cv.md.tmpl").Funcs(cvFuncs).Parse(md); err != nil {
+		return cvTemplates{}, err
+	}
+	if t.html, err = htmltemplate.New("cv.html.tmpl").Funcs(cvFuncs).Parse(html); err != nil {
+		return cvTemplates{}, err
+	}
+	return t, nil
+}
+
+func readCVTemplate(dir string, name string) (string, error) {
+	if dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(b), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	b, err := builtinCVTemplates.ReadFile("templates/" + name)
+	return string(b), err
+}
+
+// render writes cv in format
+func (t cvTemplates) render(cv instances.CV, format string) ([]byte, error) {
+	var b bytes.Buffer
+	switch format {
+	case instances.CVHTML:
+		if err := t.html.Execute(&b, cv); err != nil {
+			return nil, err
+		}
+	case instances.CVPDF:
+		if err := t.markdown.Execute(&b, cv); err != nil {
+			return nil, err
+		}
+		title := cv.Employee.Name + " " + cv.Employee.Lastname
+		return pdf.Render(title, pdf.FromMarkdown(b.String())), nil
+	default:
+		if err := t.markdown.Execute(&b, cv); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// buildCV prepares the profile of an employee for a CV: skills grouped by class, the most recent project first and
+// no secret projects. clients maps client ids to names.
+func buildCV(full instances.EmployeeFull, clients map[int64]string) instances.CV {
+	cv := instances.CV{Employee: full.Employee, Certifications: full.Certifications}
+
+	byClass := make(map[string][]instances.Skill)
+	for _, skill := range full.Skills {
+		byClass[skill.SkillClass] = append(byClass[skill.SkillClass], skill)
+	}
+	for class, skills := range byClass {
+		sort.Slice(skills, func(i, j int) bool {
+			if skills[i].SkillLevel != skills[j].SkillLevel {
+				return skills[i].SkillLevel > skills[j].SkillLevel
+			}
+			return skills[i].Skill < skills[j].Skill
+		})
+		cv.SkillGroups = append(cv.SkillGroups, instances.CVSkillGroup{SkillClass: class, Skills: skills})
+	}
+	sort.Slice(cv.SkillGroups, func(i, j int) bool { return cv.SkillGroups[i].SkillClass < cv.SkillGroups[j].SkillClass })
+
+	for i := len(full.Projects) - 1; i >= 0; i-- {
+		p := full.Projects[i]
+		if p.Project.IsSecret {
+			continue
+		}
+		cv.Projects = append(cv.Projects, instances.CVProject{
+			ProjectId:   p.Project.ProjectId,
+			Role:        p.EmployeeRole,
+			Client:      clients[int64(p.Project.ClientId)],
+			FocusArea:   p.Project.FocusArea,
+			Description: p.Project.Description,
+			StartDate:   p.StartDate,
+			EndDate:     p.EndDate,
+		})
+	}
+	return cv
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// cvFileName names the CV of an employee, e.g. cv-12-ada-lovelace.pdf
+func cvFileName(emp instances.Employee, format string) string {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(strings.ToLower(emp.Name+"-"+emp.Lastname), "-"), "-")
+	if name == "" {
+		return fmt.Sprintf("cv-%d%s", emp.EmployeeId, cvExtensions[format])
+	}
+	return fmt.Sprintf("cv-%d-%s%s", emp.EmployeeId, name, cvExtensions[format])
+}
+
+// cvFile is a rendered CV
+type cvFile struct {
+	Name string
+	Body []byte
+}
+
+// zipCVs packs rendered CVs into a ZIP archive
+func zipCVs(files []cvFile) ([]byte, error) {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, file := range files {
+		f, err := w.Create(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := f.Write(file.Body); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testProfile = instances.EmployeeFull{
+	Employee: instances.Employee{EmployeeId: 12, Name: "Ada", Lastname: "Lovelace", Email: "ada@example.com"},
+	Skills: []instances.Skill{
+		{SkillClass: "Programming", Skill: "Go", SkillLevel: 3, SkillLevelLabel: "Intermediate"},
+		{SkillClass: "Languages", Skill: "French", SkillLevel: 4},
+		{SkillClass: "Programming", Skill: "Ada", SkillLevel: 5, SkillLevelLabel: "Expert"},
+	},
+	Projects: []instances.ProjectFull{
+		{EmployeeRole: "Developer", Project: instances.Project{ProjectId: 1, ClientId: 7, FocusArea: "Cloud"},
+			StartDate: date(2023, 4, 1)},
+		{EmployeeRole: "Analyst", Project: instances.Project{ProjectId: 2, ClientId: 7, FocusArea: "Defense", IsSecret: true}},
+	},
+}
+
+func TestBuildCV(t *testing.T) {
+	cv := buildCV(testProfile, map[int64]string{7: "Acme"})
+	if assert.Len(t, cv.SkillGroups, 2) {
+		assert.Equal(t, "Languages", cv.SkillGroups[0].SkillClass)
+		assert.Equal(t, "Ada", cv.SkillGroups[1].Skills[0].Skill, "strongest skill first")
+	}
+	assert.Equal(t, []instances.CVProject{{ProjectId: 1, Role: "Developer", Client: "Acme", FocusArea: "Cloud",
+		StartDate: testProfile.Projects[0].StartDate}}, cv.Projects, "secret projects are left out")
+}
+
+func TestRenderCV(t *testing.T) {
+	templates, err := loadCVTemplates("")
+	assert.NoError(t, err)
+	cv := buildCV(testProfile, map[int64]string{7: "Acme"})
+
+	md, err := templates.render(cv, instances.CVMarkdown)
+	assert.NoError(t, err)
+	assert.Contains(t, string(md), "# Ada Lovelace")
+	assert.Contains(t, string(md), "- Ada: 5 (Expert)")
+	assert.Contains(t, string(md), "- **Developer** for Acme, 2023-04 to present (Cloud)")
+	assert.NotContains(t, string(md), "Defense")
+
+	html, err := templates.render(cv, instances.CVHTML)
+	assert.NoError(t, err)
+	assert.Contains(t, string(html), `<a href="mailto:ada@example.com">`)
+
+	pdf, err := templates.render(cv, instances.CVPDF)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+}
+
+func TestCustomCVTemplate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cv.md.tmpl"), []byte("{{.Employee.Lastname}}"), 0o644))
+	templates, err := loadCVTemplates(dir)
+	assert.NoError(t, err)
+
+	md, err := templates.render(buildCV(testProfile, nil), instances.CVMarkdown)
+	assert.NoError(t, err)
+	assert.Equal(t, "Lovelace", string(md))
+	html, err := templates.render(buildCV(testProfile, nil), instances.CVHTML)
+	assert.NoError(t, err)
+	assert.Contains(t, string(html), "<!DOCTYPE html>", "the builtin template is kept where none is given")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cv.html.tmpl"), []byte("{{.Broken"), 0o644))
+	_, err = loadCVTemplates(dir)
+	assert.Error(t, err)
+}
+
+func TestZipCVs(t *testing.T) {
+	name := cvFileName(instances.Employee{EmployeeId: 3, Name: "Zoë", Lastname: "O'Neil"}, instances.CVPDF)
+	assert.Equal(t, "cv-3-zo-o-neil.pdf", name)
+
+	archive, err := zipCVs([]cvFile{{Name: name, Body: []byte("%PDF-")}, {Name: "cv-4.md", Body: []byte("# B")}})
+	assert.NoError(t, err)
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if assert.NoError(t, err) && assert.Len(t, r.File, 2) {
+		assert.Equal(t, name, r.File[0].Name)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/xml"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Europass CVs are written in the Europass XML of the SkillsPassport schema, version 3.3. Only the parts the API has
+// data for are modelled.
+
+const europassNamespace = "http://europass.cedefop.europa.eu/Europass"
+
+// cefrLevel matches the levels of the Common European Framework of Reference, which Europass rates languages with
+var cefrLevel = regexp.MustCompile(`^[ABC][12]$`)
+
+type europassDocument struct {
+	XMLName      xml.Name            `xml:"SkillsPassport"`
+	Xmlns        string              `xml:"xmlns,attr"`
+	Locale       string              `xml:"locale,attr"`
+	DocumentInfo europassDocInfo     `xml:"DocumentInfo"`
+	LearnerInfo  europassLearnerInfo `xml:"LearnerInfo"`
+}
+
+type europassDocInfo struct {
+	DocumentType string `xml:"DocumentType"`
+	CreationDate string `xml:"CreationDate"`
+	XSDVersion   string `xml:"XSDVersion"`
+	Generator    string `xml:"Generator"`
+}
+
+type europassLearnerInfo struct {
+	Identification europassIdentification `xml:"Identification"`
+	Headline       *europassHeadline      `xml:"Headline,omitempty"`
+	WorkExperience []europassWork         `xml:"WorkExperienceList>WorkExperience,omitempty"`
+	Skills         europassSkills         `xml:"Skills"`
+	Achievements   []europassAchievement  `xml:"AchievementList>Achievement,omitempty"`
+}
+
+type europassIdentification struct {
+	FirstName string `xml:"PersonName>FirstName"`
+	Surname   string `xml:"PersonName>Surname"`
+	Email     string `xml:"ContactInfo>Email>Contact,omitempty"`
+}
+
+type europassHeadline struct {
+	Type        string `xml:"Type>Label"`
+	Description string `xml:"Description>Label"`
+}
+
+type europassWork struct {
+	Period     europassPeriod `xml:"Period"`
+	Position   string         `xml:"Position>Label,omitempty"`
+	Activities string         `xml:"Activities,omitempty"`
+	Employer   string         `xml:"Employer>Name,omitempty"`
+}
+
+type europassPeriod struct {
+	From    *europassDate `xml:"From,omitempty"`
+	To      *europassDate `xml:"To,omitempty"`
+	Current bool          `xml:"Current,omitempty"`
+}
+
+// europassDate is a date split into the XML Schema gYear, gMonth and gDay types
+type europassDate struct {
+	Year  string `xml:"year,attr"`
+	Month string `xml:"month,attr"`
+	Day   string `xml:"day,attr"`
+}
+
+type europassSkills struct {
+	ForeignLanguages []europassLanguage `xml:"Linguistic>ForeignLanguage,omitempty"`
+	Other            string             `xml:"Other>Description,omitempty"`
+}
+
+// europassLanguage rates every part of a language with the same CEFR level, the API does not record them apart
+type europassLanguage struct {
+	Description       string `xml:"Description>Label"`
+	Listening         string `xml:"ProficiencyLevel>Listening"`
+	Reading           string `xml:"ProficiencyLevel>Reading"`
+	SpokenInteraction string `xml:"ProficiencyLevel>SpokenInteraction"`
+	SpokenProduction  string `xml:"ProficiencyLevel>SpokenProduction"`
+	Writing           string `xml:"ProficiencyLevel>Writing"`
+}
+
+type europassAchievement struct {
+	Code        string `xml:"Title>Code"`
+	Label       string `xml:"Title>Label"`
+	Description string `xml:"Description"`
+}
+
+func newEuropassDate(d *instances.Date) *europassDate {
+	if d == nil {
+		return nil
+	}
+	return &europassDate{
+		Year:  fmt.Sprintf("%04d", d.Year()),
+		Month: fmt.Sprintf("--%02d", int(d.Month())),
+		Day:   fmt.Sprintf("---%02d", d.Day()),
+	}
+}
+
+// europassFromCV converts the profile prepared for a CV into a Europass CV. Skills rated with CEFR levels become
+// foreign languages, all other skills are listed by class.
+func europassFromCV(cv instances.CV, now time.Time) europassDocument {
+	doc := europassDocument{
+		Xmlns:  europassNamespace,
+		Locale: "en",
+		DocumentInfo: europassDocInfo{
+			DocumentType: "ECV",
+			CreationDate: now.UTC().Format(time.RFC3339),
+			XSDVersion:   "V3.3",
+			Generator:    "esmAPI",
+		},
+		LearnerInfo: europassLearnerInfo{
+			Identification: europassIdentification{
+				FirstName: cv.Employee.Name,
+				Surname:   cv.Employee.Lastname,
+				Email:     cv.Employee.Email,
+			},
+		},
+	}
+	if cv.Employee.FocusArea != "" {
+		doc.LearnerInfo.Headline = &europassHeadline{Type: "Preferred job", Description: cv.Employee.FocusArea}
+	}
+	for _, p := range cv.Projects {
+		activities := p.Description
+		if activities == "" {
+			activities = p.FocusArea
+		}
+		doc.LearnerInfo.WorkExperience = append(doc.LearnerInfo.WorkExperience, europassWork{
+			Period: europassPeriod{
+				From:    newEuropassDate(p.StartDate),
+				To:      newEuropassDate(p.EndDate),
+				Current: p.EndDate == nil || p.EndDate.After(now),
+			},
+			Position:   p.Role,
+			Activities: activities,
+			Employer:   p.Client,
+		})
+	}
+
+	var other []string
+	for _, group := range cv.SkillGroups {
+		var listed []string
+		for _, skill := range group.Skills {
+			if cefrLevel.MatchString(skill.SkillLevelLabel) {
+				l := skill.SkillLevelLabel
+				doc.LearnerInfo.Skills.ForeignLanguages = append(doc.LearnerInfo.Skills.ForeignLanguages,
+					europassLanguage{Description: skill.Skill, Listening: l, Reading: l, SpokenInteraction: l,
+						SpokenProduction: l, Writing: l})
+				continue
+			}
+			listed = append(listed, fmt.Sprintf("%s (%s)", skill.Skill, resumeLevel(skill)))
+		}
+		if len(listed) > 0 {
+			other = append(other, group.SkillClass+": "+strings.Join(listed, ", "))
+		}
+	}
+	doc.LearnerInfo.Skills.Other = strings.Join(other, "\n")
+
+	if len(cv.Certifications) > 0 {
+		certs := make([]string, len(cv.Certifications))
+		for i, c := range cv.Certifications {
+			certs[i] = fmt.Sprintf("%s, %s (%s)", c.Name, c.Issuer, c.IssuedOn)
+		}
+		doc.LearnerInfo.Achievements = []europassAchievement{{
+			Code:        "certifications",
+			Label:       "Certifications",
+			Description: strings.Join(certs, "\n"),
+		}}
+	}
+	return doc
+}
+
+// renderEuropass writes doc as an XML document
+func renderEuropass(doc europassDocument) ([]byte, error) {
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
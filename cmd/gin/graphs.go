@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// secretProjectLabel replaces everything known about a secret project in exported graphs
+const secretProjectLabel = "Confidential project"
+
+// staffingLink is an employee working on a project, Client is nil for projects without one
+type staffingLink struct {
+	EmployeeId int64
+	Role       string
+	Project    instances.Project
+	Client     *instances.Client
+}
+
+// The kinds of graph nodes
+const (
+	nodeEmployee = "employee"
+	nodeProject  = "project"
+	nodeClient   = "client"
+)
+
+type graphNode struct {
+	Id    string
+	Label string
+	Kind  string
+}
+
+type graphEdge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// graph is a directed graph in the order it is rendered. Nodes are added once, however often they are added.
+type graph struct {
+	Nodes []graphNode
+	Edges []graphEdge
+	seen  map[string]bool
+}
+
+func (g *graph) addNode(id string, label string, kind string) {
+	if g.seen == nil {
+		g.seen = make(map[string]bool)
+	}
+	if !g.seen[id] {
+		g.seen[id] = true
+		g.Nodes = append(g.Nodes, graphNode{Id: id, Label: label, Kind: kind})
+	}
+}
+
+func (g *graph) addEdge(from string, to string, label string) {
+	if g.seen == nil {
+		g.seen = make(map[string]bool)
+	}
+	key := from + "\x00" + to + "\x00" + label
+	if !g.seen[key] {
+		g.seen[key] = true
+		g.Edges = append(g.Edges, graphEdge{From: from, To: to, Label: label})
+	}
+}
+
+func employeeNodeId(id int64) string {
+	return "e" + strconv.FormatInt(id, 10)
+}
+
+func employeeLabel(emp instances.Employee) string {
+	return strings.TrimSpace(emp.Name + " " + emp.Lastname)
+}
+
+// projectNode returns the id and label of a project, secret projects keep nothing but their id
+func projectNode(p instances.Project) (string, string) {
+	id := "p" + strconv.FormatInt(p.ProjectId, 10)
+	if p.IsSecret {
+		return id, secretProjectLabel
+	}
+	label := "Project " + strconv.FormatInt(p.ProjectId, 10)
+	if p.FocusArea != "" {
+		label += " (" + p.FocusArea + ")"
+	}
+	return id, label
+}
+
+// linkSelected reports whether a staffing link passes the client and project filters. Secret projects never match a
+// filter, so filtering by client does not reveal whom they are for, nor filtering by project who works on them.
+func linkSelected(link staffingLink, filter instances.GraphFilter) bool {
+	if filter.Project != nil && (link.Project.IsSecret || link.Project.ProjectId != *filter.Project) {
+		return false
+	}
+	if filter.Client != nil && (link.Project.IsSecret || link.Client == nil || link.Client.ID != *filter.Client) {
+		return false
+	}
+	return true
+}
+
+// graphScope returns the ids of the employees a graph filter selects, nil if it selects everyone
+func graphScope(employees []instances.Employee, links []staffingLink, filter instances.GraphFilter) map[int64]bool {
+	scope := orgScope(employees, filter.OrgFilter)
+	if filter.Client == nil && filter.Project == nil {
+		return scope
+	}
+	staffed := make(map[int64]bool)
+	for _, link := range links {
+		if linkSelected(link, filter) && (scope == nil || scope[link.EmployeeId]) {
+			staffed[link.EmployeeId] = true
+		}
+	}
+	return staffed
+}
+
+// orgGraph draws the reporting lines between the employees in scope, managers pointing to their reports
+func orgGraph(employees []instances.Employee, scope map[int64]bool) graph {
+	var g graph
+	for _, emp := range employees {
+		if scope == nil || scope[emp.EmployeeId] {
+			g.addNode(employeeNodeId(emp.EmployeeId), employeeLabel(emp), nodeEmployee)
+		}
+	}
+	for _, emp := range employees {
+		if emp.ManagerId == nil || !g.seen[employeeNodeId(emp.EmployeeId)] || !g.seen[employeeNodeId(*emp.ManagerId)] {
+			continue
+		}
+		g.addEdge(employeeNodeId(*emp.ManagerId), employeeNodeId(emp.EmployeeId), "")
+	}
+	return g
+}
+
+// staffingGraph draws the employees in scope, the projects they work on labelled with their role and the clients of
+// those projects. Secret projects are drawn without details, without their client and without the roles on them.
+func staffingGraph(employees []instances.Employee, links []staffingLink, scope map[int64]bool,
+	filter instances.GraphFilter) graph {
+	byId := make(map[int64]instances.Employee, len(employees))
+	for _, emp := range employees {
+		byId[emp.EmployeeId] = emp
+	}
+	var g graph
+	for _, link := range links {
+		emp, ok := byId[link.EmployeeId]
+		if !ok || scope != nil && !scope[link.EmployeeId] || !linkSelected(link, filter) {
+			continue
+		}
+		empId := employeeNodeId(emp.EmployeeId)
+		projectId, projectLabel := projectNode(link.Project)
+		g.addNode(empId, employeeLabel(emp), nodeEmployee)
+		g.addNode(projectId, projectLabel, nodeProject)
+		role := link.Role
+		if link.Project.IsSecret {
+			role = ""
+		}
+		g.addEdge(empId, projectId, role)
+		if link.Client != nil && !link.Project.IsSecret {
+			clientId := "c" + strconv.FormatInt(link.Client.ID, 10)
+			g.addNode(clientId, link.Client.Name, nodeClient)
+			g.addEdge(projectId, clientId, "")
+		}
+	}
+	return g
+}
+
+// dotQuote quotes s as a Graphviz ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+var dotShapes = map[string]string{nodeEmployee: "box", nodeProject: "ellipse", nodeClient: "hexagon"}
+
+// renderDOT renders g in the Graphviz DOT language
+func renderDOT(name string, g graph) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "digraph %s {\n\trankdir=LR;\n", dotQuote(name))
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s, shape=%s];\n", dotQuote(n.Id), dotQuote(n.Label), dotShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Label != "" {
+			fmt.Fprintf(&b, " [label=%s]", dotQuote(e.Label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// mermaidText escapes s for a quoted Mermaid label
+func mermaidText(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
+
+var mermaidShapes = map[string][2]string{
+	nodeEmployee: {"[", "]"},
+	nodeProject:  {"([", "])"},
+	nodeClient:   {"{{", "}}"},
+}
+
+// renderMermaid renders g as a Mermaid flowchart
+func renderMermaid(g graph) []byte {
+	var b bytes.Buffer
+	b.WriteString("flowchart LR\n")
+	for _, n := range g.Nodes {
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&b, "    %s%s%s%s\n", n.Id, shape[0], mermaidText(n.Label), shape[1])
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", e.From, mermaidText(e.Label), e.To)
+		} else {
+			fmt.Fprintf(&b, "    %s --> %s\n", e.From, e.To)
+		}
+	}
+	return b.Bytes()
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	Id    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	Id        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfEdge struct {
+	Id     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Label  string `xml:"label,attr,omitempty"`
+}
+
+// renderGEXF renders g as GEXF 1.3, the kind of each node is its "kind" attribute
+func renderGEXF(g graph) ([]byte, error) {
+	doc := gexfDocument{
+		Xmlns:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Attributes: gexfAttributes{
+				Class:      "node",
+				Attributes: []gexfAttribute{{Id: "kind", Title: "kind", Type: "string"}},
+			},
+			Nodes: make([]gexfNode, len(g.Nodes)),
+			Edges: make([]gexfEdge, len(g.Edges)),
+		},
+	}
+	for i, n := range g.Nodes {
+		doc.Graph.Nodes[i] = gexfNode{Id: n.Id, Label: n.Label, AttValues: []gexfAttValue{{For: "kind", Value: n.Kind}}}
+	}
+	for i, e := range g.Edges {
+		doc.Graph.Edges[i] = gexfEdge{Id: strconv.Itoa(i), Source: e.From, Target: e.To, Label: e.Label}
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
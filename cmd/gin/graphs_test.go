@@ -0,0 +1,71 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var testClient = &instances.Client{ID: 7, Name: "Acme \"Labs\""}
+
+// testOrg members 1 and 3 work for Acme on project 5, 2 on the secret project 6 for Acme
+var testLinks = []staffingLink{
+	{EmployeeId: 1, Role: "Lead", Project: instances.Project{ProjectId: 5, ClientId: 7, FocusArea: "Cloud"}, Client: testClient},
+	{EmployeeId: 2, Role: "Developer", Project: instances.Project{ProjectId: 6, ClientId: 7, FocusArea: "Defense", IsSecret: true}, Client: testClient},
+	{EmployeeId: 3, Role: "Developer", Project: instances.Project{ProjectId: 5, ClientId: 7, FocusArea: "Cloud"}, Client: testClient},
+}
+
+func TestStaffingGraphHidesSecretProjects(t *testing.T) {
+	g := staffingGraph(testOrg, testLinks, nil, instances.GraphFilter{})
+	assert.Contains(t, g.Nodes, graphNode{Id: "p6", Label: secretProjectLabel, Kind: nodeProject})
+	assert.NotContains(t, g.Edges, graphEdge{From: "p6", To: "c7"}, "the client of a secret project")
+	assert.Contains(t, g.Edges, graphEdge{From: "p5", To: "c7"})
+	assert.Len(t, g.Edges, 4, "one link per employee and one to the client")
+	assert.Contains(t, g.Edges, graphEdge{From: "e2", To: "p6"}, "no role on a secret project")
+	assert.Contains(t, g.Edges, graphEdge{From: "e1", To: "p5", Label: "Lead"})
+
+	dot := string(renderDOT("staffing", g))
+	assert.NotContains(t, dot, "Defense")
+	assert.Contains(t, dot, `"c7" [label="Acme \"Labs\"", shape=hexagon];`)
+
+	secret := int64(6)
+	g = staffingGraph(testOrg, testLinks, nil, instances.GraphFilter{Project: &secret})
+	assert.Empty(t, g.Nodes, "filtering by a secret project")
+}
+
+func TestGraphScope(t *testing.T) {
+	client := int64(7)
+	scope := graphScope(testOrg, testLinks, instances.GraphFilter{Client: &client})
+	assert.Equal(t, map[int64]bool{1: true, 3: true}, scope, "secret projects never match a client")
+
+	project := int64(5)
+	scope = graphScope(testOrg, testLinks, instances.GraphFilter{Project: &project, OrgFilter: instances.OrgFilter{Manager: ptr(1)}})
+	assert.Equal(t, map[int64]bool{1: true, 3: true}, scope)
+
+	secret := int64(6)
+	scope = graphScope(testOrg, testLinks, instances.GraphFilter{Project: &secret})
+	assert.Empty(t, scope, "secret projects never match a project filter")
+
+	assert.Nil(t, graphScope(testOrg, testLinks, instances.GraphFilter{}))
+}
+
+func TestOrgGraph(t *testing.T) {
+	g := orgGraph(testOrg, map[int64]bool{1: true, 2: true, 3: true})
+	assert.Len(t, g.Nodes, 3)
+	assert.Equal(t, []graphEdge{{From: "e1", To: "e3"}}, g.Edges, "edges to managers out of scope are left out")
+
+	mermaid := string(renderMermaid(g))
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart LR\n"))
+	assert.Contains(t, mermaid, `e1["Grace"]`)
+	assert.Contains(t, mermaid, "e1 --> e3")
+}
+
+func TestRenderGEXF(t *testing.T) {
+	out, err := renderGEXF(staffingGraph(testOrg, testLinks[:1], nil, instances.GraphFilter{}))
+	assert.NoError(t, err)
+	gexf := string(out)
+	assert.Contains(t, gexf, `<gexf xmlns="http://gexf.net/1.3" version="1.3">`)
+	assert.Contains(t, gexf, `<node id="c7" label="Acme &#34;Labs&#34;">`)
+	assert.Contains(t, gexf, `<edge id="0" source="e1" target="p5" label="Lead"></edge>`)
+}
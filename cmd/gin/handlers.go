@@ -1,15 +1,22 @@
 package main
 
 import (
+	"errors"
 	"esmAPI/pkg/instances"
-	"fmt"
+	"esmAPI/pkg/validation"
 	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
 	"net/http"
+	"regexp"
 	"strconv"
 )
 
 type EmployeeHandler struct {
 	store employeeStore
+	// scales validates the levels of employee skills
+	scales skillScaleStore
+	// approvals holds self-assessed levels for review, nil if they are written directly
+	approvals skillChangeStore
 }
 
 type SkillHandler struct {
@@ -24,10 +31,77 @@ type ClientHandler struct {
 	store clientStore
 }
 
-// NewEmployeeHandler - constructor
-func NewEmployeeHandler(store employeeStore) *EmployeeHandler {
+// validationFailed responds with the list of field errors of an invalid payload
+func validationFailed(context *gin.Context, err error) {
+	var verrs validation.Errors
+	if errors.As(err, &verrs) {
+		context.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "fields": verrs})
+		return
+	}
+	context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// foreignKeyColumn finds the referencing column in MySQL's foreign key error message
+var foreignKeyColumn = regexp.MustCompile("FOREIGN KEY \\(`(\\w+)`\\)")
+
+// referenceError turns a violated foreign key into a validation error on the referencing field, so that unknown
+// ids are reported like any other invalid input. ok is false for any other error.
+func referenceError(err error) (verrs validation.Errors, ok bool) {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1452 {
+		return nil, false
+	}
+	field := "id"
+	if m := foreignKeyColumn.FindStringSubmatch(mysqlErr.Message); m != nil {
+		field = m[1]
+	}
+	return validation.Errors{{
+		Field:   field,
+		Rule:    "exists",
+		Message: field + " does not reference an existing record",
+	}}, true
+}
+
+// isReferenced reports whether err is MySQL refusing to delete a row that other rows still reference
+func isReferenced(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1451
+}
+
+// isDuplicate reports whether err is a violated primary or unique key
+func isDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// keepsId responds and returns false if an update changes the id of a record, ids only change by re-keying. A body
+// without the id, which decodes to 0, keeps the id of the path.
+func keepsId[T int | int64](context *gin.Context, field string, id int64, updated *T) bool {
+	if *updated == 0 {
+		*updated = T(id)
+	}
+	if err := changedId(field, id, int64(*updated)); err != nil {
+		validationFailed(context, err)
+		return false
+	}
+	return true
+}
+
+// changedId returns a validation error if updated is not the id of the record
+func changedId(field string, id int64, updated int64) error {
+	if id == updated {
+		return nil
+	}
+	return validation.Errors{{Field: field, Rule: "immutable",
+		Message: field + " cannot be changed by an update, re-key the record instead"}}
+}
+
+// NewEmployeeHandler - constructor. approvals may be nil to disable the approval of self-assessed levels.
+func NewEmployeeHandler(store employeeStore, scales skillScaleStore, approvals skillChangeStore) *EmployeeHandler {
 	return &EmployeeHandler{
-		store: store,
+		store:     store,
+		scales:    scales,
+		approvals: approvals,
 	}
 }
 
@@ -37,10 +111,14 @@ func (h EmployeeHandler) addEmployee(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	if err := validation.Struct(emp); err != nil {
+		validationFailed(context, err)
+		return
+	}
 
-	result, err := h.store.Add(emp)
+	result, err := h.store.Add(context.Request.Context(), emp)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"err": err})
+		employeeFailed(context, err)
 		return
 	}
 	context.IndentedJSON(http.StatusCreated, gin.H{"rows_affected": result})
@@ -53,7 +131,7 @@ func (h EmployeeHandler) updateEmployee(context *gin.Context) {
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
-	currEmployee, err := h.store.Get(id)
+	currEmployee, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -62,9 +140,16 @@ func (h EmployeeHandler) updateEmployee(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.Update(id, currEmployee)
+	if !keepsId(context, "employee_id", id, &currEmployee.EmployeeId) {
+		return
+	}
+	if err := validation.Struct(currEmployee); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, currEmployee)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"err": err})
+		employeeFailed(context, err)
 		return
 	}
 	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
@@ -77,7 +162,7 @@ func (h EmployeeHandler) deleteEmployee(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	result, err := h.store.Delete(id)
+	result, err := h.store.Delete(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -92,7 +177,7 @@ func (h EmployeeHandler) getEmployee(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	employee, err := h.store.Get(id)
+	employee, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -101,7 +186,7 @@ func (h EmployeeHandler) getEmployee(context *gin.Context) {
 }
 
 func (h EmployeeHandler) getEmployees(context *gin.Context) {
-	employees, err := h.store.List()
+	employees, err := h.store.List(context.Request.Context())
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -110,7 +195,11 @@ func (h EmployeeHandler) getEmployees(context *gin.Context) {
 }
 
 func (h EmployeeHandler) getFullEmployees(context *gin.Context) {
-	fullEmployees, err := h.store.ListFull()
+	filter, ok := bindAssignmentFilter(context)
+	if !ok {
+		return
+	}
+	fullEmployees, err := h.store.ListFull(context.Request.Context(), filter)
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -125,7 +214,11 @@ func (h EmployeeHandler) getFullEmployee(context *gin.Context) {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	fullEmployee, err := h.store.GetFull(id)
+	filter, ok := bindAssignmentFilter(context)
+	if !ok {
+		return
+	}
+	fullEmployee, err := h.store.GetFull(context.Request.Context(), id, filter)
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -145,10 +238,31 @@ func (h EmployeeHandler) addSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	if err := validation.Struct(empSkill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if !validateSkillLevel(context, h.scales, empSkill) {
+		return
+	}
 
-	fmt.Printf("empdId:%d, skillId: %d, skillLevel: %d", id, empSkill.SkillId, empSkill.SkillLevel)
-	result, err := h.store.AddSkill(id, empSkill.SkillId, empSkill.SkillLevel)
+	loggerFrom(context.Request.Context()).Debug("adding skill to employee",
+		"employee_id", id, "skill_id", empSkill.SkillId, "skill_level", empSkill.SkillLevel)
+	pending, err := h.needsApproval(context, id, empSkill)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if pending {
+		h.requestChange(context, id, empSkill)
+		return
+	}
+	result, err := h.store.AddSkill(context.Request.Context(), id, withAssessor(context, empSkill))
 	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
@@ -174,7 +288,7 @@ func (h EmployeeHandler) deleteSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.DeleteSkill(id, empSkill.SkillId)
+	result, err := h.store.DeleteSkill(context.Request.Context(), id, empSkill.SkillId)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -194,7 +308,23 @@ func (h EmployeeHandler) updateSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.UpdateSkill(id, empSkill.SkillId, empSkill.SkillLevel)
+	if err := validation.Struct(empSkill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if !validateSkillLevel(context, h.scales, empSkill) {
+		return
+	}
+	pending, err := h.needsApproval(context, id, empSkill)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if pending {
+		h.requestChange(context, id, empSkill)
+		return
+	}
+	result, err := h.store.UpdateSkill(context.Request.Context(), id, withAssessor(context, empSkill))
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -214,9 +344,13 @@ func (h EmployeeHandler) addProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.AddProject(empProject.ProjectId, id, empProject.ProjectRole)
+	if err := validation.Struct(empProject); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.AddProject(context.Request.Context(), id, empProject)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err})
+		assignmentFailed(context, err)
 		return
 	}
 	context.IndentedJSON(http.StatusCreated, gin.H{"rows_affected": result})
@@ -234,9 +368,13 @@ func (h EmployeeHandler) updateProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.UpdateProject(empProject.ProjectId, id, empProject.ProjectRole)
+	if err := validation.Struct(empProject); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.UpdateProject(context.Request.Context(), id, empProject)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, gin.H{"error": err})
+		assignmentFailed(context, err)
 		return
 	}
 	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
@@ -254,7 +392,7 @@ func (h EmployeeHandler) deleteProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.DeleteProject(empProject.ProjectId, id)
+	result, err := h.store.DeleteProject(context.Request.Context(), empProject.ProjectId, id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -263,7 +401,7 @@ func (h EmployeeHandler) deleteProject(context *gin.Context) {
 }
 
 func (h SkillHandler) getSkills(context *gin.Context) {
-	skills, err := h.store.List()
+	skills, err := h.store.List(context.Request.Context())
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -278,7 +416,7 @@ func (h SkillHandler) getSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	skill, err := h.store.Get(id)
+	skill, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -292,9 +430,40 @@ func (h SkillHandler) addSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
+	if err := validation.Struct(skill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if err := checkDeprecation(skill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	var options instances.SkillAddOptions
+	if err := context.BindQuery(&options); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !options.Force {
+		skills, err := h.store.List(context.Request.Context())
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if similar := similarSkills(skill.Skill, skills); len(similar) > 0 {
+			context.JSON(http.StatusConflict, gin.H{
+				"error":       "the skill resembles existing skills, use one of them or add it with force=true",
+				"suggestions": similar,
+			})
+			return
+		}
+	}
 
-	result, err := h.store.Add(skill)
+	result, err := h.store.Add(context.Request.Context(), skill)
 	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
@@ -308,7 +477,7 @@ func (h SkillHandler) updateSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	currSkill, err := h.store.Get(id)
+	currSkill, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -317,8 +486,23 @@ func (h SkillHandler) updateSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.Update(id, currSkill)
+	if !keepsId(context, "skill_id", id, &currSkill.SkillId) {
+		return
+	}
+	if err := validation.Struct(currSkill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if err := checkDeprecation(currSkill); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, currSkill)
 	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
@@ -332,7 +516,7 @@ func (h SkillHandler) deleteSkill(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	result, err := h.store.Delete(id)
+	result, err := h.store.Delete(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -348,7 +532,7 @@ func NewProjectHandler(store projectStore) *ProjectHandler {
 }
 
 func (h ProjectHandler) getProjects(context *gin.Context) {
-	projects, err := h.store.List()
+	projects, err := h.store.List(context.Request.Context())
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -363,7 +547,7 @@ func (h ProjectHandler) getProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	project, err := h.store.Get(id)
+	project, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -377,8 +561,16 @@ func (h ProjectHandler) addProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.Add(project)
+	if err := validation.Struct(project); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Add(context.Request.Context(), project)
 	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
@@ -392,7 +584,7 @@ func (h ProjectHandler) updateProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	proj, err := h.store.Get(id)
+	proj, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -401,8 +593,19 @@ func (h ProjectHandler) updateProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
-	result, err := h.store.Update(id, proj)
+	if !keepsId(context, "project_id", id, &proj.ProjectId) {
+		return
+	}
+	if err := validation.Struct(proj); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, proj)
 	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
@@ -416,7 +619,7 @@ func (h ProjectHandler) deleteProject(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	result, err := h.store.Delete(id)
+	result, err := h.store.Delete(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -432,7 +635,7 @@ func NewClientHandler(store clientStore) *ClientHandler {
 }
 
 func (h ClientHandler) getClients(context *gin.Context) {
-	clients, err := h.store.List()
+	clients, err := h.store.List(context.Request.Context())
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -447,7 +650,7 @@ func (h ClientHandler) getClient(context *gin.Context) {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	client, err := h.store.Get(id)
+	client, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err})
 		return
@@ -461,7 +664,11 @@ func (h ClientHandler) addClient(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err})
 		return
 	}
-	result, err := h.store.Add(client)
+	if err := validation.Struct(client); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Add(context.Request.Context(), client)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -476,7 +683,7 @@ func (h ClientHandler) updateClient(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	client, err := h.store.Get(id)
+	client, err := h.store.Get(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -485,7 +692,14 @@ func (h ClientHandler) updateClient(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
 	}
-	result, err := h.store.Update(id, client)
+	if !keepsId(context, "id", id, &client.ID) {
+		return
+	}
+	if err := validation.Struct(client); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, client)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -500,7 +714,7 @@ func (h ClientHandler) deleteClient(context *gin.Context) {
 		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	result, err := h.store.Delete(id)
+	result, err := h.store.Delete(context.Request.Context(), id)
 	if err != nil {
 		context.JSON(http.StatusBadRequest, gin.H{"err": err})
 		return
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SkillChangeHandler struct {
+	store  skillChangeStore
+	scales skillScaleStore
+}
+
+// NewSkillChangeHandler - constructor
+func NewSkillChangeHandler(store skillChangeStore, scales skillScaleStore) *SkillChangeHandler {
+	return &SkillChangeHandler{
+		store:  store,
+		scales: scales,
+	}
+}
+
+// errUnauthenticated is returned when a request that has to name its caller is anonymous
+var errUnauthenticated = errors.New("the request is not authenticated")
+
+// needsApproval reports whether the level of an employee skill has to be approved before it is written. While the
+// approval workflow is enabled, only a level an authenticated caller gives another employee, naming its source, is
+// written directly. Levels callers give themselves wait for a reviewer whatever source they name, and so do levels
+// of anonymous callers and levels without a source.
+func (h EmployeeHandler) needsApproval(context *gin.Context, employeeId int64, empSkill instances.EmployeeSkill) (bool, error) {
+	if h.approvals == nil {
+		return false, nil
+	}
+	other, err := h.assessesOther(context, employeeId)
+	if err != nil {
+		return false, err
+	}
+	return approvalNeeded(empSkill.Source, other), nil
+}
+
+// approvalNeeded is needsApproval of a level from source once it is known whether the caller assesses another
+// employee
+func approvalNeeded(source string, assessesOther bool) bool {
+	switch source {
+	case instances.AssessmentManager, instances.AssessmentPeer, instances.AssessmentCertification:
+		return !assessesOther
+	default:
+		return true
+	}
+}
+
+// assessesOther reports whether the caller is authenticated and someone other than the employee
+func (h EmployeeHandler) assessesOther(context *gin.Context, employeeId int64) (bool, error) {
+	principal := context.GetString(principalKey)
+	if principal == "" {
+		return false, nil
+	}
+	callerId, err := h.approvals.Caller(context.Request.Context(), principal)
+	if errors.Is(err, sql.ErrNoRows) {
+		// authenticated callers that are not employees, e.g. other services, cannot assess themselves
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return callerId != employeeId, nil
+}
+
+// requestChange records a level for review and responds with the pending request
+func (h EmployeeHandler) requestChange(context *gin.Context, employeeId int64, empSkill instances.EmployeeSkill) {
+	requestId, err := h.approvals.Request(context.Request.Context(), employeeId, empSkill)
+	if err != nil {
+		if errors.Is(err, errChangePending) {
+			context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusAccepted, gin.H{"request_id": requestId, "status": instances.ChangePending})
+}
+
+// getChangeRequests lists change requests, the pending ones unless the query selects another status
+func (h SkillChangeHandler) getChangeRequests(context *gin.Context) {
+	var filter instances.SkillChangeFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	requests, err := h.store.List(context.Request.Context(), filter)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, requests)
+}
+
+func (h SkillChangeHandler) getChangeRequest(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := h.store.Get(context.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "change request not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, req)
+}
+
+func (h SkillChangeHandler) approveChange(context *gin.Context) {
+	h.review(context, true)
+}
+
+func (h SkillChangeHandler) rejectChange(context *gin.Context) {
+	h.review(context, false)
+}
+
+func (h SkillChangeHandler) review(context *gin.Context, approve bool) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	principal := context.GetString(principalKey)
+	if principal == "" {
+		context.JSON(http.StatusUnauthorized, gin.H{"error": errUnauthenticated.Error()})
+		return
+	}
+	var review instances.SkillReview
+	if err := context.BindJSON(&review); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(review); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	// the reviewer is the caller, callers that are not employees can neither own skills nor manage anyone
+	review.ReviewerId, err = h.store.Caller(context.Request.Context(), principal)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusForbidden, gin.H{"error": errNotApprover.Error()})
+		return
+	}
+	if errors.Is(err, errAmbiguousCaller) {
+		context.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if approve {
+		// the scale may have changed since the level was requested
+		req, err := h.store.Get(context.Request.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"error": "change request not found"})
+			return
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !validateSkillLevel(context, h.scales, instances.EmployeeSkill{SkillId: req.SkillId, SkillLevel: req.SkillLevel}) {
+			return
+		}
+	}
+
+	req, err := h.store.Review(context.Request.Context(), id, review, approve)
+	switch {
+	case err == nil:
+		context.IndentedJSON(http.StatusOK, req)
+	case errors.Is(err, sql.ErrNoRows):
+		context.JSON(http.StatusNotFound, gin.H{"error": "change request not found"})
+	case errors.Is(err, errChangeReviewed):
+		context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errNotApprover), errors.Is(err, errOwnChange):
+		context.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func (h SkillChangeHandler) getOwners(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	owners, err := h.store.Owners(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, owners)
+}
+
+func (h SkillChangeHandler) addOwner(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var owner instances.SkillOwner
+	if err := context.BindJSON(&owner); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(owner); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.AddOwner(context.Request.Context(), id, owner.EmployeeId)
+	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		if isDuplicate(err) {
+			context.JSON(http.StatusConflict, gin.H{"error": "the employee already owns the skill"})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"rows_affected": result})
+}
+
+func (h SkillChangeHandler) deleteOwner(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employeeId, err := strconv.ParseInt(context.Params.ByName("employeeId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.DeleteOwner(context.Request.Context(), id, employeeId)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
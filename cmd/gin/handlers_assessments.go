@@ -0,0 +1,54 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// withAssessor attributes an assessment without an explicit assessor to the authenticated caller
+func withAssessor(context *gin.Context, empSkill instances.EmployeeSkill) instances.EmployeeSkill {
+	if empSkill.Assessor == "" {
+		empSkill.Assessor = context.GetString(principalKey)
+	}
+	return empSkill
+}
+
+// getSkillHistory returns the dated assessments of one skill of an employee, labelled on the skill's scale
+func (h EmployeeHandler) getSkillHistory(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skillId, err := strconv.ParseInt(context.Params.ByName("skillId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	history, err := h.store.SkillHistory(context.Request.Context(), id, skillId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "no assessments of this skill for this employee"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scale, err := h.scales.ForSkill(context.Request.Context(), skillId)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i, assessment := range history.Assessments {
+		if level, ok := scale.Find(int(assessment.SkillLevel)); ok {
+			history.Assessments[i].SkillLevelLabel = level.Label
+		}
+	}
+	context.IndentedJSON(http.StatusOK, history)
+}
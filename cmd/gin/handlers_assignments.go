@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// bindAssignmentFilter reads the projects query parameter selecting current, past or upcoming stints.
+// It responds and returns false if the parameter is invalid.
+func bindAssignmentFilter(context *gin.Context) (instances.AssignmentFilter, bool) {
+	var filter instances.AssignmentFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return filter, false
+	}
+	return filter, true
+}
+
+// assignmentFailed responds to an error of a store call changing a stint
+func assignmentFailed(context *gin.Context, err error) {
+	var verrs validation.Errors
+	switch {
+	case errors.As(err, &verrs):
+		validationFailed(context, verrs)
+	case errors.Is(err, errOverlappingStint):
+		context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// getProjects lists the stints of an employee on projects
+func (h EmployeeHandler) getProjects(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, ok := bindAssignmentFilter(context)
+	if !ok {
+		return
+	}
+	projects, err := h.store.Projects(context.Request.Context(), id, filter)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, projects)
+}
+
+// updateAssignment changes a single stint, e.g. to set the end of an open-ended one
+func (h EmployeeHandler) updateAssignment(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	assignmentId, err := strconv.ParseInt(context.Params.ByName("assignmentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var empProject instances.EmployeeProject
+	if err := context.BindJSON(&empProject); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	empProject.AssignmentId = assignmentId
+	if err := validation.Struct(empProject); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.UpdateAssignment(context.Request.Context(), id, empProject)
+	if err != nil {
+		assignmentFailed(context, err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h EmployeeHandler) deleteAssignment(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	assignmentId, err := strconv.ParseInt(context.Params.ByName("assignmentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.DeleteAssignment(context.Request.Context(), id, assignmentId)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// getCandidates ranks the employees for the open roles of a project by how well their skills, experience and
+// availability fit its requirements. Every candidate carries the partial scores and the matched and missing skills.
+func (h RequirementHandler) getCandidates(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var filter instances.CandidateFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	from := today()
+	if filter.From != nil {
+		from = *filter.From
+	}
+	to := instances.Date{Time: from.AddDate(0, 0, filter.Days-1)}
+
+	ctx := context.Request.Context()
+	reqs, err := h.store.List(ctx, id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(reqs) == 0 {
+		context.JSON(http.StatusConflict, gin.H{"error": "the project has no skill requirements to match candidates against"})
+		return
+	}
+	pool, err := h.store.Candidates(ctx, id, filter.IncludeTeam)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	experience, err := h.store.Experience(ctx, id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stints, err := h.reports.Stints(ctx, from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	candidates := rankCandidates(reqs, pool, experience, averageAllocation(stints, from, to))
+	if len(candidates) > filter.Limit {
+		candidates = candidates[:filter.Limit]
+	}
+	context.IndentedJSON(http.StatusOK, candidates)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type CertificationHandler struct {
+	store certificationStore
+}
+
+// NewCertificationHandler - constructor
+func NewCertificationHandler(store certificationStore) *CertificationHandler {
+	return &CertificationHandler{
+		store: store,
+	}
+}
+
+// validateCertification checks the rules of a certification payload and responds if it is invalid
+func validateCertification(context *gin.Context, cert instances.Certification) bool {
+	if err := validation.Struct(cert); err != nil {
+		validationFailed(context, err)
+		return false
+	}
+	if err := validation.Period("issued_on", cert.IssuedOn, "expires_on", cert.ExpiresOn); err != nil {
+		validationFailed(context, err)
+		return false
+	}
+	return true
+}
+
+func (h CertificationHandler) getCertifications(context *gin.Context) {
+	var filter instances.CertificationFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	certs, err := h.store.List(context.Request.Context(), filter)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, certs)
+}
+
+// getExpiring lists the certifications expiring soon, e.g. to plan renewals before a client audit
+func (h CertificationHandler) getExpiring(context *gin.Context) {
+	var filter instances.ExpiringFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	certs, err := h.store.Expiring(context.Request.Context(), filter)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, certs)
+}
+
+// getHeadcount returns how many employees hold each valid certification
+func (h CertificationHandler) getHeadcount(context *gin.Context) {
+	counts, err := h.store.Headcount(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, counts)
+}
+
+func (h CertificationHandler) getCertification(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert, err := h.store.Get(context.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "certification not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, cert)
+}
+
+func (h CertificationHandler) addCertification(context *gin.Context) {
+	var cert instances.Certification
+	if err := context.BindJSON(&cert); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validateCertification(context, cert) {
+		return
+	}
+	id, err := h.store.Add(context.Request.Context(), cert)
+	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"certification_id": id})
+}
+
+func (h CertificationHandler) updateCertification(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cert, err := h.store.Get(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := context.BindJSON(&cert); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validateCertification(context, cert) {
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, cert)
+	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h CertificationHandler) deleteCertification(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.Delete(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type CVHandler struct {
+	employees employeeStore
+	clients   clientStore
+	templates cvTemplates
+}
+
+// NewCVHandler - constructor
+func NewCVHandler(employees employeeStore, clients clientStore, templates cvTemplates) *CVHandler {
+	return &CVHandler{
+		employees: employees,
+		clients:   clients,
+		templates: templates,
+	}
+}
+
+// cvFormat returns the format asked for, or the one of the Accept header if format is empty
+func cvFormat(context *gin.Context, format string) string {
+	if format != "" {
+		return format
+	}
+	switch context.NegotiateFormat("text/markdown", "text/html", "application/pdf") {
+	case "text/html":
+		return instances.CVHTML
+	case "application/pdf":
+		return instances.CVPDF
+	default:
+		return instances.CVMarkdown
+	}
+}
+
+// clientNames maps the ids of all clients to their names
+func clientNames(context *gin.Context, store clientStore) (map[int64]string, error) {
+	clients, err := store.List(context.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(clients))
+	for _, c := range clients {
+		names[c.ID] = c.Name
+	}
+	return names, nil
+}
+
+// renderCV loads the profile of an employee and renders it. The error wraps sql.ErrNoRows if the employee does not
+// exist.
+func (h CVHandler) renderCV(context *gin.Context, id int64, clients map[int64]string, format string) (cvFile, error) {
+	full, err := h.employees.GetFull(context.Request.Context(), id, instances.AssignmentFilter{})
+	if err != nil {
+		return cvFile{}, fmt.Errorf("employee %d: %w", id, err)
+	}
+	body, err := h.templates.render(buildCV(full, clients), format)
+	if err != nil {
+		return cvFile{}, err
+	}
+	return cvFile{Name: cvFileName(full.Employee, format), Body: body}, nil
+}
+
+// getCV renders the CV of an employee as Markdown, HTML or PDF
+func (h CVHandler) getCV(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var opts instances.CVOptions
+	if err := context.BindQuery(&opts); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(opts); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	clients, err := clientNames(context, h.clients)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := cvFormat(context, opts.Format)
+	file, err := h.renderCV(context, id, clients, format)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	context.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", file.Name))
+	context.Data(http.StatusOK, cvContentTypes[format], file.Body)
+}
+
+// getCVs renders the CVs of several employees into one ZIP archive
+func (h CVHandler) getCVs(context *gin.Context) {
+	var batch instances.CVBatch
+	if err := context.BindJSON(&batch); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(batch); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if batch.Format == "" {
+		batch.Format = instances.CVPDF
+	}
+	clients, err := clientNames(context, h.clients)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	files := make([]cvFile, 0, len(batch.EmployeeIds))
+	for _, id := range batch.EmployeeIds {
+		file, err := h.renderCV(context, id, clients, batch.Format)
+		if errors.Is(err, sql.ErrNoRows) {
+			context.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("employee %d not found", id)})
+			return
+		}
+		if err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		files = append(files, file)
+	}
+	archive, err := zipCVs(files)
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	context.Header("Content-Disposition", `attachment; filename="cvs.zip"`)
+	context.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// graphContentTypes are the content types of the graph export formats
+var graphContentTypes = map[string]string{
+	instances.GraphDOT:     "text/vnd.graphviz; charset=utf-8",
+	instances.GraphMermaid: "text/plain; charset=utf-8",
+	instances.GraphGEXF:    "application/gexf+xml; charset=utf-8",
+}
+
+type GraphHandler struct {
+	reports reportStore
+}
+
+// NewGraphHandler - constructor
+func NewGraphHandler(reports reportStore) *GraphHandler {
+	return &GraphHandler{
+		reports: reports,
+	}
+}
+
+// loadGraphData reads the filter of a graph export and loads the employees and staffing it draws from. It responds
+// and returns false if anything fails.
+func (h GraphHandler) loadGraphData(context *gin.Context) (instances.GraphFilter, []instances.Employee, []staffingLink, bool) {
+	var filter instances.GraphFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, nil, nil, false
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return filter, nil, nil, false
+	}
+	if filter.Format == "" {
+		filter.Format = instances.GraphDOT
+	}
+	employees, err := h.reports.Employees(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, nil, nil, false
+	}
+	links, err := h.reports.Staffing(context.Request.Context(), filter.Projects)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, nil, nil, false
+	}
+	return filter, employees, links, true
+}
+
+// respondGraph renders g in the format asked for
+func respondGraph(context *gin.Context, name string, g graph, format string) {
+	var body []byte
+	switch format {
+	case instances.GraphMermaid:
+		body = renderMermaid(g)
+	case instances.GraphGEXF:
+		var err error
+		if body, err = renderGEXF(g); err != nil {
+			context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	default:
+		body = renderDOT(name, g)
+	}
+	context.Data(http.StatusOK, graphContentTypes[format], body)
+}
+
+// exportOrgChart renders the reporting hierarchy
+func (h GraphHandler) exportOrgChart(context *gin.Context) {
+	filter, employees, links, ok := h.loadGraphData(context)
+	if !ok {
+		return
+	}
+	respondGraph(context, "org", orgGraph(employees, graphScope(employees, links, filter)), filter.Format)
+}
+
+// exportStaffing renders the network of employees, the projects they work on and the clients of the projects
+func (h GraphHandler) exportStaffing(context *gin.Context) {
+	filter, employees, links, ok := h.loadGraphData(context)
+	if !ok {
+		return
+	}
+	respondGraph(context, "staffing", staffingGraph(employees, links, graphScope(employees, links, filter), filter),
+		filter.Format)
+}
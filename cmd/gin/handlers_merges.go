@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type MergeHandler struct {
+	store mergeStore
+}
+
+// NewMergeHandler - constructor
+func NewMergeHandler(store mergeStore) *MergeHandler {
+	return &MergeHandler{
+		store: store,
+	}
+}
+
+type mergeFunc func(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error)
+
+// merge merges the record :id into :target with the store's merge of entity
+func (h MergeHandler) merge(context *gin.Context, entity string, merge mergeFunc) {
+	source, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	target, err := strconv.ParseInt(context.Params.ByName("target"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req instances.MergeRequest
+	if err := context.BindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if source == target {
+		validationFailed(context, validation.Errors{{Field: "target", Rule: "nefield",
+			Message: "a record cannot be merged into itself"}})
+		return
+	}
+
+	result, err := merge(context.Request.Context(), source, target, req)
+	switch {
+	case err == nil:
+		context.IndentedJSON(http.StatusOK, result)
+	case errors.Is(err, sql.ErrNoRows):
+		context.JSON(http.StatusNotFound, gin.H{"error": entity + " not found"})
+	case errors.Is(err, errManagerCycle):
+		validationFailed(context, validation.Errors{{Field: "target", Rule: "acyclic", Message: err.Error()}})
+	default:
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func (h MergeHandler) mergeSkills(context *gin.Context) {
+	h.merge(context, instances.MergeSkill, h.store.MergeSkills)
+}
+
+func (h MergeHandler) mergeEmployees(context *gin.Context) {
+	h.merge(context, instances.MergeEmployee, h.store.MergeEmployees)
+}
+
+func (h MergeHandler) mergeClients(context *gin.Context) {
+	h.merge(context, instances.MergeClient, h.store.MergeClients)
+}
+
+func (h MergeHandler) getMerges(context *gin.Context) {
+	var filter instances.MergeFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	merges, err := h.store.List(context.Request.Context(), filter)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, merges)
+}
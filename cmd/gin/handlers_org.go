@@ -0,0 +1,217 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type DepartmentHandler struct {
+	store departmentStore
+}
+
+// NewDepartmentHandler - constructor
+func NewDepartmentHandler(store departmentStore) *DepartmentHandler {
+	return &DepartmentHandler{
+		store: store,
+	}
+}
+
+// employeeFailed responds to an error adding or updating an employee
+func employeeFailed(context *gin.Context, err error) {
+	if errors.Is(err, errManagerCycle) {
+		validationFailed(context, validation.Errors{{Field: "manager_id", Rule: "acyclic", Message: err.Error()}})
+		return
+	}
+	if verrs, ok := referenceError(err); ok {
+		validationFailed(context, verrs)
+		return
+	}
+	context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+func (h DepartmentHandler) getDepartments(context *gin.Context) {
+	depts, err := h.store.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, depts)
+}
+
+func (h DepartmentHandler) getDepartment(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dept, err := h.store.Get(context.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "department not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, dept)
+}
+
+func (h DepartmentHandler) addDepartment(context *gin.Context) {
+	var dept instances.Department
+	if err := context.BindJSON(&dept); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(dept); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	id, err := h.store.Add(context.Request.Context(), dept)
+	if isDuplicate(err) {
+		context.JSON(http.StatusConflict, gin.H{"error": "a department with this name already exists"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"department_id": id})
+}
+
+func (h DepartmentHandler) updateDepartment(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dept, err := h.store.Get(context.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "department not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := context.BindJSON(&dept); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(dept); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, dept)
+	if isDuplicate(err) {
+		context.JSON(http.StatusConflict, gin.H{"error": "a department with this name already exists"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h DepartmentHandler) deleteDepartment(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.Delete(context.Request.Context(), id)
+	if isReferenced(err) {
+		context.JSON(http.StatusConflict, gin.H{"error": "the department still has employees"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+// getMembers lists the employees of a department
+func (h DepartmentHandler) getMembers(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.store.Get(context.Request.Context(), id); errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "department not found"})
+		return
+	} else if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employees, err := h.store.Members(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, employees)
+}
+
+// orgEmployees loads every employee and finds the one of the id parameter. It responds and returns false if the
+// employee does not exist.
+func (h EmployeeHandler) orgEmployees(context *gin.Context) ([]instances.Employee, instances.Employee, bool) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, instances.Employee{}, false
+	}
+	employees, err := h.store.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, instances.Employee{}, false
+	}
+	for _, emp := range employees {
+		if emp.EmployeeId == id {
+			return employees, emp, true
+		}
+	}
+	context.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+	return nil, instances.Employee{}, false
+}
+
+// getReports lists the employees reporting directly to an employee
+func (h EmployeeHandler) getReports(context *gin.Context) {
+	employees, emp, ok := h.orgEmployees(context)
+	if !ok {
+		return
+	}
+	reports := reportsByManager(employees)[emp.EmployeeId]
+	if reports == nil {
+		reports = []instances.Employee{}
+	}
+	context.IndentedJSON(http.StatusOK, reports)
+}
+
+// getSubtree returns an employee with everyone reporting to them directly or indirectly, nested by reporting line
+func (h EmployeeHandler) getSubtree(context *gin.Context) {
+	employees, emp, ok := h.orgEmployees(context)
+	if !ok {
+		return
+	}
+	context.IndentedJSON(http.StatusOK, buildOrgTree(employees, emp))
+}
+
+// getChain returns the managers of an employee up to the top of the organisation, the direct manager first
+func (h EmployeeHandler) getChain(context *gin.Context) {
+	employees, emp, ok := h.orgEmployees(context)
+	if !ok {
+		return
+	}
+	context.IndentedJSON(http.StatusOK, managementChain(employees, emp.EmployeeId))
+}
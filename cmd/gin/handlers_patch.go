@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/patch"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// errNeedsApproval stops patching a skill level that needs approval, which is requested for review instead of written
+var errNeedsApproval = errors.New("the level needs approval")
+
+// patchRequest is the body of a PATCH request, a JSON Merge Patch or a JSON Patch
+type patchRequest struct {
+	contentType string
+	body        []byte
+}
+
+// readPatch reads the patch in the body. It responds and returns false if the body is not a patch.
+func readPatch(context *gin.Context) (patchRequest, bool) {
+	p := patchRequest{contentType: context.ContentType()}
+	if p.contentType != patch.MergePatchType && p.contentType != patch.JSONPatchType {
+		context.JSON(http.StatusUnsupportedMediaType, gin.H{"error": patch.ErrUnsupportedType.Error()})
+		return p, false
+	}
+	body, err := io.ReadAll(context.Request.Body)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return p, false
+	}
+	p.body = body
+	return p, true
+}
+
+// applyPatch patches current and validates the result. Members the record does not have make the patch invalid.
+func applyPatch[T any](p patchRequest, current T) (T, error) {
+	var patched T
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return patched, err
+	}
+	doc, err = patch.Apply(p.contentType, doc, p.body)
+	if err != nil {
+		return patched, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return patched, fmt.Errorf("%w: %v", patch.ErrInvalid, err)
+	}
+	return patched, validation.Struct(patched)
+}
+
+// patchFailed responds to an error of patching a record of entity
+func patchFailed(context *gin.Context, entity string, err error) {
+	var verrs validation.Errors
+	switch {
+	case errors.As(err, &verrs):
+		validationFailed(context, verrs)
+	case errors.Is(err, patch.ErrTestFailed), errors.Is(err, errOverlappingStint):
+		context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		context.JSON(http.StatusNotFound, gin.H{"error": entity + " not found"})
+	case isDuplicate(err):
+		context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		// unknown references and reporting cycles are reported like invalid fields
+		employeeFailed(context, err)
+	}
+}
+
+// patchEmployee changes the fields of an employee the patch names, e.g. {"manager_id": null} removes the manager
+func (h EmployeeHandler) patchEmployee(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	emp, err := h.store.Patch(context.Request.Context(), id, func(current instances.Employee) (instances.Employee, error) {
+		emp, err := applyPatch(p, current)
+		if err != nil {
+			return emp, err
+		}
+		return emp, changedId("employee_id", id, emp.EmployeeId)
+	})
+	if err != nil {
+		patchFailed(context, "employee", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, emp)
+}
+
+// patchSkill changes the level of an employee skill and records it as an assessment. Levels that need approval are
+// requested for it like those sent in full. The scale and the caller are looked up before the skill is locked, so
+// the lock is only held while the patch is applied.
+func (h EmployeeHandler) patchSkill(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skillId, err := strconv.ParseInt(context.Params.ByName("skillId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	scale, err := h.scales.ForSkill(context.Request.Context(), skillId)
+	if err != nil {
+		patchFailed(context, "employee skill", err)
+		return
+	}
+	var assessesOther bool
+	if h.approvals != nil {
+		if assessesOther, err = h.assessesOther(context, id); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var pending instances.EmployeeSkill
+	empSkill, err := h.store.PatchSkill(context.Request.Context(), id, skillId, func(current instances.EmployeeSkill) (instances.EmployeeSkill, error) {
+		empSkill, err := applyPatch(p, current)
+		if err != nil {
+			return empSkill, err
+		}
+		if err := changedId("skill_id", skillId, empSkill.SkillId); err != nil {
+			return empSkill, err
+		}
+		if err := validation.ScaleLevel("skill_level", scale, empSkill.SkillLevel); err != nil {
+			return empSkill, err
+		}
+		if h.approvals != nil && approvalNeeded(empSkill.Source, assessesOther) {
+			pending = empSkill
+			return empSkill, errNeedsApproval
+		}
+		return withAssessor(context, empSkill), nil
+	})
+	if errors.Is(err, errNeedsApproval) {
+		h.requestChange(context, id, pending)
+		return
+	}
+	if err != nil {
+		patchFailed(context, "employee skill", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, empSkill)
+}
+
+// patchAssignment changes a stint of an employee on a project, e.g. {"end_date": null} makes it open-ended
+func (h EmployeeHandler) patchAssignment(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	assignmentId, err := strconv.ParseInt(context.Params.ByName("assignmentId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	empProject, err := h.store.PatchAssignment(context.Request.Context(), id, assignmentId, func(current instances.EmployeeProject) (instances.EmployeeProject, error) {
+		empProject, err := applyPatch(p, current)
+		if err != nil {
+			return empProject, err
+		}
+		return empProject, changedId("assignment_id", assignmentId, empProject.AssignmentId)
+	})
+	if err != nil {
+		patchFailed(context, "assignment", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, empProject)
+}
+
+func (h SkillHandler) patchSkill(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	skill, err := h.store.Patch(context.Request.Context(), id, func(current instances.Skill) (instances.Skill, error) {
+		skill, err := applyPatch(p, current)
+		if err != nil {
+			return skill, err
+		}
+		return skill, changedId("skill_id", id, int64(skill.SkillId))
+	})
+	if err != nil {
+		patchFailed(context, "skill", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, skill)
+}
+
+func (h ProjectHandler) patchProject(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	proj, err := h.store.Patch(context.Request.Context(), id, func(current instances.Project) (instances.Project, error) {
+		proj, err := applyPatch(p, current)
+		if err != nil {
+			return proj, err
+		}
+		return proj, changedId("project_id", id, proj.ProjectId)
+	})
+	if err != nil {
+		patchFailed(context, "project", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, proj)
+}
+
+func (h ClientHandler) patchClient(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, ok := readPatch(context)
+	if !ok {
+		return
+	}
+	client, err := h.store.Patch(context.Request.Context(), id, func(current instances.Client) (instances.Client, error) {
+		client, err := applyPatch(p, current)
+		if err != nil {
+			return client, err
+		}
+		return client, changedId("id", id, client.ID)
+	})
+	if err != nil {
+		patchFailed(context, "client", err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, client)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ProfileHandler exchanges employee profiles with other tools as JSON Resume and Europass documents
+type ProfileHandler struct {
+	employees employeeStore
+	clients   clientStore
+	skills    skillStore
+	scales    skillScaleStore
+	approvals skillChangeStore
+}
+
+// NewProfileHandler - constructor. approvals may be nil to disable the approval of self-assessed levels.
+func NewProfileHandler(employees employeeStore, clients clientStore, skills skillStore, scales skillScaleStore,
+	approvals skillChangeStore) *ProfileHandler {
+	return &ProfileHandler{
+		employees: employees,
+		clients:   clients,
+		skills:    skills,
+		scales:    scales,
+		approvals: approvals,
+	}
+}
+
+// profileCV loads the profile of the employee of the id parameter. It responds and returns false if that fails.
+func (h ProfileHandler) profileCV(context *gin.Context) (instances.CV, bool) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return instances.CV{}, false
+	}
+	full, err := h.employees.GetFull(context.Request.Context(), id, instances.AssignmentFilter{})
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "employee not found"})
+		return instances.CV{}, false
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return instances.CV{}, false
+	}
+	clients, err := clientNames(context, h.clients)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return instances.CV{}, false
+	}
+	return buildCV(full, clients), true
+}
+
+// getResume exports an employee as a JSON Resume
+func (h ProfileHandler) getResume(context *gin.Context) {
+	cv, ok := h.profileCV(context)
+	if !ok {
+		return
+	}
+	context.IndentedJSON(http.StatusOK, resumeFromCV(cv))
+}
+
+// getEuropass exports an employee as a Europass CV
+func (h ProfileHandler) getEuropass(context *gin.Context) {
+	cv, ok := h.profileCV(context)
+	if !ok {
+		return
+	}
+	body, err := renderEuropass(europassFromCV(cv, time.Now()))
+	if err != nil {
+		context.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	context.Header("Content-Disposition", fmt.Sprintf(`inline; filename="europass-%d.xml"`, cv.Employee.EmployeeId))
+	context.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// importResume creates or updates the employee of the id parameter from a JSON Resume and assigns the skills it
+// lists. Skills that match no skill name or alias, or whose level is not on the skill's scale, are reported back
+// instead of being assigned, and so are skills whose change request fails once the employee is saved.
+func (h ProfileHandler) importResume(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var resume instances.Resume
+	if err := context.BindJSON(&resume); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(resume); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	name, lastname := splitName(resume.Basics.Name)
+	emp := instances.Employee{
+		EmployeeId: id,
+		Name:       name,
+		Lastname:   lastname,
+		FocusArea:  resume.Basics.Label,
+		Email:      resume.Basics.Email,
+	}
+	if err := validation.Struct(emp); err != nil {
+		validationFailed(context, err)
+		return
+	}
+
+	skills, err := h.skills.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scaleList, err := h.scales.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scales := make(map[string]instances.SkillScale, len(scaleList))
+	for _, scale := range scaleList {
+		scales[scale.SkillClass] = scale
+	}
+	mapped, unmapped := mapResumeSkills(resume.Skills, newSkillIndex(skills), scales)
+
+	// resumes are self-assessments, with the approval workflow their levels wait for a reviewer
+	assign := mapped
+	if h.approvals != nil {
+		assign = nil
+	}
+	created, err := h.employees.ImportProfile(context.Request.Context(), emp, assign)
+	if err != nil {
+		employeeFailed(context, err)
+		return
+	}
+	result := instances.ResumeImport{EmployeeId: id, Created: created, Imported: assign, Unmapped: unmapped}
+	if result.Imported == nil {
+		result.Imported = []instances.EmployeeSkill{}
+	}
+	if h.approvals != nil {
+		for _, empSkill := range mapped {
+			requestId, err := h.approvals.Request(context.Request.Context(), id, empSkill)
+			if errors.Is(err, errChangePending) {
+				result.Unmapped = append(result.Unmapped, instances.UnmappedSkill{
+					Name:   skillName(skills, empSkill.SkillId),
+					Level:  strconv.FormatInt(empSkill.SkillLevel, 10),
+					Reason: err.Error(),
+				})
+				continue
+			}
+			if err != nil {
+				// the employee is saved already, so skills that could not be requested are reported, not the import
+				// failed
+				loggerFrom(context.Request.Context()).Warn("requesting an imported skill level failed",
+					"employee_id", id, "skill_id", empSkill.SkillId, "error", err)
+				result.Unmapped = append(result.Unmapped, instances.UnmappedSkill{
+					Name:   skillName(skills, empSkill.SkillId),
+					Level:  strconv.FormatInt(empSkill.SkillLevel, 10),
+					Reason: unmappedRequestFailed,
+				})
+				continue
+			}
+			result.PendingRequests = append(result.PendingRequests, requestId)
+		}
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	context.IndentedJSON(status, result)
+}
+
+// skillName returns the name of the skill with id skillId
+func skillName(skills []instances.Skill, skillId int64) string {
+	for _, skill := range skills {
+		if int64(skill.SkillId) == skillId {
+			return skill.Skill
+		}
+	}
+	return strconv.FormatInt(skillId, 10)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type rekeyFunc func(ctx context.Context, currId int64, newId int64) (instances.Rekey, error)
+
+// rekeyRecord changes the id of the record :id of entity to the new id in the body
+func rekeyRecord(context *gin.Context, entity string, rekey rekeyFunc) {
+	id, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req instances.RekeyRequest
+	if err := context.BindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if req.NewId == id {
+		validationFailed(context, validation.Errors{{Field: "new_id", Rule: "nefield",
+			Message: "new_id is the current id"}})
+		return
+	}
+
+	result, err := rekey(context.Request.Context(), id, req.NewId)
+	switch {
+	case err == nil:
+		context.IndentedJSON(http.StatusOK, result)
+	case errors.Is(err, sql.ErrNoRows):
+		context.JSON(http.StatusNotFound, gin.H{"error": entity + " not found"})
+	case isDuplicate(err):
+		context.JSON(http.StatusConflict, gin.H{"error": "new_id is already used"})
+	default:
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func (h SkillHandler) rekeySkill(context *gin.Context) {
+	rekeyRecord(context, "skill", h.store.Rekey)
+}
+
+func (h ProjectHandler) rekeyProject(context *gin.Context) {
+	rekeyRecord(context, "project", h.store.Rekey)
+}
+
+func (h ClientHandler) rekeyClient(context *gin.Context) {
+	rekeyRecord(context, "client", h.store.Rekey)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/csv"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mimeCSV is the content type of reports downloaded as spreadsheets
+const mimeCSV = "text/csv"
+
+// benchTopSkills is how many skills are listed for each employee on the bench
+const benchTopSkills = 3
+
+type ReportHandler struct {
+	store reportStore
+}
+
+// NewReportHandler - constructor
+func NewReportHandler(store reportStore) *ReportHandler {
+	return &ReportHandler{
+		store: store,
+	}
+}
+
+// bindCapacityFilter reads the period of a capacity report. It responds and returns false if it is invalid.
+func bindCapacityFilter(context *gin.Context) (instances.CapacityFilter, instances.Date, instances.Date, bool) {
+	var filter instances.CapacityFilter
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, instances.Date{}, instances.Date{}, false
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return filter, instances.Date{}, instances.Date{}, false
+	}
+	from, to, err := reportPeriod(filter, time.Now())
+	if err != nil {
+		validationFailed(context, err)
+		return filter, from, to, false
+	}
+	return filter, from, to, true
+}
+
+// inOrgScope keeps the employees and stints within the part of the organisation the filter selects
+func inOrgScope(employees []instances.Employee, stints []stint, filter instances.OrgFilter) ([]instances.Employee, []stint) {
+	scope := orgScope(employees, filter)
+	if scope == nil {
+		return employees, stints
+	}
+	var scopedEmployees []instances.Employee
+	for _, emp := range employees {
+		if scope[emp.EmployeeId] {
+			scopedEmployees = append(scopedEmployees, emp)
+		}
+	}
+	var scopedStints []stint
+	for _, st := range stints {
+		if scope[st.EmployeeId] {
+			scopedStints = append(scopedStints, st)
+		}
+	}
+	return scopedEmployees, scopedStints
+}
+
+// wantsCSV reports whether the client asked for CSV, with ?format=csv or the Accept header
+func wantsCSV(context *gin.Context) bool {
+	if format := context.Query("format"); format != "" {
+		return format == "csv"
+	}
+	return context.NegotiateFormat(gin.MIMEJSON, mimeCSV) == mimeCSV
+}
+
+// respondCSV writes records as a CSV download named name
+func respondCSV(context *gin.Context, name string, header []string, records [][]string) {
+	context.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+	context.Status(http.StatusOK)
+	w := csv.NewWriter(context.Writer)
+	w.Write(header)
+	w.WriteAll(records)
+}
+
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// employeeRecord is the leading columns of the CSV rows about an employee
+func employeeRecord(emp instances.Employee) []string {
+	return []string{strconv.FormatInt(emp.EmployeeId, 10), emp.Name, emp.Lastname, emp.FocusArea}
+}
+
+// getUtilization returns the allocation of every employee over the period and flags the overbooked ones
+func (h ReportHandler) getUtilization(context *gin.Context) {
+	filter, from, to, ok := bindCapacityFilter(context)
+	if !ok {
+		return
+	}
+	employees, err := h.store.Employees(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stints, err := h.store.Stints(context.Request.Context(), from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employees, stints = inOrgScope(employees, stints, filter.OrgFilter)
+	rows := []instances.Utilization{}
+	for _, u := range utilization(employees, stints, from, to) {
+		if !filter.Overbooked || u.Overbooked {
+			rows = append(rows, u)
+		}
+	}
+
+	if wantsCSV(context) {
+		records := make([][]string, len(rows))
+		for i, u := range rows {
+			records[i] = append(employeeRecord(u.Employee), formatPercent(u.Allocation),
+				strconv.Itoa(u.PeakAllocation), strconv.Itoa(u.OverbookedDays), strconv.FormatBool(u.Overbooked))
+		}
+		respondCSV(context, "utilization", []string{"employee_id", "name", "lastname", "focus_area", "allocation",
+			"peak_allocation", "overbooked_days", "overbooked"}, records)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"from": from, "to": to, "employees": rows})
+}
+
+// getBench lists the employees allocated no more than the threshold over the period, with their top skills
+func (h ReportHandler) getBench(context *gin.Context) {
+	filter, from, to, ok := bindCapacityFilter(context)
+	if !ok {
+		return
+	}
+	employees, err := h.store.Employees(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stints, err := h.store.Stints(context.Request.Context(), from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employees, stints = inOrgScope(employees, stints, filter.OrgFilter)
+	bench := []instances.BenchEmployee{}
+	var ids []int64
+	for _, u := range utilization(employees, stints, from, to) {
+		if u.Allocation <= float64(filter.Threshold) {
+			bench = append(bench, instances.BenchEmployee{Employee: u.Employee, Allocation: u.Allocation})
+			ids = append(ids, u.Employee.EmployeeId)
+		}
+	}
+	top, err := h.store.TopSkills(context.Request.Context(), ids, benchTopSkills)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range bench {
+		bench[i].TopSkills = top[bench[i].Employee.EmployeeId]
+		if bench[i].TopSkills == nil {
+			bench[i].TopSkills = []instances.Skill{}
+		}
+	}
+
+	if wantsCSV(context) {
+		records := make([][]string, len(bench))
+		for i, b := range bench {
+			skills := make([]string, len(b.TopSkills))
+			for j, s := range b.TopSkills {
+				skills[j] = fmt.Sprintf("%s (%d)", s.Skill, s.SkillLevel)
+			}
+			records[i] = append(employeeRecord(b.Employee), formatPercent(b.Allocation), strings.Join(skills, "; "))
+		}
+		respondCSV(context, "bench", []string{"employee_id", "name", "lastname", "focus_area", "allocation",
+			"top_skills"}, records)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"from": from, "to": to, "employees": bench})
+}
+
+// getFocusAreaUtilization returns the monthly staffing of the projects of each focus area
+func (h ReportHandler) getFocusAreaUtilization(context *gin.Context) {
+	filter, from, to, ok := bindCapacityFilter(context)
+	if !ok {
+		return
+	}
+	employees, err := h.store.Employees(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stints, err := h.store.Stints(context.Request.Context(), from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	employees, stints = inOrgScope(employees, stints, filter.OrgFilter)
+	rows := focusAreaUtilization(stints, len(employees), from, to)
+
+	if wantsCSV(context) {
+		records := make([][]string, len(rows))
+		for i, r := range rows {
+			records[i] = []string{r.Month, r.FocusArea, formatPercent(r.FTE), formatPercent(r.Utilization)}
+		}
+		respondCSV(context, "focus-area-utilization", []string{"month", "focus_area", "fte", "utilization"}, records)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"from": from, "to": to, "focus_areas": rows})
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type RequirementHandler struct {
+	store   requirementStore
+	scales  skillScaleStore
+	reports reportStore
+}
+
+// NewRequirementHandler - constructor. reports provides the allocations the availability of candidates is judged on.
+func NewRequirementHandler(store requirementStore, scales skillScaleStore, reports reportStore) *RequirementHandler {
+	return &RequirementHandler{
+		store:   store,
+		scales:  scales,
+		reports: reports,
+	}
+}
+
+// requirementIds reads the project and requirement ids from the path. It responds and returns false if one is invalid.
+func requirementIds(context *gin.Context) (int64, int64, bool) {
+	projectId, err := strconv.ParseInt(context.Params.ByName("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	requirementId, err := strconv.ParseInt(context.Params.ByName("requirementId"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return projectId, requirementId, true
+}
+
+// validateRequirement checks a requirement payload, including its minimum level against the scale of the skill.
+// A minimum level of 0 accepts any level. It responds and returns false if the requirement is invalid.
+func (h RequirementHandler) validateRequirement(context *gin.Context, req instances.ProjectRequirement) bool {
+	if err := validation.Struct(req); err != nil {
+		validationFailed(context, err)
+		return false
+	}
+	return req.MinLevel == 0 || validateScaleLevel(context, h.scales, req.SkillId, "min_level", req.MinLevel)
+}
+
+// requirementFailed responds to an error of a store call writing a requirement
+func requirementFailed(context *gin.Context, err error) {
+	if verrs, ok := referenceError(err); ok {
+		validationFailed(context, verrs)
+		return
+	}
+	if isDuplicate(err) {
+		context.JSON(http.StatusConflict, gin.H{"error": "the project already requires the skill at this level"})
+		return
+	}
+	context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+func (h RequirementHandler) getRequirements(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reqs, err := h.store.List(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, reqs)
+}
+
+func (h RequirementHandler) getRequirement(context *gin.Context) {
+	projectId, requirementId, ok := requirementIds(context)
+	if !ok {
+		return
+	}
+	req, err := h.store.Get(context.Request.Context(), projectId, requirementId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "requirement not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, req)
+}
+
+func (h RequirementHandler) addRequirement(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var req instances.ProjectRequirement
+	if err := context.BindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.ProjectId = id
+	if req.Headcount == 0 {
+		req.Headcount = 1
+	}
+	if !h.validateRequirement(context, req) {
+		return
+	}
+	requirementId, err := h.store.Add(context.Request.Context(), req)
+	if err != nil {
+		requirementFailed(context, err)
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"requirement_id": requirementId})
+}
+
+func (h RequirementHandler) updateRequirement(context *gin.Context) {
+	projectId, requirementId, ok := requirementIds(context)
+	if !ok {
+		return
+	}
+	req, err := h.store.Get(context.Request.Context(), projectId, requirementId)
+	if errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusNotFound, gin.H{"error": "requirement not found"})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := context.BindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Headcount == 0 {
+		req.Headcount = 1
+	}
+	if !h.validateRequirement(context, req) {
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), projectId, requirementId, req)
+	if err != nil {
+		requirementFailed(context, err)
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h RequirementHandler) deleteRequirement(context *gin.Context) {
+	projectId, requirementId, ok := requirementIds(context)
+	if !ok {
+		return
+	}
+	result, err := h.store.Delete(context.Request.Context(), projectId, requirementId)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+// getCoverage compares the requirements of a project against the skills of its current team and lists the gaps
+func (h RequirementHandler) getCoverage(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reqs, err := h.store.List(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	team, err := h.store.Team(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, coverage(id, reqs, team))
+}
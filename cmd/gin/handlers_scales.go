@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SkillScaleHandler struct {
+	store skillScaleStore
+}
+
+// NewSkillScaleHandler - constructor
+func NewSkillScaleHandler(store skillScaleStore) *SkillScaleHandler {
+	return &SkillScaleHandler{
+		store: store,
+	}
+}
+
+func (h SkillScaleHandler) getScales(context *gin.Context) {
+	scales, err := h.store.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"default": instances.DefaultSkillScale, "scales": scales})
+}
+
+func (h SkillScaleHandler) getScale(context *gin.Context) {
+	skillClass := context.Params.ByName("class")
+	scale, err := h.store.Get(context.Request.Context(), skillClass)
+	if errors.Is(err, sql.ErrNoRows) {
+		// classes without a scale of their own use the default one
+		scale = instances.DefaultSkillScale
+		scale.SkillClass = skillClass
+	} else if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, scale)
+}
+
+// putScale creates or replaces the scale of the skill class in the path
+func (h SkillScaleHandler) putScale(context *gin.Context) {
+	var scale instances.SkillScale
+	if err := context.BindJSON(&scale); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	scale.SkillClass = context.Params.ByName("class")
+	if err := validation.Struct(scale); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if err := h.store.Put(context.Request.Context(), scale); err != nil {
+		if errors.Is(err, errScaleInUse) {
+			context.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, scale)
+}
+
+func (h SkillScaleHandler) deleteScale(context *gin.Context) {
+	result, err := h.store.Delete(context.Request.Context(), context.Params.ByName("class"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+// validateSkillLevel checks the level of an employee skill against the scale of the skill's class.
+// It responds and returns false if the skill is unknown or the level is not on the scale.
+func validateSkillLevel(context *gin.Context, scales skillScaleStore, empSkill instances.EmployeeSkill) bool {
+	return validateScaleLevel(context, scales, empSkill.SkillId, "skill_level", empSkill.SkillLevel)
+}
+
+// validateScaleLevel is validateSkillLevel for a level sent in field
+func validateScaleLevel(context *gin.Context, scales skillScaleStore, skillId int64, field string, level int64) bool {
+	if err := checkScaleLevel(context.Request.Context(), scales, skillId, field, level); err != nil {
+		var verrs validation.Errors
+		if errors.As(err, &verrs) {
+			validationFailed(context, verrs)
+			return false
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// checkScaleLevel returns validation errors if the skill is unknown or the level is not on the scale of its class
+func checkScaleLevel(ctx context.Context, scales skillScaleStore, skillId int64, field string, level int64) error {
+	scale, err := scales.ForSkill(ctx, skillId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return validation.Errors{{
+			Field:   "skill_id",
+			Rule:    "exists",
+			Message: "skill_id does not reference an existing record",
+		}}
+	}
+	if err != nil {
+		return err
+	}
+	return validation.ScaleLevel(field, scale, level)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type SearchHandler struct {
+	index *searchIndex
+}
+
+// NewSearchHandler - constructor
+func NewSearchHandler(index *searchIndex) *SearchHandler {
+	return &SearchHandler{
+		index: index,
+	}
+}
+
+// search finds employees, skills, projects and clients by their names and descriptions, tolerating typos. Secret
+// projects are never found.
+func (h SearchHandler) search(context *gin.Context) {
+	var query instances.SearchQuery
+	if err := context.BindQuery(&query); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(query); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	hits, err := h.index.search(context.Request.Context(), query)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, hits)
+}
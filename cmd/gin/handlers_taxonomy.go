@@ -0,0 +1,250 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type SkillCategoryHandler struct {
+	store  skillCategoryStore
+	skills skillStore
+}
+
+// NewSkillCategoryHandler - constructor
+func NewSkillCategoryHandler(store skillCategoryStore, skills skillStore) *SkillCategoryHandler {
+	return &SkillCategoryHandler{
+		store:  store,
+		skills: skills,
+	}
+}
+
+// getCategoryTree returns the whole taxonomy, categories nested with their skills
+func (h SkillCategoryHandler) getCategoryTree(context *gin.Context) {
+	h.respondTree(context, 0)
+}
+
+// getCategory returns the subtree below a category
+func (h SkillCategoryHandler) getCategory(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.respondTree(context, id)
+}
+
+func (h SkillCategoryHandler) respondTree(context *gin.Context, root int64) {
+	categories, err := h.store.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skills, err := h.skills.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tree := buildCategoryTree(categories, skills, root)
+	if root == 0 {
+		context.IndentedJSON(http.StatusOK, tree)
+		return
+	}
+	if len(tree) == 0 {
+		context.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, tree[0])
+}
+
+func (h SkillCategoryHandler) addCategory(context *gin.Context) {
+	var category instances.SkillCategory
+	if err := context.BindJSON(&category); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(category); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	id, err := h.store.Add(context.Request.Context(), category)
+	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"category_id": id})
+}
+
+func (h SkillCategoryHandler) updateCategory(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	category, err := h.store.Get(context.Request.Context(), id)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := context.BindJSON(&category); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(category); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.Update(context.Request.Context(), id, category)
+	if err != nil {
+		if errors.Is(err, errCategoryCycle) {
+			validationFailed(context, validation.Errors{{Field: "parent_id", Rule: "acyclic", Message: err.Error()}})
+			return
+		}
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h SkillCategoryHandler) deleteCategory(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.Delete(context.Request.Context(), id)
+	if err != nil {
+		if isReferenced(err) {
+			context.JSON(http.StatusConflict, gin.H{"error": "category still has subcategories or skills"})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+func (h SkillHandler) addAlias(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var alias instances.SkillAlias
+	if err := context.BindJSON(&alias); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(alias); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	result, err := h.store.AddAlias(context.Request.Context(), id, alias.Alias)
+	if err != nil {
+		if verrs, ok := referenceError(err); ok {
+			validationFailed(context, verrs)
+			return
+		}
+		if isDuplicate(err) {
+			context.JSON(http.StatusConflict, gin.H{"error": "alias is already used"})
+			return
+		}
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusCreated, gin.H{"rows_affected": result})
+}
+
+func (h SkillHandler) deleteAlias(context *gin.Context) {
+	strId := context.Params.ByName("id")
+	id, err := strconv.ParseInt(strId, 10, 64)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.store.DeleteAlias(context.Request.Context(), id, context.Params.ByName("alias"))
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, gin.H{"rows_affected": result})
+}
+
+// getSuggestions completes what a user typed to the names of known skills
+func (h SkillHandler) getSuggestions(context *gin.Context) {
+	var query instances.SkillSuggest
+	if err := context.BindQuery(&query); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(query); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSuggestLimit
+	}
+	skills, err := h.store.List(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, suggestSkills(query.Prefix, skills, query.Limit))
+}
+
+// checkDeprecation validates the deprecation fields of a skill, which depend on each other
+func checkDeprecation(skill instances.Skill) error {
+	if skill.ReplacedBy == nil {
+		return nil
+	}
+	if !skill.Deprecated {
+		return validation.Errors{{Field: "replaced_by", Rule: "deprecated",
+			Message: "replaced_by can only be set on a deprecated skill"}}
+	}
+	if *skill.ReplacedBy == int64(skill.SkillId) {
+		return validation.Errors{{Field: "replaced_by", Rule: "nefield",
+			Message: "a skill cannot replace itself"}}
+	}
+	return nil
+}
+
+// searchEmployees finds employees by skill name or alias and/or by skill category, including subcategories
+func (h EmployeeHandler) searchEmployees(context *gin.Context) {
+	var filter instances.SkillSearch
+	if err := context.BindQuery(&filter); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(filter); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	if filter.Skill == "" && filter.CategoryId == 0 {
+		validationFailed(context, validation.Errors{{Field: "skill", Rule: "required_without",
+			Message: "skill or category is required"}})
+		return
+	}
+	matches, err := h.store.Search(context.Request.Context(), filter)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, matches)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+)
+
+type TeamHandler struct {
+	store   requirementStore
+	reports reportStore
+	skills  skillStore
+}
+
+// NewTeamHandler - constructor
+func NewTeamHandler(store requirementStore, reports reportStore, skills skillStore) *TeamHandler {
+	return &TeamHandler{
+		store:   store,
+		reports: reports,
+		skills:  skills,
+	}
+}
+
+// proposeTeams assembles teams covering the requirements of the request within its constraints. The proposals
+// come with the coverage of every requirement and are the same for the same data.
+func (h TeamHandler) proposeTeams(context *gin.Context) {
+	var req instances.TeamRequest
+	if err := context.BindJSON(&req); err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		validationFailed(context, err)
+		return
+	}
+	req = withTeamDefaults(req)
+	excluded := make(map[int64]bool, len(req.Exclude))
+	for _, id := range req.Exclude {
+		excluded[id] = true
+	}
+	for _, id := range req.MustInclude {
+		if excluded[id] {
+			validationFailed(context, validation.Errors{{
+				Field:   "must_include",
+				Rule:    "excluded",
+				Message: fmt.Sprintf("employee %d is both excluded and must be included", id),
+			}})
+			return
+		}
+	}
+
+	ctx := context.Request.Context()
+	skillIds := make([]int64, len(req.Requirements))
+	for i := range req.Requirements {
+		skill, err := h.skills.Get(ctx, req.Requirements[i].SkillId)
+		if errors.Is(err, sql.ErrNoRows) {
+			field := fmt.Sprintf("requirements[%d].skill_id", i)
+			validationFailed(context, validation.Errors{{
+				Field:   field,
+				Rule:    "exists",
+				Message: field + " does not reference an existing record",
+			}})
+			return
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Requirements[i].Skill = skill.Skill
+		skillIds[i] = req.Requirements[i].SkillId
+	}
+
+	holders, err := h.store.SkillHolders(ctx, skillIds)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	pool, ok := h.withMustInclude(context, holders, req.MustInclude)
+	if !ok {
+		return
+	}
+	from := today()
+	if req.From != nil {
+		from = *req.From
+	}
+	to := instances.Date{Time: from.AddDate(0, 0, req.Days-1)}
+	stints, err := h.reports.Stints(ctx, from, to)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	context.IndentedJSON(http.StatusOK, proposeTeams(req, pool, averageAllocation(stints, from, to)))
+}
+
+// withMustInclude adds the must-include employees without any of the required skills to the pool. It responds and
+// returns false if one of them does not exist.
+func (h TeamHandler) withMustInclude(context *gin.Context, pool []instances.EmployeeMatch, mustInclude []int64) ([]instances.EmployeeMatch, bool) {
+	inPool := make(map[int64]bool, len(pool))
+	for _, m := range pool {
+		inPool[m.Employee.EmployeeId] = true
+	}
+	var absent []int64
+	for _, id := range mustInclude {
+		if !inPool[id] {
+			absent = append(absent, id)
+		}
+	}
+	if len(absent) == 0 {
+		return pool, true
+	}
+
+	employees, err := h.reports.Employees(context.Request.Context())
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	byId := make(map[int64]instances.Employee, len(employees))
+	for _, emp := range employees {
+		byId[emp.EmployeeId] = emp
+	}
+	for _, id := range absent {
+		emp, ok := byId[id]
+		if !ok {
+			validationFailed(context, validation.Errors{{
+				Field:   "must_include",
+				Rule:    "exists",
+				Message: fmt.Sprintf("employee %d does not exist", id),
+			}})
+			return nil, false
+		}
+		pool = append(pool, instances.EmployeeMatch{Employee: emp})
+	}
+	sort.Slice(pool, func(i, j int) bool {
+		return pool[i].Employee.EmployeeId < pool[j].Employee.EmployeeId
+	})
+	return pool, true
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/migrations"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// version is the release of the server, set at build time with -ldflags "-X main.version=1.2.3"
+var version = "dev"
+
+// HealthHandler serves the probes of the orchestrator and the build information. Its endpoints are registered
+// outside of /v1 and must stay reachable without credentials.
+type HealthHandler struct {
+	// dbs are the connection pools to check, by the name of the store using them
+	dbs map[string]*sql.DB
+	// schemaDB is used to read the applied schema version
+	schemaDB     *sql.DB
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewHealthHandler - constructor
+func NewHealthHandler(dbs map[string]*sql.DB, schemaDB *sql.DB) *HealthHandler {
+	return &HealthHandler{
+		dbs:      dbs,
+		schemaDB: schemaDB,
+		timeout:  2 * time.Second,
+	}
+}
+
+// setShuttingDown makes the readiness probe fail, so no new traffic is routed to the server while it drains
+func (h *HealthHandler) setShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// healthz reports that the process is alive. It deliberately checks nothing else: a failing database
+// must not get the server restarted.
+func (h *HealthHandler) healthz(context *gin.Context) {
+	context.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+type checkResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// readyz reports whether the server can handle requests: every pool answers a ping and has a free connection,
+// and the database schema is at the version this build expects.
+func (h *HealthHandler) readyz(context *gin.Context) {
+	ctx, cancel := contextWithTimeout(context, h.timeout)
+	defer cancel()
+
+	var checks []checkResult
+	if h.shuttingDown.Load() {
+		checks = append(checks, checkResult{Name: "shutdown", OK: false, Detail: "server is shutting down"})
+	}
+
+	names := make([]string, 0, len(h.dbs))
+	for name := range h.dbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		db := h.dbs[name]
+		if err := db.PingContext(ctx); err != nil {
+			checks = append(checks, checkResult{Name: "db:" + name, OK: false, Detail: err.Error()})
+			continue
+		}
+		stats := db.Stats()
+		if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+			checks = append(checks, checkResult{Name: "pool:" + name, OK: false, Detail: "connection pool exhausted"})
+			continue
+		}
+		checks = append(checks, checkResult{Name: "db:" + name, OK: true})
+	}
+
+	current, err := migrations.Current(ctx, h.schemaDB)
+	switch {
+	case err != nil:
+		checks = append(checks, checkResult{Name: "migrations", OK: false, Detail: err.Error()})
+	case current < migrations.Latest():
+		checks = append(checks, checkResult{Name: "migrations", OK: false, Detail: "pending migrations"})
+	default:
+		checks = append(checks, checkResult{Name: "migrations", OK: true})
+	}
+
+	status, code := "ready", http.StatusOK
+	for _, c := range checks {
+		if !c.OK {
+			status, code = "not ready", http.StatusServiceUnavailable
+			break
+		}
+	}
+	context.JSON(code, gin.H{"status": status, "checks": checks})
+}
+
+// getVersion reports the build of the server and the schema versions of the database and of the build
+func (h *HealthHandler) getVersion(context *gin.Context) {
+	ctx, cancel := contextWithTimeout(context, h.timeout)
+	defer cancel()
+
+	info := gin.H{
+		"version":         version,
+		"expected_schema": migrations.Latest(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info["go_version"] = build.GoVersion
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info["commit"] = setting.Value
+			case "vcs.time":
+				info["commit_time"] = setting.Value
+			case "vcs.modified":
+				info["dirty"] = setting.Value == "true"
+			}
+		}
+	}
+	if current, err := migrations.Current(ctx, h.schemaDB); err == nil {
+		info["schema"] = current
+	} else {
+		info["schema_error"] = err.Error()
+	}
+	context.JSON(http.StatusOK, info)
+}
+
+// contextWithTimeout derives a context from the request that is cancelled after d
+func contextWithTimeout(c *gin.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.Request.Context(), d)
+}
@@ -0,0 +1,747 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// The instrumented stores wrap the store interfaces, record the duration and errors of every call and
+// trace it as a child span of the request. Handlers get the wrapped stores, so the database layer itself
+// stays free of instrumentation code.
+
+// startStoreCall starts the span of a store method. The returned function ends the span and records the metrics;
+// it is meant to be deferred with a pointer to the named error result.
+func startStoreCall(ctx context.Context, m *metrics, store, method string) (context.Context, func(*error)) {
+	start := time.Now()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, store+"."+method,
+		trace.WithAttributes(attribute.String("store", store), attribute.String("store.method", method)))
+	return ctx, func(err *error) {
+		if *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+		m.observeStore(store, method, start, err)
+	}
+}
+
+type instrumentedEmployeeStore struct {
+	next employeeStore
+	m    *metrics
+}
+
+func instrumentEmployeeStore(next employeeStore, m *metrics) employeeStore {
+	return instrumentedEmployeeStore{next: next, m: m}
+}
+
+func (s instrumentedEmployeeStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "employees", method)
+}
+
+func (s instrumentedEmployeeStore) Add(ctx context.Context, emp instances.Employee) (_ int, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, emp)
+}
+
+func (s instrumentedEmployeeStore) Get(ctx context.Context, employeeId int64) (_ instances.Employee, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, employeeId)
+}
+
+func (s instrumentedEmployeeStore) List(ctx context.Context) (_ []instances.Employee, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedEmployeeStore) Update(ctx context.Context, currId int64, emp instances.Employee) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, emp)
+}
+
+func (s instrumentedEmployeeStore) Patch(ctx context.Context, employeeId int64, apply func(instances.Employee) (instances.Employee, error)) (_ instances.Employee, err error) {
+	ctx, end := s.start(ctx, "Patch")
+	defer end(&err)
+	return s.next.Patch(ctx, employeeId, apply)
+}
+
+func (s instrumentedEmployeeStore) Delete(ctx context.Context, employeeId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, employeeId)
+}
+
+func (s instrumentedEmployeeStore) GetFull(ctx context.Context, employeeId int64, filter instances.AssignmentFilter) (_ instances.EmployeeFull, err error) {
+	ctx, end := s.start(ctx, "GetFull")
+	defer end(&err)
+	return s.next.GetFull(ctx, employeeId, filter)
+}
+
+func (s instrumentedEmployeeStore) ListFull(ctx context.Context, filter instances.AssignmentFilter) (_ []instances.EmployeeFull, err error) {
+	ctx, end := s.start(ctx, "ListFull")
+	defer end(&err)
+	return s.next.ListFull(ctx, filter)
+}
+
+func (s instrumentedEmployeeStore) AddSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (_ int64, err error) {
+	ctx, end := s.start(ctx, "AddSkill")
+	defer end(&err)
+	return s.next.AddSkill(ctx, employeeId, empSkill)
+}
+
+func (s instrumentedEmployeeStore) DeleteSkill(ctx context.Context, employeeId int64, skillId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "DeleteSkill")
+	defer end(&err)
+	return s.next.DeleteSkill(ctx, employeeId, skillId)
+}
+
+func (s instrumentedEmployeeStore) UpdateSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (_ int64, err error) {
+	ctx, end := s.start(ctx, "UpdateSkill")
+	defer end(&err)
+	return s.next.UpdateSkill(ctx, employeeId, empSkill)
+}
+
+func (s instrumentedEmployeeStore) PatchSkill(ctx context.Context, employeeId int64, skillId int64, apply func(instances.EmployeeSkill) (instances.EmployeeSkill, error)) (_ instances.EmployeeSkill, err error) {
+	ctx, end := s.start(ctx, "PatchSkill")
+	defer end(&err)
+	return s.next.PatchSkill(ctx, employeeId, skillId, apply)
+}
+
+func (s instrumentedEmployeeStore) SkillHistory(ctx context.Context, employeeId int64, skillId int64) (_ instances.SkillHistory, err error) {
+	ctx, end := s.start(ctx, "SkillHistory")
+	defer end(&err)
+	return s.next.SkillHistory(ctx, employeeId, skillId)
+}
+
+func (s instrumentedEmployeeStore) ImportProfile(ctx context.Context, emp instances.Employee, skills []instances.EmployeeSkill) (_ bool, err error) {
+	ctx, end := s.start(ctx, "ImportProfile")
+	defer end(&err)
+	return s.next.ImportProfile(ctx, emp, skills)
+}
+
+func (s instrumentedEmployeeStore) AddProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (_ int64, err error) {
+	ctx, end := s.start(ctx, "AddProject")
+	defer end(&err)
+	return s.next.AddProject(ctx, employeeId, empProject)
+}
+
+func (s instrumentedEmployeeStore) DeleteProject(ctx context.Context, projectId int64, employeeId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "DeleteProject")
+	defer end(&err)
+	return s.next.DeleteProject(ctx, projectId, employeeId)
+}
+
+func (s instrumentedEmployeeStore) UpdateProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (_ int64, err error) {
+	ctx, end := s.start(ctx, "UpdateProject")
+	defer end(&err)
+	return s.next.UpdateProject(ctx, employeeId, empProject)
+}
+
+func (s instrumentedEmployeeStore) Projects(ctx context.Context, employeeId int64, filter instances.AssignmentFilter) (_ []instances.ProjectFull, err error) {
+	ctx, end := s.start(ctx, "Projects")
+	defer end(&err)
+	return s.next.Projects(ctx, employeeId, filter)
+}
+
+func (s instrumentedEmployeeStore) UpdateAssignment(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (_ int64, err error) {
+	ctx, end := s.start(ctx, "UpdateAssignment")
+	defer end(&err)
+	return s.next.UpdateAssignment(ctx, employeeId, empProject)
+}
+
+func (s instrumentedEmployeeStore) PatchAssignment(ctx context.Context, employeeId int64, assignmentId int64, apply func(instances.EmployeeProject) (instances.EmployeeProject, error)) (_ instances.EmployeeProject, err error) {
+	ctx, end := s.start(ctx, "PatchAssignment")
+	defer end(&err)
+	return s.next.PatchAssignment(ctx, employeeId, assignmentId, apply)
+}
+
+func (s instrumentedEmployeeStore) DeleteAssignment(ctx context.Context, employeeId int64, assignmentId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "DeleteAssignment")
+	defer end(&err)
+	return s.next.DeleteAssignment(ctx, employeeId, assignmentId)
+}
+
+func (s instrumentedEmployeeStore) Search(ctx context.Context, filter instances.SkillSearch) (_ []instances.EmployeeMatch, err error) {
+	ctx, end := s.start(ctx, "Search")
+	defer end(&err)
+	return s.next.Search(ctx, filter)
+}
+
+type instrumentedSkillStore struct {
+	next skillStore
+	m    *metrics
+}
+
+func instrumentSkillStore(next skillStore, m *metrics) skillStore {
+	return instrumentedSkillStore{next: next, m: m}
+}
+
+func (s instrumentedSkillStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "skills", method)
+}
+
+func (s instrumentedSkillStore) Add(ctx context.Context, skill instances.Skill) (_ int, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, skill)
+}
+
+func (s instrumentedSkillStore) Get(ctx context.Context, skillId int64) (_ instances.Skill, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, skillId)
+}
+
+func (s instrumentedSkillStore) List(ctx context.Context) (_ []instances.Skill, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedSkillStore) Update(ctx context.Context, currId int64, skill instances.Skill) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, skill)
+}
+
+func (s instrumentedSkillStore) Patch(ctx context.Context, skillId int64, apply func(instances.Skill) (instances.Skill, error)) (_ instances.Skill, err error) {
+	ctx, end := s.start(ctx, "Patch")
+	defer end(&err)
+	return s.next.Patch(ctx, skillId, apply)
+}
+
+func (s instrumentedSkillStore) Delete(ctx context.Context, skillId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, skillId)
+}
+
+func (s instrumentedSkillStore) AddAlias(ctx context.Context, skillId int64, alias string) (_ int64, err error) {
+	ctx, end := s.start(ctx, "AddAlias")
+	defer end(&err)
+	return s.next.AddAlias(ctx, skillId, alias)
+}
+
+func (s instrumentedSkillStore) DeleteAlias(ctx context.Context, skillId int64, alias string) (_ int64, err error) {
+	ctx, end := s.start(ctx, "DeleteAlias")
+	defer end(&err)
+	return s.next.DeleteAlias(ctx, skillId, alias)
+}
+
+func (s instrumentedSkillStore) Rekey(ctx context.Context, currId int64, newId int64) (_ instances.Rekey, err error) {
+	ctx, end := s.start(ctx, "Rekey")
+	defer end(&err)
+	return s.next.Rekey(ctx, currId, newId)
+}
+
+type instrumentedProjectStore struct {
+	next projectStore
+	m    *metrics
+}
+
+func instrumentProjectStore(next projectStore, m *metrics) projectStore {
+	return instrumentedProjectStore{next: next, m: m}
+}
+
+func (s instrumentedProjectStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "projects", method)
+}
+
+func (s instrumentedProjectStore) Add(ctx context.Context, proj instances.Project) (_ int, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, proj)
+}
+
+func (s instrumentedProjectStore) Get(ctx context.Context, projId int64) (_ instances.Project, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, projId)
+}
+
+func (s instrumentedProjectStore) List(ctx context.Context) (_ []instances.Project, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedProjectStore) Update(ctx context.Context, currId int64, proj instances.Project) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, proj)
+}
+
+func (s instrumentedProjectStore) Patch(ctx context.Context, projId int64, apply func(instances.Project) (instances.Project, error)) (_ instances.Project, err error) {
+	ctx, end := s.start(ctx, "Patch")
+	defer end(&err)
+	return s.next.Patch(ctx, projId, apply)
+}
+
+func (s instrumentedProjectStore) Delete(ctx context.Context, projId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, projId)
+}
+
+func (s instrumentedProjectStore) Rekey(ctx context.Context, currId int64, newId int64) (_ instances.Rekey, err error) {
+	ctx, end := s.start(ctx, "Rekey")
+	defer end(&err)
+	return s.next.Rekey(ctx, currId, newId)
+}
+
+type instrumentedClientStore struct {
+	next clientStore
+	m    *metrics
+}
+
+func instrumentClientStore(next clientStore, m *metrics) clientStore {
+	return instrumentedClientStore{next: next, m: m}
+}
+
+func (s instrumentedClientStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "clients", method)
+}
+
+func (s instrumentedClientStore) Add(ctx context.Context, client instances.Client) (_ int, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, client)
+}
+
+func (s instrumentedClientStore) Get(ctx context.Context, clientId int64) (_ instances.Client, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, clientId)
+}
+
+func (s instrumentedClientStore) List(ctx context.Context) (_ []instances.Client, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedClientStore) Update(ctx context.Context, currId int64, client instances.Client) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, client)
+}
+
+func (s instrumentedClientStore) Patch(ctx context.Context, clientId int64, apply func(instances.Client) (instances.Client, error)) (_ instances.Client, err error) {
+	ctx, end := s.start(ctx, "Patch")
+	defer end(&err)
+	return s.next.Patch(ctx, clientId, apply)
+}
+
+func (s instrumentedClientStore) Delete(ctx context.Context, clientId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, clientId)
+}
+
+func (s instrumentedClientStore) Rekey(ctx context.Context, currId int64, newId int64) (_ instances.Rekey, err error) {
+	ctx, end := s.start(ctx, "Rekey")
+	defer end(&err)
+	return s.next.Rekey(ctx, currId, newId)
+}
+
+type instrumentedSkillScaleStore struct {
+	next skillScaleStore
+	m    *metrics
+}
+
+func instrumentSkillScaleStore(next skillScaleStore, m *metrics) skillScaleStore {
+	return instrumentedSkillScaleStore{next: next, m: m}
+}
+
+func (s instrumentedSkillScaleStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "skillScales", method)
+}
+
+func (s instrumentedSkillScaleStore) List(ctx context.Context) (_ []instances.SkillScale, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedSkillScaleStore) Get(ctx context.Context, skillClass string) (_ instances.SkillScale, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, skillClass)
+}
+
+func (s instrumentedSkillScaleStore) ForSkill(ctx context.Context, skillId int64) (_ instances.SkillScale, err error) {
+	ctx, end := s.start(ctx, "ForSkill")
+	defer end(&err)
+	return s.next.ForSkill(ctx, skillId)
+}
+
+func (s instrumentedSkillScaleStore) Put(ctx context.Context, scale instances.SkillScale) (err error) {
+	ctx, end := s.start(ctx, "Put")
+	defer end(&err)
+	return s.next.Put(ctx, scale)
+}
+
+func (s instrumentedSkillScaleStore) Delete(ctx context.Context, skillClass string) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, skillClass)
+}
+
+type instrumentedSkillCategoryStore struct {
+	next skillCategoryStore
+	m    *metrics
+}
+
+func instrumentSkillCategoryStore(next skillCategoryStore, m *metrics) skillCategoryStore {
+	return instrumentedSkillCategoryStore{next: next, m: m}
+}
+
+func (s instrumentedSkillCategoryStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "skillCategories", method)
+}
+
+func (s instrumentedSkillCategoryStore) Add(ctx context.Context, category instances.SkillCategory) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, category)
+}
+
+func (s instrumentedSkillCategoryStore) Get(ctx context.Context, categoryId int64) (_ instances.SkillCategory, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, categoryId)
+}
+
+func (s instrumentedSkillCategoryStore) List(ctx context.Context) (_ []instances.SkillCategory, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedSkillCategoryStore) Update(ctx context.Context, currId int64, category instances.SkillCategory) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, category)
+}
+
+func (s instrumentedSkillCategoryStore) Delete(ctx context.Context, categoryId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, categoryId)
+}
+
+type instrumentedSkillChangeStore struct {
+	next skillChangeStore
+	m    *metrics
+}
+
+func instrumentSkillChangeStore(next skillChangeStore, m *metrics) skillChangeStore {
+	return instrumentedSkillChangeStore{next: next, m: m}
+}
+
+func (s instrumentedSkillChangeStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "skillChanges", method)
+}
+
+func (s instrumentedSkillChangeStore) Request(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Request")
+	defer end(&err)
+	return s.next.Request(ctx, employeeId, empSkill)
+}
+
+func (s instrumentedSkillChangeStore) Get(ctx context.Context, requestId int64) (_ instances.SkillChangeRequest, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, requestId)
+}
+
+func (s instrumentedSkillChangeStore) List(ctx context.Context, filter instances.SkillChangeFilter) (_ []instances.SkillChangeRequest, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx, filter)
+}
+
+func (s instrumentedSkillChangeStore) Review(ctx context.Context, requestId int64, review instances.SkillReview, approve bool) (_ instances.SkillChangeRequest, err error) {
+	ctx, end := s.start(ctx, "Review")
+	defer end(&err)
+	return s.next.Review(ctx, requestId, review, approve)
+}
+
+func (s instrumentedSkillChangeStore) Owners(ctx context.Context, skillId int64) (_ []instances.SkillOwner, err error) {
+	ctx, end := s.start(ctx, "Owners")
+	defer end(&err)
+	return s.next.Owners(ctx, skillId)
+}
+
+func (s instrumentedSkillChangeStore) AddOwner(ctx context.Context, skillId int64, employeeId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "AddOwner")
+	defer end(&err)
+	return s.next.AddOwner(ctx, skillId, employeeId)
+}
+
+func (s instrumentedSkillChangeStore) DeleteOwner(ctx context.Context, skillId int64, employeeId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "DeleteOwner")
+	defer end(&err)
+	return s.next.DeleteOwner(ctx, skillId, employeeId)
+}
+
+func (s instrumentedSkillChangeStore) Caller(ctx context.Context, principal string) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Caller")
+	defer end(&err)
+	return s.next.Caller(ctx, principal)
+}
+
+type instrumentedCertificationStore struct {
+	next certificationStore
+	m    *metrics
+}
+
+func instrumentCertificationStore(next certificationStore, m *metrics) certificationStore {
+	return instrumentedCertificationStore{next: next, m: m}
+}
+
+func (s instrumentedCertificationStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "certifications", method)
+}
+
+func (s instrumentedCertificationStore) Add(ctx context.Context, cert instances.Certification) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, cert)
+}
+
+func (s instrumentedCertificationStore) Get(ctx context.Context, certificationId int64) (_ instances.Certification, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, certificationId)
+}
+
+func (s instrumentedCertificationStore) List(ctx context.Context, filter instances.CertificationFilter) (_ []instances.Certification, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx, filter)
+}
+
+func (s instrumentedCertificationStore) Update(ctx context.Context, currId int64, cert instances.Certification) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, cert)
+}
+
+func (s instrumentedCertificationStore) Delete(ctx context.Context, certificationId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, certificationId)
+}
+
+func (s instrumentedCertificationStore) Expiring(ctx context.Context, filter instances.ExpiringFilter) (_ []instances.Certification, err error) {
+	ctx, end := s.start(ctx, "Expiring")
+	defer end(&err)
+	return s.next.Expiring(ctx, filter)
+}
+
+func (s instrumentedCertificationStore) Headcount(ctx context.Context) (_ []instances.CertifiedHeadcount, err error) {
+	ctx, end := s.start(ctx, "Headcount")
+	defer end(&err)
+	return s.next.Headcount(ctx)
+}
+
+type instrumentedReportStore struct {
+	next reportStore
+	m    *metrics
+}
+
+func instrumentReportStore(next reportStore, m *metrics) reportStore {
+	return instrumentedReportStore{next: next, m: m}
+}
+
+func (s instrumentedReportStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "reports", method)
+}
+
+func (s instrumentedReportStore) Employees(ctx context.Context) (_ []instances.Employee, err error) {
+	ctx, end := s.start(ctx, "Employees")
+	defer end(&err)
+	return s.next.Employees(ctx)
+}
+
+func (s instrumentedReportStore) Stints(ctx context.Context, from instances.Date, to instances.Date) (_ []stint, err error) {
+	ctx, end := s.start(ctx, "Stints")
+	defer end(&err)
+	return s.next.Stints(ctx, from, to)
+}
+
+func (s instrumentedReportStore) TopSkills(ctx context.Context, employeeIds []int64, n int) (_ map[int64][]instances.Skill, err error) {
+	ctx, end := s.start(ctx, "TopSkills")
+	defer end(&err)
+	return s.next.TopSkills(ctx, employeeIds, n)
+}
+
+func (s instrumentedReportStore) Staffing(ctx context.Context, status string) (_ []staffingLink, err error) {
+	ctx, end := s.start(ctx, "Staffing")
+	defer end(&err)
+	return s.next.Staffing(ctx, status)
+}
+
+type instrumentedRequirementStore struct {
+	next requirementStore
+	m    *metrics
+}
+
+func instrumentRequirementStore(next requirementStore, m *metrics) requirementStore {
+	return instrumentedRequirementStore{next: next, m: m}
+}
+
+func (s instrumentedRequirementStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "requirements", method)
+}
+
+func (s instrumentedRequirementStore) Add(ctx context.Context, req instances.ProjectRequirement) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, req)
+}
+
+func (s instrumentedRequirementStore) Get(ctx context.Context, projectId int64, requirementId int64) (_ instances.ProjectRequirement, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, projectId, requirementId)
+}
+
+func (s instrumentedRequirementStore) List(ctx context.Context, projectId int64) (_ []instances.ProjectRequirement, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx, projectId)
+}
+
+func (s instrumentedRequirementStore) Update(ctx context.Context, projectId int64, requirementId int64, req instances.ProjectRequirement) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, projectId, requirementId, req)
+}
+
+func (s instrumentedRequirementStore) Delete(ctx context.Context, projectId int64, requirementId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, projectId, requirementId)
+}
+
+func (s instrumentedRequirementStore) Team(ctx context.Context, projectId int64) (_ []instances.EmployeeMatch, err error) {
+	ctx, end := s.start(ctx, "Team")
+	defer end(&err)
+	return s.next.Team(ctx, projectId)
+}
+
+func (s instrumentedRequirementStore) Candidates(ctx context.Context, projectId int64, includeTeam bool) (_ []instances.EmployeeMatch, err error) {
+	ctx, end := s.start(ctx, "Candidates")
+	defer end(&err)
+	return s.next.Candidates(ctx, projectId, includeTeam)
+}
+
+func (s instrumentedRequirementStore) Experience(ctx context.Context, projectId int64) (_ map[int64][]int64, err error) {
+	ctx, end := s.start(ctx, "Experience")
+	defer end(&err)
+	return s.next.Experience(ctx, projectId)
+}
+
+func (s instrumentedRequirementStore) SkillHolders(ctx context.Context, skillIds []int64) (_ []instances.EmployeeMatch, err error) {
+	ctx, end := s.start(ctx, "SkillHolders")
+	defer end(&err)
+	return s.next.SkillHolders(ctx, skillIds)
+}
+
+type instrumentedDepartmentStore struct {
+	next departmentStore
+	m    *metrics
+}
+
+func instrumentDepartmentStore(next departmentStore, m *metrics) departmentStore {
+	return instrumentedDepartmentStore{next: next, m: m}
+}
+
+func (s instrumentedDepartmentStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "departments", method)
+}
+
+func (s instrumentedDepartmentStore) Add(ctx context.Context, dept instances.Department) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Add")
+	defer end(&err)
+	return s.next.Add(ctx, dept)
+}
+
+func (s instrumentedDepartmentStore) Get(ctx context.Context, departmentId int64) (_ instances.Department, err error) {
+	ctx, end := s.start(ctx, "Get")
+	defer end(&err)
+	return s.next.Get(ctx, departmentId)
+}
+
+func (s instrumentedDepartmentStore) List(ctx context.Context) (_ []instances.Department, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx)
+}
+
+func (s instrumentedDepartmentStore) Update(ctx context.Context, currId int64, dept instances.Department) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Update")
+	defer end(&err)
+	return s.next.Update(ctx, currId, dept)
+}
+
+func (s instrumentedDepartmentStore) Delete(ctx context.Context, departmentId int64) (_ int64, err error) {
+	ctx, end := s.start(ctx, "Delete")
+	defer end(&err)
+	return s.next.Delete(ctx, departmentId)
+}
+
+func (s instrumentedDepartmentStore) Members(ctx context.Context, departmentId int64) (_ []instances.Employee, err error) {
+	ctx, end := s.start(ctx, "Members")
+	defer end(&err)
+	return s.next.Members(ctx, departmentId)
+}
+
+type instrumentedMergeStore struct {
+	next mergeStore
+	m    *metrics
+}
+
+func instrumentMergeStore(next mergeStore, m *metrics) mergeStore {
+	return instrumentedMergeStore{next: next, m: m}
+}
+
+func (s instrumentedMergeStore) start(ctx context.Context, method string) (context.Context, func(*error)) {
+	return startStoreCall(ctx, s.m, "merges", method)
+}
+
+func (s instrumentedMergeStore) MergeSkills(ctx context.Context, source int64, target int64, req instances.MergeRequest) (_ instances.Merge, err error) {
+	ctx, end := s.start(ctx, "MergeSkills")
+	defer end(&err)
+	return s.next.MergeSkills(ctx, source, target, req)
+}
+
+func (s instrumentedMergeStore) MergeEmployees(ctx context.Context, source int64, target int64, req instances.MergeRequest) (_ instances.Merge, err error) {
+	ctx, end := s.start(ctx, "MergeEmployees")
+	defer end(&err)
+	return s.next.MergeEmployees(ctx, source, target, req)
+}
+
+func (s instrumentedMergeStore) MergeClients(ctx context.Context, source int64, target int64, req instances.MergeRequest) (_ instances.Merge, err error) {
+	ctx, end := s.start(ctx, "MergeClients")
+	defer end(&err)
+	return s.next.MergeClients(ctx, source, target, req)
+}
+
+func (s instrumentedMergeStore) List(ctx context.Context, filter instances.MergeFilter) (_ []instances.Merge, err error) {
+	ctx, end := s.start(ctx, "List")
+	defer end(&err)
+	return s.next.List(ctx, filter)
+}
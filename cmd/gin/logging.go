@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// principalKey is the gin context key under which the authenticate middleware stores the caller's identity.
+// The request logger picks it up, so every access log line of an authenticated request says who made it. A principal
+// that is the email of an employee makes that employee the caller, e.g. of skill assessments and reviews.
+const principalKey = "principal"
+
+type loggerCtxKey struct{}
+
+// newLogger builds the application logger. format is either "text" or "json", level one of debug/info/warn/error
+func newLogger(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %v", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected text or json", format)
+	}
+}
+
+// withLogger returns a copy of ctx carrying logger
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+// loggerFrom returns the request-scoped logger stored in ctx, falling back to the default logger
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// newRequestID generates a random 128-bit request id in hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestLogger is a gin middleware replacing gin.Logger. It takes the request id from X-Request-ID (or generates one),
+// echoes it back, attaches a request-scoped logger to the request context and writes one access log line per request.
+func requestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		reqLogger := logger.With(
+			slog.String("request_id", requestID),
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+		)
+		// correlate logs with traces when the request is being traced
+		if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			reqLogger = reqLogger.With(slog.String("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(withLogger(c.Request.Context(), reqLogger))
+
+		c.Next()
+
+		attrs := []any{
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if principal := c.GetString(principalKey); principal != "" {
+			attrs = append(attrs, slog.String("principal", principal))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= 500:
+			level = slog.LevelError
+		case c.Writer.Status() >= 400:
+			level = slog.LevelWarn
+		}
+		reqLogger.Log(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+// queryError logs a failed store operation with the request-scoped logger and returns err unchanged,
+// so it can be used directly in return statements. Missing rows are logged at debug level only,
+// since they are an expected outcome of lookups by id.
+func queryError(ctx context.Context, op string, err error, attrs ...any) error {
+	level := slog.LevelError
+	if errors.Is(err, sql.ErrNoRows) {
+		level = slog.LevelDebug
+	}
+	loggerFrom(ctx).Log(ctx, level, "query failed", append([]any{slog.String("op", op), slog.Any("error", err)}, attrs...)...)
+	return err
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "json", "debug")
+	assert.NoError(t, err)
+
+	eng := gin.New()
+	eng.Use(requestLogger(logger))
+	eng.GET("/employees/:id", func(context *gin.Context) {
+		context.Set(principalKey, "jdoe")
+		loggerFrom(context.Request.Context()).Info("inside handler")
+		context.Status(http.StatusNoContent)
+	})
+
+	// the request id sent by the client is kept and echoed back
+	req, _ := http.NewRequest("GET", "/employees/1", nil)
+	req.Header.Set(requestIDHeader, "abc123")
+	w := httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Equal(t, "abc123", w.Header().Get(requestIDHeader))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	var handlerLine, accessLine map[string]any
+	assert.NoError(t, json.Unmarshal(lines[0], &handlerLine))
+	assert.NoError(t, json.Unmarshal(lines[1], &accessLine))
+	assert.Equal(t, "abc123", handlerLine["request_id"])
+	assert.Equal(t, "/employees/:id", accessLine["route"])
+	assert.Equal(t, float64(http.StatusNoContent), accessLine["status"])
+	assert.Equal(t, "jdoe", accessLine["principal"])
+
+	// without the header a request id is generated
+	req, _ = http.NewRequest("GET", "/employees/1", nil)
+	w = httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Len(t, w.Header().Get(requestIDHeader), 32)
+}
+
+func TestNewLoggerRejectsInvalidSettings(t *testing.T) {
+	_, err := newLogger(&bytes.Buffer{}, "xml", "info")
+	assert.Error(t, err)
+	_, err = newLogger(&bytes.Buffer{}, "text", "verbose")
+	assert.Error(t, err)
+}
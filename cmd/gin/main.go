@@ -1,13 +1,23 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/migrations"
 	"github.com/gin-gonic/gin"
 	"github.com/go-sql-driver/mysql"
-	"log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-// TODO introduce a logger
 // TODO tests can be written in .http format
 // TODO also, consider using a router gorilla/mux
 // TODO ids should probably be a uint
@@ -17,6 +27,21 @@ import (
 // TODO adding, updating, deleting an Employee to a Project
 
 func main() {
+	appCfg, err := loadConfig()
+	if err != nil {
+		fatal("loading configuration", err)
+	}
+	logger, err := newLogger(os.Stderr, appCfg.LogFormat, appCfg.LogLevel)
+	if err != nil {
+		fatal("configuring logger", err)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := setupTracing(context.Background(), appCfg.TracesExporter, appCfg.TraceFile)
+	if err != nil {
+		fatal("configuring tracing", err)
+	}
+
 	// Capture connection properties.
 	// TODO read cfg from a separate file in gitignore
 	cfg := mysql.Config{
@@ -26,39 +51,173 @@ func main() {
 		Addr:                 "127.0.0.1:3306",
 		DBName:               "esmdb",
 		AllowNativePasswords: true,
+		ParseTime:            true,
 	}
 
 	// create stores
 	empStore, err := NewEmployeeStore(cfg)
 	if err != nil {
-		log.Fatal(err)
+		fatal("creating employee store", err)
 	}
 	skillStore, err := NewSkillStore(cfg)
 	if err != nil {
-		log.Fatal(err)
+		fatal("creating skill store", err)
 	}
 	projectStore, err := NewProjectStore(cfg)
 	if err != nil {
-		log.Fatal(err)
+		fatal("creating project store", err)
 	}
 	clientStore, err := NewClientStore(cfg)
 	if err != nil {
-		log.Fatal(err)
+		fatal("creating client store", err)
+	}
+	scaleStore, err := NewSkillScaleStore(cfg)
+	if err != nil {
+		fatal("creating skill scale store", err)
+	}
+	categoryStore, err := NewSkillCategoryStore(cfg)
+	if err != nil {
+		fatal("creating skill category store", err)
+	}
+	changeStore, err := NewSkillChangeStore(cfg)
+	if err != nil {
+		fatal("creating skill change store", err)
+	}
+	certStore, err := NewCertificationStore(cfg)
+	if err != nil {
+		fatal("creating certification store", err)
+	}
+	reportStore, err := NewReportStore(cfg)
+	if err != nil {
+		fatal("creating report store", err)
+	}
+	requirementStore, err := NewRequirementStore(cfg)
+	if err != nil {
+		fatal("creating requirement store", err)
+	}
+	departmentStore, err := NewDepartmentStore(cfg)
+	if err != nil {
+		fatal("creating department store", err)
+	}
+	mergeStore, err := NewMergeStore(cfg)
+	if err != nil {
+		fatal("creating merge store", err)
+	}
+	dbs := map[string]*sql.DB{
+		"employees":       empStore.db,
+		"skills":          skillStore.db,
+		"projects":        projectStore.db,
+		"clients":         clientStore.db,
+		"skillScales":     scaleStore.db,
+		"skillCategories": categoryStore.db,
+		"skillChanges":    changeStore.db,
+		"certifications":  certStore.db,
+		"reports":         reportStore.db,
+		"requirements":    requirementStore.db,
+		"departments":     departmentStore.db,
+		"merges":          mergeStore.db,
+	}
+	for _, db := range dbs {
+		db.SetMaxOpenConns(appCfg.DBMaxOpenConns)
 	}
+
+	if appCfg.MigrateOnStart {
+		applied, err := migrations.Apply(context.Background(), empStore.db)
+		if err != nil {
+			fatal("applying migrations", err)
+		}
+		slog.Info("database schema up to date", "applied", applied, "version", migrations.Latest())
+	}
+
+	// set up metrics
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	m := newMetrics(reg)
+	for name, db := range dbs {
+		registerDBStats(reg, name, db)
+	}
+	reg.MustRegister(newBusinessCollector(empStore.db))
+
 	// create handlers
-	empHandler := NewEmployeeHandler(empStore)
-	skillHandler := NewSkillHandler(skillStore)
-	projectHandler := NewProjectHandler(projectStore)
-	clientHandler := NewClientHandler(clientStore)
+	scales := instrumentSkillScaleStore(scaleStore, m)
+	changes := instrumentSkillChangeStore(changeStore, m)
+	var approvals skillChangeStore
+	if appCfg.SkillApproval {
+		approvals = changes
+	}
+	instrumentedEmployees := instrumentEmployeeStore(empStore, m)
+	instrumentedSkills := instrumentSkillStore(skillStore, m)
+	instrumentedProjects := instrumentProjectStore(projectStore, m)
+	instrumentedClients := instrumentClientStore(clientStore, m)
+	// the search index loads through the instrumented stores, all writes go through the indexed ones
+	index := newSearchIndex(instrumentedEmployees, instrumentedSkills, instrumentedProjects, instrumentedClients)
+	employees := indexedEmployeeStore{instrumentedEmployees, index}
+	skills := indexedSkillStore{instrumentedSkills, index}
+	projects := indexedProjectStore{instrumentedProjects, index}
+	clients := indexedClientStore{instrumentedClients, index}
+	empHandler := NewEmployeeHandler(employees, scales, approvals)
+	skillHandler := NewSkillHandler(skills)
+	projectHandler := NewProjectHandler(projects)
+	clientHandler := NewClientHandler(clients)
+	searchHandler := NewSearchHandler(index)
+	mergeHandler := NewMergeHandler(indexedMergeStore{instrumentMergeStore(mergeStore, m), index})
+	scaleHandler := NewSkillScaleHandler(scales)
+	changeHandler := NewSkillChangeHandler(changes, scales)
+	certHandler := NewCertificationHandler(instrumentCertificationStore(certStore, m))
+	reports := instrumentReportStore(reportStore, m)
+	reportHandler := NewReportHandler(reports)
+	graphHandler := NewGraphHandler(reports)
+	cvTemplates, err := loadCVTemplates(appCfg.CVTemplateDir)
+	if err != nil {
+		fatal("loading CV templates", err)
+	}
+	cvHandler := NewCVHandler(employees, clients, cvTemplates)
+	profileHandler := NewProfileHandler(employees, clients, skills, scales, approvals)
+	requirements := instrumentRequirementStore(requirementStore, m)
+	requirementHandler := NewRequirementHandler(requirements, scales, reports)
+	teamHandler := NewTeamHandler(requirements, reports, skills)
+	departmentHandler := NewDepartmentHandler(instrumentDepartmentStore(departmentStore, m))
+	categoryHandler := NewSkillCategoryHandler(instrumentSkillCategoryStore(categoryStore, m), skills)
+	healthHandler := NewHealthHandler(dbs, empStore.db)
 	//Configure endpoints
-	router := gin.Default()
+	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
+		logger.Debug("route registered", "method", httpMethod, "path", absolutePath, "handler", handlerName)
+	}
+	tokens := tokenPrincipals{}
+	if appCfg.AuthTokenFile != "" {
+		if tokens, err = loadTokens(appCfg.AuthTokenFile); err != nil {
+			fatal("loading bearer tokens", err)
+		}
+	}
+	router := gin.New()
+	router.Use(otelgin.Middleware(serviceName), requestLogger(logger), m.middleware(), gin.Recovery(),
+		maxBodySize(appCfg.MaxBodyBytes), authenticate(tokens))
 	router.Routes()
+	// operational endpoints, reachable without credentials
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+	router.GET("/healthz", healthHandler.healthz)
+	router.GET("/readyz", healthHandler.readyz)
+	router.GET("/version", healthHandler.getVersion)
+
 	router.GET("/v1/employees", empHandler.getEmployees)
+	router.GET("/v1/employees/search", empHandler.searchEmployees)
 	router.GET("/v1/employees/:id", empHandler.getEmployee)
 	router.POST("/v1/employees", empHandler.addEmployee)
 	router.PUT("/v1/employees/:id", empHandler.updateEmployee)
+	router.PATCH("/v1/employees/:id", empHandler.patchEmployee)
 	router.DELETE("/v1/employees/:id", empHandler.deleteEmployee)
 
+	router.GET("/v1/employees/:id/reports", empHandler.getReports)
+	router.GET("/v1/employees/:id/subtree", empHandler.getSubtree)
+	router.GET("/v1/employees/:id/chain", empHandler.getChain)
+	router.GET("/v1/employees/:id/cv", cvHandler.getCV)
+	router.POST("/v1/employees/cvs", cvHandler.getCVs)
+	router.GET("/v1/employees/:id/resume", profileHandler.getResume)
+	router.PUT("/v1/employees/:id/resume", profileHandler.importResume)
+	router.GET("/v1/employees/:id/europass", profileHandler.getEuropass)
+	router.GET("/v1/employees/:id/skills/:skillId/history", empHandler.getSkillHistory)
+	router.PATCH("/v1/employees/:id/skills/:skillId", empHandler.patchSkill)
+
 	router.GET("/v1/fullEmployees", empHandler.getFullEmployees)
 	router.GET("/v1/fullEmployees/:id", empHandler.getFullEmployee)
 	//special endpoints
@@ -68,25 +227,124 @@ func main() {
 	router.POST("/v1/projects/employees/:id", empHandler.addProject)
 	router.DELETE("/v1/projects/employees/:id", empHandler.deleteProject)
 	router.PUT("/v1/projects/employees/:id", empHandler.updateProject)
+	router.GET("/v1/projects/employees/:id", empHandler.getProjects)
+	router.PUT("/v1/projects/employees/:id/assignments/:assignmentId", empHandler.updateAssignment)
+	router.PATCH("/v1/projects/employees/:id/assignments/:assignmentId", empHandler.patchAssignment)
+	router.DELETE("/v1/projects/employees/:id/assignments/:assignmentId", empHandler.deleteAssignment)
 
 	router.GET("/v1/projects", projectHandler.getProjects)
 	router.GET("/v1/projects/:id", projectHandler.getProject)
 	router.POST("/v1/projects", projectHandler.addProject)
 	router.PUT("v1/projects/:id", projectHandler.updateProject)
+	router.PATCH("/v1/projects/:id", projectHandler.patchProject)
 	router.DELETE("v1/projects/:id", projectHandler.deleteProject)
+	router.POST("/v1/projects/:id/rekey", projectHandler.rekeyProject)
+	router.GET("/v1/projects/:id/requirements", requirementHandler.getRequirements)
+	router.POST("/v1/projects/:id/requirements", requirementHandler.addRequirement)
+	router.GET("/v1/projects/:id/requirements/:requirementId", requirementHandler.getRequirement)
+	router.PUT("/v1/projects/:id/requirements/:requirementId", requirementHandler.updateRequirement)
+	router.DELETE("/v1/projects/:id/requirements/:requirementId", requirementHandler.deleteRequirement)
+	router.GET("/v1/projects/:id/coverage", requirementHandler.getCoverage)
+	router.GET("/v1/projects/:id/candidates", requirementHandler.getCandidates)
+	router.POST("/v1/teams/proposals", teamHandler.proposeTeams)
+
+	router.GET("/v1/departments", departmentHandler.getDepartments)
+	router.GET("/v1/departments/:id", departmentHandler.getDepartment)
+	router.POST("/v1/departments", departmentHandler.addDepartment)
+	router.PUT("/v1/departments/:id", departmentHandler.updateDepartment)
+	router.DELETE("/v1/departments/:id", departmentHandler.deleteDepartment)
+	router.GET("/v1/departments/:id/employees", departmentHandler.getMembers)
 
 	router.GET("/v1/clients", clientHandler.getClients)
 	router.GET("/v1/clients/:id", clientHandler.getClient)
 	router.POST("/v1/clients", clientHandler.addClient)
 	router.PUT("v1/clients/:id", clientHandler.updateClient)
+	router.PATCH("/v1/clients/:id", clientHandler.patchClient)
 	router.DELETE("v1/clients/:id", clientHandler.deleteClient)
+	router.POST("/v1/clients/:id/rekey", clientHandler.rekeyClient)
 
 	router.GET("/v1/skills", skillHandler.getSkills)
+	router.GET("/v1/skills/suggest", skillHandler.getSuggestions)
 	router.GET("/v1/skills/:id", skillHandler.getSkill)
 	router.POST("/v1/skills", skillHandler.addSkill)
 	router.PUT("/v1/skills/:id", skillHandler.updateSkill)
+	router.PATCH("/v1/skills/:id", skillHandler.patchSkill)
 	router.DELETE("/v1/skills/:id", skillHandler.deleteSkill)
+	router.POST("/v1/skills/:id/rekey", skillHandler.rekeySkill)
+	router.POST("/v1/skills/:id/aliases", skillHandler.addAlias)
+	router.DELETE("/v1/skills/:id/aliases/:alias", skillHandler.deleteAlias)
+	router.GET("/v1/skills/:id/owners", changeHandler.getOwners)
+	router.POST("/v1/skills/:id/owners", changeHandler.addOwner)
+	router.DELETE("/v1/skills/:id/owners/:employeeId", changeHandler.deleteOwner)
 
-	router.Run("localhost:9090")
+	router.GET("/v1/skillChangeRequests", changeHandler.getChangeRequests)
+	router.GET("/v1/skillChangeRequests/:id", changeHandler.getChangeRequest)
+	router.POST("/v1/skillChangeRequests/:id/approve", changeHandler.approveChange)
+	router.POST("/v1/skillChangeRequests/:id/reject", changeHandler.rejectChange)
+
+	router.GET("/v1/skillCategories", categoryHandler.getCategoryTree)
+	router.GET("/v1/skillCategories/:id", categoryHandler.getCategory)
+	router.POST("/v1/skillCategories", categoryHandler.addCategory)
+	router.PUT("/v1/skillCategories/:id", categoryHandler.updateCategory)
+	router.DELETE("/v1/skillCategories/:id", categoryHandler.deleteCategory)
+
+	router.GET("/v1/skillScales", scaleHandler.getScales)
+	router.GET("/v1/skillScales/:class", scaleHandler.getScale)
+	router.PUT("/v1/skillScales/:class", scaleHandler.putScale)
+	router.DELETE("/v1/skillScales/:class", scaleHandler.deleteScale)
+
+	router.GET("/v1/certifications", certHandler.getCertifications)
+	router.GET("/v1/certifications/expiring", certHandler.getExpiring)
+	router.GET("/v1/certifications/headcount", certHandler.getHeadcount)
+	router.GET("/v1/certifications/:id", certHandler.getCertification)
+	router.POST("/v1/certifications", certHandler.addCertification)
+	router.PUT("/v1/certifications/:id", certHandler.updateCertification)
+	router.DELETE("/v1/certifications/:id", certHandler.deleteCertification)
+
+	router.GET("/v1/reports/utilization", reportHandler.getUtilization)
+	router.GET("/v1/reports/bench", reportHandler.getBench)
+	router.GET("/v1/reports/focusAreas", reportHandler.getFocusAreaUtilization)
+	router.GET("/v1/graphs/org", graphHandler.exportOrgChart)
+	router.GET("/v1/graphs/staffing", graphHandler.exportStaffing)
+	router.GET("/v1/search", searchHandler.search)
+	router.POST("/v1/skills/:id/merge-into/:target", mergeHandler.mergeSkills)
+	router.POST("/v1/employees/:id/merge-into/:target", mergeHandler.mergeEmployees)
+	router.POST("/v1/clients/:id/merge-into/:target", mergeHandler.mergeClients)
+	router.GET("/v1/merges", mergeHandler.getMerges)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	srv := newHTTPServer(appCfg, router)
+	err = runServer(ctx, srv, appCfg.TLSCertFile, appCfg.TLSKeyFile,
+		healthHandler.setShuttingDown, appCfg.ShutdownDelay, appCfg.ShutdownTimeout)
+	if err != nil {
+		slog.Error("server stopped", "error", err)
+	}
+
+	// nothing uses the stores once the requests are drained
+	for name, closer := range map[string]io.Closer{
+		"employees": empStore, "skills": skillStore, "projects": projectStore, "clients": clientStore,
+		"skillScales": scaleStore, "skillCategories": categoryStore, "skillChanges": changeStore,
+		"certifications": certStore, "reports": reportStore, "requirements": requirementStore,
+		"departments": departmentStore, "merges": mergeStore,
+	} {
+		if cerr := closer.Close(); cerr != nil {
+			slog.Warn("closing store", "store", name, "error", cerr)
+		}
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if serr := shutdownTracing(flushCtx); serr != nil {
+		slog.Warn("flushing traces", "error", serr)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	slog.Info("server stopped")
+}
 
+// fatal logs err with the default logger and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergedManager(t *testing.T) {
+	manager, err := mergedManager(testOrg, 2, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, ptr(0), manager, "the target keeps their manager")
+
+	manager, err = mergedManager(testOrg, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, ptr(0), manager, "a report of the source takes over their manager")
+
+	manager, err = mergedManager(testOrg, 0, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, manager)
+
+	// Ken reports to Grace, who would report to Ken once Ada is merged into him
+	_, err = mergedManager(testOrg, 0, 3)
+	assert.ErrorIs(t, err, errManagerCycle)
+}
+
+func TestResolveStints(t *testing.T) {
+	date := func(month time.Month, day int) *instances.Date {
+		d := instances.NewDate(2024, month, day)
+		return &d
+	}
+	// the target worked on project 5 in spring and from September on, the source from April to October
+	target := []instances.EmployeeProject{
+		{AssignmentId: 1, ProjectId: 5, ProjectRole: "Developer", StartDate: date(3, 1), EndDate: date(5, 31), Allocation: 50},
+		{AssignmentId: 2, ProjectId: 5, ProjectRole: "Lead", StartDate: date(9, 1), Allocation: 40},
+		{AssignmentId: 3, ProjectId: 6, ProjectRole: "Developer", StartDate: date(1, 1), EndDate: date(1, 31), Allocation: 100},
+	}
+	source := []instances.EmployeeProject{
+		{AssignmentId: 4, ProjectId: 5, ProjectRole: "Tester", StartDate: date(4, 1), EndDate: date(10, 31), Allocation: 80},
+		{AssignmentId: 5, ProjectId: 6, ProjectRole: "Tester", StartDate: date(2, 1), EndDate: date(2, 28), Allocation: 100},
+	}
+
+	update, drop := resolveStints(target, source, instances.MergeKeepTarget)
+	assert.Empty(t, update)
+	assert.Equal(t, []int64{4}, drop, "the stint after the target's on project 6 does not overlap")
+
+	update, drop = resolveStints(target, source, instances.MergeKeepSource)
+	assert.Empty(t, update)
+	assert.Equal(t, []int64{1, 2}, drop)
+
+	update, drop = resolveStints(target, source, instances.MergeKeepMax)
+	assert.Equal(t, []instances.EmployeeProject{
+		{AssignmentId: 1, ProjectId: 5, ProjectRole: "Developer", StartDate: date(3, 1), Allocation: 80},
+	}, update, "joined into the earliest stint of the target, open-ended like the latest")
+	assert.ElementsMatch(t, []int64{2, 4}, drop)
+
+	update, drop = resolveStints(target[2:], source[1:], instances.MergeKeepMax)
+	assert.Empty(t, update)
+	assert.Empty(t, drop, "stints one after the other do not overlap")
+}
+
+// mergingStore fails every merge with the configured error
+type mergingStore struct {
+	mergeStore
+	err error
+}
+
+func (s mergingStore) MergeSkills(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	if s.err != nil {
+		return instances.Merge{}, s.err
+	}
+	return instances.Merge{Entity: instances.MergeSkill, SourceId: source, TargetId: target, Policy: req.Policy}, nil
+}
+
+func TestMergeSkillsStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		path string
+		body string
+		err  error
+		want int
+	}{
+		{name: "merged", path: "/v1/skills/4/merge-into/9", body: `{"merged_by": "ada", "policy": "keep_source"}`, want: http.StatusOK},
+		{name: "missing author", path: "/v1/skills/4/merge-into/9", body: `{}`, want: http.StatusBadRequest},
+		{name: "unknown policy", path: "/v1/skills/4/merge-into/9", body: `{"merged_by": "ada", "policy": "keep_all"}`, want: http.StatusBadRequest},
+		{name: "into itself", path: "/v1/skills/4/merge-into/4", body: `{"merged_by": "ada"}`, want: http.StatusBadRequest},
+		{name: "unknown skill", path: "/v1/skills/4/merge-into/9", body: `{"merged_by": "ada"}`, err: sql.ErrNoRows, want: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewMergeHandler(mergingStore{err: tc.err})
+			eng := gin.New()
+			eng.POST("/v1/skills/:id/merge-into/:target", h.mergeSkills)
+
+			req, _ := http.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
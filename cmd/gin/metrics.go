@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+const metricsNamespace = "esm"
+
+// metrics groups the application's prometheus collectors. They are registered on an explicit registry instead of
+// the global one, so tests can create as many as they need.
+type metrics struct {
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	storeDuration *prometheus.HistogramVec
+	storeErrors   *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests handled, by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		storeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "store",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of store method calls, by store and method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"store", "method"}),
+		storeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "store",
+			Name:      "errors_total",
+			Help:      "Number of failed store method calls, by store and method. Lookups of missing rows are not counted.",
+		}, []string{"store", "method"}),
+	}
+	reg.MustRegister(m.httpRequests, m.httpDuration, m.storeDuration, m.storeErrors)
+	return m
+}
+
+// middleware records the request counter and latency histogram. Routes are labelled by their template
+// (e.g. /v1/employees/:id) to keep the label cardinality bounded.
+func (m *metrics) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// observeStore records a single store call. It is meant to be deferred with a pointer to the named error result.
+func (m *metrics) observeStore(store, method string, start time.Time, err *error) {
+	m.storeDuration.WithLabelValues(store, method).Observe(time.Since(start).Seconds())
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+		m.storeErrors.WithLabelValues(store, method).Inc()
+	}
+}
+
+// registerDBStats exposes the sql.DBStats of a connection pool, labelled by the store using it
+func registerDBStats(reg prometheus.Registerer, store string, db *sql.DB) {
+	reg.MustRegister(collectors.NewDBStatsCollector(db, store))
+}
+
+// businessCollector exposes gauges about the data itself. The values are queried on every scrape,
+// so they are always current and there is no background job to keep them in sync.
+type businessCollector struct {
+	db      *sql.DB
+	timeout time.Duration
+
+	employees      *prometheus.Desc
+	projects       *prometheus.Desc
+	clients        *prometheus.Desc
+	skillsPerClass *prometheus.Desc
+	assignedSkills *prometheus.Desc
+}
+
+func newBusinessCollector(db *sql.DB) *businessCollector {
+	return &businessCollector{
+		db:      db,
+		timeout: 5 * time.Second,
+		employees: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "employees"),
+			"Number of employees.", nil, nil),
+		projects: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "projects"),
+			"Number of projects.", nil, nil),
+		clients: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "clients"),
+			"Number of clients.", nil, nil),
+		skillsPerClass: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "skills"),
+			"Number of skills, by skill class.", []string{"skill_class"}, nil),
+		assignedSkills: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "employee_skills"),
+			"Number of skills assigned to employees, by skill class.", []string{"skill_class"}, nil),
+	}
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.employees
+	ch <- c.projects
+	ch <- c.clients
+	ch <- c.skillsPerClass
+	ch <- c.assignedSkills
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	c.collectCount(ctx, ch, c.employees, "SELECT COUNT(*) FROM Employees")
+	c.collectCount(ctx, ch, c.projects, "SELECT COUNT(*) FROM Projects")
+	c.collectCount(ctx, ch, c.clients, "SELECT COUNT(*) FROM Clients")
+	c.collectByClass(ctx, ch, c.skillsPerClass, "SELECT skill_class, COUNT(*) FROM Skills GROUP BY skill_class")
+	c.collectByClass(ctx, ch, c.assignedSkills, "SELECT s.skill_class, COUNT(*) FROM EmployeeSkills AS e "+
+		"INNER JOIN Skills AS s ON e.skill_id = s.skill_id GROUP BY s.skill_class")
+}
+
+func (c *businessCollector) collectCount(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc, query string) {
+	var count float64
+	if err := c.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		slog.Warn("collecting business metric", "query", query, "error", err)
+		ch <- prometheus.NewInvalidMetric(desc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, count)
+}
+
+func (c *businessCollector) collectByClass(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc, query string) {
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		slog.Warn("collecting business metric", "query", query, "error", err)
+		ch <- prometheus.NewInvalidMetric(desc, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var class sql.NullString
+		var count float64
+		if err := rows.Scan(&class, &count); err != nil {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, count, class.String)
+	}
+	if err := rows.Err(); err != nil {
+		ch <- prometheus.NewInvalidMetric(desc, err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// failingSkillStore returns the configured error from every call
+type failingSkillStore struct {
+	err error
+}
+
+func (s failingSkillStore) Add(ctx context.Context, skill instances.Skill) (int, error) {
+	return -1, s.err
+}
+func (s failingSkillStore) Get(ctx context.Context, skillId int64) (instances.Skill, error) {
+	return instances.Skill{}, s.err
+}
+func (s failingSkillStore) List(ctx context.Context) ([]instances.Skill, error) { return nil, s.err }
+func (s failingSkillStore) Update(ctx context.Context, currId int64, skill instances.Skill) (int64, error) {
+	return -1, s.err
+}
+func (s failingSkillStore) Patch(ctx context.Context, skillId int64, apply func(instances.Skill) (instances.Skill, error)) (instances.Skill, error) {
+	return instances.Skill{}, s.err
+}
+func (s failingSkillStore) Delete(ctx context.Context, skillId int64) (int64, error) {
+	return -1, s.err
+}
+
+func (s failingSkillStore) AddAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	return -1, s.err
+}
+
+func (s failingSkillStore) DeleteAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	return -1, s.err
+}
+
+func (s failingSkillStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	return instances.Rekey{}, s.err
+}
+
+func TestHTTPMetricsUseRouteTemplate(t *testing.T) {
+	m := newMetrics(prometheus.NewRegistry())
+	eng := gin.New()
+	eng.Use(m.middleware())
+	eng.GET("/skills/:id", func(context *gin.Context) { context.Status(http.StatusOK) })
+
+	for _, path := range []string{"/skills/1", "/skills/2", "/nothing"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		eng.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/skills/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "unmatched", "404")))
+}
+
+func TestInstrumentedStoreCountsErrors(t *testing.T) {
+	m := newMetrics(prometheus.NewRegistry())
+
+	store := instrumentSkillStore(failingSkillStore{err: errors.New("connection reset")}, m)
+	_, _ = store.List(context.Background())
+	_, _ = store.List(context.Background())
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.storeErrors.WithLabelValues("skills", "List")))
+
+	// missing rows are an expected outcome and are not counted
+	store = instrumentSkillStore(failingSkillStore{err: sql.ErrNoRows}, m)
+	_, _ = store.Get(context.Background(), 42)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.storeErrors.WithLabelValues("skills", "Get")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.storeDuration, "esm_store_query_duration_seconds"))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"sort"
+)
+
+// Like the skill taxonomy, the reporting lines are loaded as a whole and walked in memory. Every walk is bounded
+// by the number of employees in case the stored data already contains a cycle. Changes of a manager only load the
+// lines above the employees they change, which is all a cycle check walks.
+
+// reportsByManager indexes employees by the id of their manager
+func reportsByManager(employees []instances.Employee) map[int64][]instances.Employee {
+	reports := make(map[int64][]instances.Employee)
+	for _, emp := range employees {
+		if emp.ManagerId != nil {
+			reports[*emp.ManagerId] = append(reports[*emp.ManagerId], emp)
+		}
+	}
+	for _, list := range reports {
+		sort.Slice(list, func(i, j int) bool { return list[i].EmployeeId < list[j].EmployeeId })
+	}
+	return reports
+}
+
+// reportingLines returns the employees ids and everyone above them, read level by level with read
+func reportingLines(ids []int64, read func(ids []int64) ([]instances.Employee, error)) ([]instances.Employee, error) {
+	seen := make(map[int64]bool, len(ids))
+	var next []int64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			next = append(next, id)
+		}
+	}
+	var employees []instances.Employee
+	for len(next) > 0 {
+		level, err := read(next)
+		if err != nil {
+			return nil, err
+		}
+		next = nil
+		for _, emp := range level {
+			employees = append(employees, emp)
+			if emp.ManagerId != nil && !seen[*emp.ManagerId] {
+				seen[*emp.ManagerId] = true
+				next = append(next, *emp.ManagerId)
+			}
+		}
+	}
+	return employees, nil
+}
+
+// createsReportingCycle reports whether making manager the manager of employee id would make id manage themselves
+func createsReportingCycle(employees []instances.Employee, id int64, manager *int64) bool {
+	if manager == nil {
+		return false
+	}
+	managers := make(map[int64]*int64, len(employees))
+	for _, emp := range employees {
+		managers[emp.EmployeeId] = emp.ManagerId
+	}
+	current := manager
+	for steps := 0; current != nil && steps <= len(employees); steps++ {
+		if *current == id {
+			return true
+		}
+		current = managers[*current]
+	}
+	return false
+}
+
+// subtreeIds returns id and the ids of everyone reporting to id directly or indirectly
+func subtreeIds(employees []instances.Employee, id int64) []int64 {
+	reports := reportsByManager(employees)
+	ids := []int64{id}
+	seen := map[int64]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, emp := range reports[ids[i]] {
+			if !seen[emp.EmployeeId] {
+				seen[emp.EmployeeId] = true
+				ids = append(ids, emp.EmployeeId)
+			}
+		}
+	}
+	return ids
+}
+
+// managementChain returns the managers of employee id, the direct manager first and the top of the organisation last
+func managementChain(employees []instances.Employee, id int64) []instances.Employee {
+	byId := make(map[int64]instances.Employee, len(employees))
+	for _, emp := range employees {
+		byId[emp.EmployeeId] = emp
+	}
+	chain := []instances.Employee{}
+	current := byId[id].ManagerId
+	for steps := 0; current != nil && steps < len(employees); steps++ {
+		manager, ok := byId[*current]
+		if !ok || manager.EmployeeId == id {
+			break
+		}
+		chain = append(chain, manager)
+		current = manager.ManagerId
+	}
+	return chain
+}
+
+// buildOrgTree nests everyone reporting to root below it
+func buildOrgTree(employees []instances.Employee, root instances.Employee) instances.OrgNode {
+	reports := reportsByManager(employees)
+	var build func(emp instances.Employee, depth int) instances.OrgNode
+	build = func(emp instances.Employee, depth int) instances.OrgNode {
+		node := instances.OrgNode{Employee: emp}
+		if depth > len(employees) {
+			return node
+		}
+		for _, report := range reports[emp.EmployeeId] {
+			node.Reports = append(node.Reports, build(report, depth+1))
+		}
+		return node
+	}
+	return build(root, 0)
+}
+
+// orgScope returns the ids of the employees an org filter selects, nil if it selects everyone
+func orgScope(employees []instances.Employee, filter instances.OrgFilter) map[int64]bool {
+	if !filter.IsSet() {
+		return nil
+	}
+	scope := make(map[int64]bool)
+	if filter.Manager != nil {
+		for _, id := range subtreeIds(employees, *filter.Manager) {
+			scope[id] = true
+		}
+	} else {
+		for _, emp := range employees {
+			scope[emp.EmployeeId] = true
+		}
+	}
+	if filter.Department != 0 {
+		for _, emp := range employees {
+			if emp.DepartmentId == nil || *emp.DepartmentId != filter.Department {
+				delete(scope, emp.EmployeeId)
+			}
+		}
+	}
+	return scope
+}
+
+// mergedManager returns whom the employee target reports to once the employee source is merged into them: their
+// own manager, or the manager of source if they reported to source. Merging fails with errManagerCycle if the reports
+// of source would end up above target. employees holds at least the reporting lines of source and target.
+func mergedManager(employees []instances.Employee, source int64, target int64) (*int64, error) {
+	managers := make(map[int64]*int64, len(employees))
+	for _, emp := range employees {
+		managers[emp.EmployeeId] = emp.ManagerId
+	}
+	manager := managers[target]
+	if manager != nil && *manager == source {
+		manager = managers[source]
+	}
+	if manager != nil && *manager == target {
+		manager = nil
+	}
+	merged := make([]instances.Employee, 0, len(employees))
+	for _, emp := range employees {
+		switch {
+		case emp.EmployeeId == source:
+			continue
+		case emp.EmployeeId == target:
+			emp.ManagerId = manager
+		case emp.ManagerId != nil && *emp.ManagerId == source:
+			emp.ManagerId = &target
+		}
+		merged = append(merged, emp)
+	}
+	if createsReportingCycle(merged, target, manager) {
+		return nil, errManagerCycle
+	}
+	return manager, nil
+}
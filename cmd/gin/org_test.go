@@ -0,0 +1,94 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// 0 manages 1 and 2, 1 manages 3; 0, 1 and 3 are in department 10, 2 in department 20
+var testOrg = []instances.Employee{
+	{EmployeeId: 0, Name: "Ada", DepartmentId: ptr(10)},
+	{EmployeeId: 1, Name: "Grace", ManagerId: ptr(0), DepartmentId: ptr(10)},
+	{EmployeeId: 2, Name: "Linus", ManagerId: ptr(0), DepartmentId: ptr(20)},
+	{EmployeeId: 3, Name: "Ken", ManagerId: ptr(1), DepartmentId: ptr(10)},
+}
+
+func TestCreatesReportingCycle(t *testing.T) {
+	assert.True(t, createsReportingCycle(testOrg, 0, ptr(3)), "reporting to an indirect report")
+	assert.True(t, createsReportingCycle(testOrg, 2, ptr(2)), "reporting to themselves")
+	assert.False(t, createsReportingCycle(testOrg, 3, ptr(2)))
+	assert.False(t, createsReportingCycle(testOrg, 1, nil))
+}
+
+func TestReportingLines(t *testing.T) {
+	var reads [][]int64
+	read := func(ids []int64) ([]instances.Employee, error) {
+		reads = append(reads, ids)
+		var level []instances.Employee
+		for _, emp := range testOrg {
+			for _, id := range ids {
+				if emp.EmployeeId == id {
+					level = append(level, emp)
+				}
+			}
+		}
+		return level, nil
+	}
+	lines, err := reportingLines([]int64{3, 2, 3}, read)
+	if assert.NoError(t, err) {
+		assert.ElementsMatch(t, []instances.Employee{testOrg[0], testOrg[1], testOrg[2], testOrg[3]}, lines)
+		if assert.Len(t, reads, 2) {
+			assert.Equal(t, []int64{3, 2}, reads[0], "each employee is read once")
+			assert.ElementsMatch(t, []int64{1, 0}, reads[1])
+		}
+		assert.True(t, createsReportingCycle(lines, 1, ptr(3)))
+	}
+
+	reads = nil
+	lines, err = reportingLines([]int64{1}, read)
+	if assert.NoError(t, err) {
+		assert.Len(t, lines, 2, "the reports of 1 are not part of their line")
+		assert.Len(t, reads, 2)
+	}
+}
+
+func TestSubtreeIds(t *testing.T) {
+	assert.ElementsMatch(t, []int64{0, 1, 2, 3}, subtreeIds(testOrg, 0))
+	assert.ElementsMatch(t, []int64{1, 3}, subtreeIds(testOrg, 1))
+	assert.Equal(t, []int64{2}, subtreeIds(testOrg, 2))
+}
+
+func TestManagementChain(t *testing.T) {
+	chain := managementChain(testOrg, 3)
+	if assert.Len(t, chain, 2) {
+		assert.Equal(t, int64(1), chain[0].EmployeeId, "direct manager first")
+		assert.Equal(t, int64(0), chain[1].EmployeeId)
+	}
+	assert.Empty(t, managementChain(testOrg, 0))
+
+	// stored data that already contains a cycle must not loop forever
+	cyclic := []instances.Employee{
+		{EmployeeId: 1, ManagerId: ptr(2)},
+		{EmployeeId: 2, ManagerId: ptr(1)},
+	}
+	assert.Len(t, managementChain(cyclic, 1), 1)
+}
+
+func TestBuildOrgTree(t *testing.T) {
+	tree := buildOrgTree(testOrg, testOrg[0])
+	assert.Equal(t, int64(0), tree.Employee.EmployeeId)
+	if assert.Len(t, tree.Reports, 2) {
+		assert.Equal(t, int64(1), tree.Reports[0].Employee.EmployeeId)
+		assert.Equal(t, int64(3), tree.Reports[0].Reports[0].Employee.EmployeeId)
+		assert.Empty(t, tree.Reports[1].Reports)
+	}
+}
+
+func TestOrgScope(t *testing.T) {
+	assert.Nil(t, orgScope(testOrg, instances.OrgFilter{}))
+	assert.Equal(t, map[int64]bool{1: true, 3: true}, orgScope(testOrg, instances.OrgFilter{Manager: ptr(1)}))
+	assert.Equal(t, map[int64]bool{2: true}, orgScope(testOrg, instances.OrgFilter{Department: 20}))
+	assert.Equal(t, map[int64]bool{0: true, 1: true, 3: true},
+		orgScope(testOrg, instances.OrgFilter{Manager: ptr(0), Department: 10}))
+}
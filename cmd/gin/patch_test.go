@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/patch"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// patchingEmployeeStore holds the employee 1 with the skill 7 and the stint 3, and counts the records it writes
+type patchingEmployeeStore struct {
+	employeeStore
+	writes *int
+}
+
+func (s patchingEmployeeStore) Patch(ctx context.Context, employeeId int64, apply func(instances.Employee) (instances.Employee, error)) (instances.Employee, error) {
+	if employeeId != 1 {
+		return instances.Employee{}, sql.ErrNoRows
+	}
+	emp, err := apply(instances.Employee{EmployeeId: 1, Name: "Grace", Lastname: "Hopper", FocusArea: "Compilers",
+		ManagerId: ptr(0)})
+	if err != nil {
+		return instances.Employee{}, err
+	}
+	*s.writes++
+	return emp, nil
+}
+
+func (s patchingEmployeeStore) PatchSkill(ctx context.Context, employeeId int64, skillId int64, apply func(instances.EmployeeSkill) (instances.EmployeeSkill, error)) (instances.EmployeeSkill, error) {
+	if employeeId != 1 || skillId != 7 {
+		return instances.EmployeeSkill{}, sql.ErrNoRows
+	}
+	empSkill, err := apply(instances.EmployeeSkill{SkillId: 7, SkillLevel: 2})
+	if err != nil {
+		return instances.EmployeeSkill{}, err
+	}
+	*s.writes++
+	return empSkill, nil
+}
+
+func (s patchingEmployeeStore) PatchAssignment(ctx context.Context, employeeId int64, assignmentId int64, apply func(instances.EmployeeProject) (instances.EmployeeProject, error)) (instances.EmployeeProject, error) {
+	if employeeId != 1 || assignmentId != 3 {
+		return instances.EmployeeProject{}, sql.ErrNoRows
+	}
+	start, end := instances.NewDate(2024, 1, 1), instances.NewDate(2024, 6, 30)
+	ep, err := apply(instances.EmployeeProject{AssignmentId: 3, ProjectId: 5, ProjectRole: "Developer",
+		StartDate: &start, EndDate: &end, Allocation: 50})
+	if err != nil {
+		return instances.EmployeeProject{}, err
+	}
+	*s.writes++
+	return ep, nil
+}
+
+// defaultScaleStore rates every known skill on the default scale, skills above 100 are unknown
+type defaultScaleStore struct {
+	skillScaleStore
+}
+
+func (s defaultScaleStore) ForSkill(ctx context.Context, skillId int64) (instances.SkillScale, error) {
+	if skillId > 100 {
+		return instances.SkillScale{}, sql.ErrNoRows
+	}
+	return instances.DefaultSkillScale, nil
+}
+
+// requestingChangeStore accepts every change request, the principal ada is the employee 2
+type requestingChangeStore struct {
+	skillChangeStore
+}
+
+func (s requestingChangeStore) Caller(ctx context.Context, principal string) (int64, error) {
+	if principal == "ada" {
+		return 2, nil
+	}
+	return -1, sql.ErrNoRows
+}
+
+func (s requestingChangeStore) Request(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error) {
+	return 12, nil
+}
+
+type patchCase struct {
+	name        string
+	path        string
+	contentType string
+	body        string
+	want        int
+	contains    string
+}
+
+func servePatches(t *testing.T, eng *gin.Engine, writes *int, cases []patchCase) {
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			*writes = 0
+			req, _ := http.NewRequest("PATCH", tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tc.contains)
+			if tc.want == http.StatusOK {
+				assert.Equal(t, 1, *writes)
+			} else {
+				assert.Zero(t, *writes)
+			}
+		})
+	}
+}
+
+func TestPatchEmployee(t *testing.T) {
+	writes := 0
+	h := NewEmployeeHandler(patchingEmployeeStore{writes: &writes}, defaultScaleStore{}, nil)
+	eng := gin.New()
+	eng.PATCH("/v1/employees/:id", h.patchEmployee)
+
+	servePatches(t, eng, &writes, []patchCase{
+		{name: "merge patch", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"focus_area": "COBOL"}`, want: http.StatusOK, contains: `"focus_area": "COBOL"`},
+		{name: "merge patch with charset", path: "/v1/employees/1", contentType: patch.MergePatchType + "; charset=utf-8",
+			body: `{"focus_area": "COBOL"}`, want: http.StatusOK, contains: `"lastname": "Hopper"`},
+		{name: "clear the manager", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"manager_id": null}`, want: http.StatusOK, contains: `"name": "Grace"`},
+		{name: "json patch", path: "/v1/employees/1", contentType: patch.JSONPatchType,
+			body: `[{"op": "test", "path": "/lastname", "value": "Hopper"}, {"op": "replace", "path": "/email", "value": "grace@example.com"}]`,
+			want: http.StatusOK, contains: `"email": "grace@example.com"`},
+		{name: "failed test", path: "/v1/employees/1", contentType: patch.JSONPatchType,
+			body: `[{"op": "test", "path": "/lastname", "value": "Lovelace"}, {"op": "remove", "path": "/manager_id"}]`,
+			want: http.StatusConflict, contains: patch.ErrTestFailed.Error()},
+		{name: "plain json", path: "/v1/employees/1", contentType: "application/json",
+			body: `{"focus_area": "COBOL"}`, want: http.StatusUnsupportedMediaType},
+		{name: "malformed patch", path: "/v1/employees/1", contentType: patch.JSONPatchType,
+			body: `{"op": "remove", "path": "/email"}`, want: http.StatusBadRequest, contains: patch.ErrInvalid.Error()},
+		{name: "unknown field", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"nickname": "Amazing Grace"}`, want: http.StatusBadRequest, contains: "nickname"},
+		{name: "wrong type", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"name": 42}`, want: http.StatusBadRequest},
+		{name: "invalid result", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"name": null}`, want: http.StatusBadRequest, contains: `"rule":"required"`},
+		{name: "changed id", path: "/v1/employees/1", contentType: patch.MergePatchType,
+			body: `{"employee_id": 2}`, want: http.StatusBadRequest, contains: `"rule":"immutable"`},
+		{name: "unknown employee", path: "/v1/employees/2", contentType: patch.MergePatchType,
+			body: `{"focus_area": "COBOL"}`, want: http.StatusNotFound},
+	})
+}
+
+func TestPatchEmployeeSkill(t *testing.T) {
+	writes := 0
+	store := patchingEmployeeStore{writes: &writes}
+	eng := gin.New()
+	eng.Use(func(context *gin.Context) {
+		context.Set(principalKey, "ada")
+	})
+	eng.PATCH("/v1/employees/:id/skills/:skillId", NewEmployeeHandler(store, defaultScaleStore{}, nil).patchSkill)
+	eng.PATCH("/v2/employees/:id/skills/:skillId", NewEmployeeHandler(store, defaultScaleStore{}, requestingChangeStore{}).patchSkill)
+
+	servePatches(t, eng, &writes, []patchCase{
+		{name: "new level", path: "/v1/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4, "note": "led the migration"}`, want: http.StatusOK, contains: `"assessor": "ada"`},
+		{name: "level off the scale", path: "/v1/employees/1/skills/7", contentType: patch.JSONPatchType,
+			body: `[{"op": "replace", "path": "/skill_level", "value": 9}]`, want: http.StatusBadRequest, contains: "skill_level"},
+		{name: "changed skill", path: "/v1/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_id": 8}`, want: http.StatusBadRequest, contains: `"rule":"immutable"`},
+		{name: "skill the employee lacks", path: "/v1/employees/1/skills/8", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4}`, want: http.StatusNotFound},
+		{name: "self-assessed", path: "/v2/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4, "source": "self"}`, want: http.StatusAccepted, contains: `"request_id": 12`},
+		{name: "assessed by the manager", path: "/v2/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4, "source": "manager"}`, want: http.StatusOK},
+		{name: "without a source", path: "/v2/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4}`, want: http.StatusAccepted, contains: `"status": "pending"`},
+	})
+}
+
+// lockingEmployeeStore marks the employee skill locked while the patch is applied
+type lockingEmployeeStore struct {
+	patchingEmployeeStore
+	locked *bool
+}
+
+func (s lockingEmployeeStore) PatchSkill(ctx context.Context, employeeId int64, skillId int64, apply func(instances.EmployeeSkill) (instances.EmployeeSkill, error)) (instances.EmployeeSkill, error) {
+	return s.patchingEmployeeStore.PatchSkill(ctx, employeeId, skillId, func(current instances.EmployeeSkill) (instances.EmployeeSkill, error) {
+		*s.locked = true
+		defer func() { *s.locked = false }()
+		return apply(current)
+	})
+}
+
+// lockCheckingScaleStore and lockCheckingChangeStore count the lookups made while an employee skill is locked
+type lockCheckingScaleStore struct {
+	defaultScaleStore
+	locked  *bool
+	lookups *int
+}
+
+func (s lockCheckingScaleStore) ForSkill(ctx context.Context, skillId int64) (instances.SkillScale, error) {
+	if *s.locked {
+		*s.lookups++
+	}
+	return s.defaultScaleStore.ForSkill(ctx, skillId)
+}
+
+type lockCheckingChangeStore struct {
+	requestingChangeStore
+	locked  *bool
+	lookups *int
+}
+
+func (s lockCheckingChangeStore) Caller(ctx context.Context, principal string) (int64, error) {
+	if *s.locked {
+		*s.lookups++
+	}
+	return s.requestingChangeStore.Caller(ctx, principal)
+}
+
+func TestPatchSkillLooksUpBeforeLocking(t *testing.T) {
+	writes, lookups, locked := 0, 0, false
+	store := lockingEmployeeStore{patchingEmployeeStore: patchingEmployeeStore{writes: &writes}, locked: &locked}
+	h := NewEmployeeHandler(store, lockCheckingScaleStore{locked: &locked, lookups: &lookups},
+		lockCheckingChangeStore{locked: &locked, lookups: &lookups})
+	eng := gin.New()
+	eng.Use(func(context *gin.Context) {
+		context.Set(principalKey, "ada")
+	})
+	eng.PATCH("/v2/employees/:id/skills/:skillId", h.patchSkill)
+
+	servePatches(t, eng, &writes, []patchCase{
+		{name: "assessed by the manager", path: "/v2/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4, "source": "manager"}`, want: http.StatusOK},
+		{name: "self-assessed", path: "/v2/employees/1/skills/7", contentType: patch.MergePatchType,
+			body: `{"skill_level": 4, "source": "self"}`, want: http.StatusAccepted},
+	})
+	assert.Zero(t, lookups)
+}
+
+func TestPatchAssignment(t *testing.T) {
+	writes := 0
+	h := NewEmployeeHandler(patchingEmployeeStore{writes: &writes}, defaultScaleStore{}, nil)
+	eng := gin.New()
+	eng.PATCH("/v1/projects/employees/:id/assignments/:assignmentId", h.patchAssignment)
+
+	servePatches(t, eng, &writes, []patchCase{
+		{name: "open-ended", path: "/v1/projects/employees/1/assignments/3", contentType: patch.MergePatchType,
+			body: `{"end_date": null}`, want: http.StatusOK, contains: `"start_date": "2024-01-01"`},
+		{name: "new role", path: "/v1/projects/employees/1/assignments/3", contentType: patch.JSONPatchType,
+			body: `[{"op": "replace", "path": "/project_role", "value": "Lead"}]`, want: http.StatusOK, contains: `"end_date": "2024-06-30"`},
+		{name: "invalid allocation", path: "/v1/projects/employees/1/assignments/3", contentType: patch.MergePatchType,
+			body: `{"allocation": 150}`, want: http.StatusBadRequest, contains: "allocation"},
+		{name: "changed assignment", path: "/v1/projects/employees/1/assignments/3", contentType: patch.MergePatchType,
+			body: `{"assignment_id": 4}`, want: http.StatusBadRequest, contains: `"rule":"immutable"`},
+		{name: "unknown assignment", path: "/v1/projects/employees/1/assignments/4", contentType: patch.MergePatchType,
+			body: `{"end_date": null}`, want: http.StatusNotFound},
+	})
+}
+
+// patchingClientStore holds the client 2
+type patchingClientStore struct {
+	clientStore
+	writes *int
+}
+
+func (s patchingClientStore) Patch(ctx context.Context, clientId int64, apply func(instances.Client) (instances.Client, error)) (instances.Client, error) {
+	if clientId != 2 {
+		return instances.Client{}, sql.ErrNoRows
+	}
+	client, err := apply(instances.Client{ID: 2, Name: "Initech", Description: "Software"})
+	if err != nil {
+		return instances.Client{}, err
+	}
+	*s.writes++
+	return client, nil
+}
+
+func TestPatchClient(t *testing.T) {
+	writes := 0
+	h := NewClientHandler(patchingClientStore{writes: &writes})
+	eng := gin.New()
+	eng.PATCH("/v1/clients/:id", h.patchClient)
+
+	servePatches(t, eng, &writes, []patchCase{
+		{name: "clear the description", path: "/v1/clients/2", contentType: patch.MergePatchType,
+			body: `{"description": null}`, want: http.StatusOK, contains: `"description": ""`},
+		{name: "copy a value", path: "/v1/clients/2", contentType: patch.JSONPatchType,
+			body: `[{"op": "copy", "from": "/name", "path": "/description"}]`, want: http.StatusOK, contains: `"description": "Initech"`},
+		{name: "changed id", path: "/v1/clients/2", contentType: patch.MergePatchType,
+			body: `{"id": 3}`, want: http.StatusBadRequest, contains: `"rule":"immutable"`},
+		{name: "unknown client", path: "/v1/clients/3", contentType: patch.MergePatchType,
+			body: `{"name": "Initrode"}`, want: http.StatusNotFound},
+	})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rekeyingSkillStore holds the skill 4, counts updates and fails re-keying with the configured error
+type rekeyingSkillStore struct {
+	skillStore
+	err     error
+	updates *int
+}
+
+func (s rekeyingSkillStore) Get(ctx context.Context, skillId int64) (instances.Skill, error) {
+	if skillId != 4 {
+		return instances.Skill{}, sql.ErrNoRows
+	}
+	return instances.Skill{SkillId: 4, SkillClass: "Programming", Skill: "Go"}, nil
+}
+
+func (s rekeyingSkillStore) Update(ctx context.Context, currId int64, skill instances.Skill) (int64, error) {
+	*s.updates++
+	return 1, nil
+}
+
+func (s rekeyingSkillStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	if s.err != nil {
+		return instances.Rekey{}, s.err
+	}
+	return instances.Rekey{OldId: currId, NewId: newId, Moved: map[string]int64{"EmployeeSkills.skill_id": 3}}, nil
+}
+
+func TestUpdateKeepsIds(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "same id", body: `{"skill_id": 4, "skill": "Golang"}`, want: http.StatusOK},
+		{name: "no id", body: `{"skill": "Golang"}`, want: http.StatusOK},
+		{name: "changed id", body: `{"skill_id": 5, "skill": "Golang"}`, want: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updates := 0
+			h := NewSkillHandler(rekeyingSkillStore{updates: &updates})
+			eng := gin.New()
+			eng.PUT("/v1/skills/:id", h.updateSkill)
+
+			req, _ := http.NewRequest("PUT", "/v1/skills/4", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+			if tc.want != http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"rule":"immutable"`)
+				assert.Zero(t, updates)
+			}
+		})
+	}
+}
+
+func TestRekeyStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{name: "re-keyed", body: `{"new_id": 40}`, want: http.StatusOK},
+		{name: "same id", body: `{"new_id": 4}`, want: http.StatusBadRequest},
+		{name: "negative id", body: `{"new_id": -1}`, want: http.StatusBadRequest},
+		{name: "unknown skill", body: `{"new_id": 40}`, err: sql.ErrNoRows, want: http.StatusNotFound},
+		{name: "id taken", body: `{"new_id": 40}`, err: &mysql.MySQLError{Number: 1062}, want: http.StatusConflict},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewSkillHandler(rekeyingSkillStore{err: tc.err})
+			eng := gin.New()
+			eng.POST("/v1/skills/:id/rekey", h.rekeySkill)
+
+			req, _ := http.NewRequest("POST", "/v1/skills/4/rekey", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			eng.ServeHTTP(w, req)
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+// updatingProjectStore holds the project 5 and keeps the last project it updated
+type updatingProjectStore struct {
+	projectStore
+	updated *instances.Project
+}
+
+func (s updatingProjectStore) Get(ctx context.Context, projectId int64) (instances.Project, error) {
+	if projectId != 5 {
+		return instances.Project{}, sql.ErrNoRows
+	}
+	return instances.Project{ProjectId: 5, ClientId: 2, FocusArea: "Cloud"}, nil
+}
+
+func (s updatingProjectStore) Update(ctx context.Context, currId int64, proj instances.Project) (int64, error) {
+	*s.updated = proj
+	return 1, nil
+}
+
+func TestUpdateWithoutIdKeepsPathId(t *testing.T) {
+	for _, body := range []string{`{"focus_area": "Data"}`, `{"project_id": 0, "focus_area": "Data"}`} {
+		var updated instances.Project
+		eng := gin.New()
+		eng.PUT("/v1/projects/:id", NewProjectHandler(updatingProjectStore{updated: &updated}).updateProject)
+
+		req, _ := http.NewRequest("PUT", "/v1/projects/5", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		eng.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, body)
+		assert.Equal(t, instances.Project{ProjectId: 5, ClientId: 2, FocusArea: "Data"}, updated, body)
+	}
+}
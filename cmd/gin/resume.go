@@ -0,0 +1,168 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"strconv"
+	"strings"
+)
+
+// The reasons a resume skill is not imported
+const (
+	unmappedUnknownSkill = "unknown skill"
+	unmappedUnknownLevel = "level not on the skill's scale"
+	// unmappedRequestFailed is a skill whose change request failed after the employee was saved
+	unmappedRequestFailed = "the change request could not be recorded"
+)
+
+// resumeDate writes a date the way JSON Resume does, an empty string for nil
+func resumeDate(d *instances.Date) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+// resumeLevel writes the level of a skill as its label, or as the number if it has none
+func resumeLevel(skill instances.Skill) string {
+	if skill.SkillLevelLabel != "" {
+		return skill.SkillLevelLabel
+	}
+	return strconv.Itoa(skill.SkillLevel)
+}
+
+// resumeFromCV converts the profile prepared for a CV into a JSON Resume, so both leave out secret projects
+func resumeFromCV(cv instances.CV) instances.Resume {
+	resume := instances.Resume{
+		Schema: instances.JSONResumeSchema,
+		Basics: instances.ResumeBasics{
+			Name:  employeeLabel(cv.Employee),
+			Label: cv.Employee.FocusArea,
+			Email: cv.Employee.Email,
+		},
+	}
+	for _, p := range cv.Projects {
+		summary := p.Description
+		if summary == "" {
+			summary = p.FocusArea
+		}
+		resume.Work = append(resume.Work, instances.ResumeWork{
+			Name:      p.Client,
+			Position:  p.Role,
+			StartDate: resumeDate(p.StartDate),
+			EndDate:   resumeDate(p.EndDate),
+			Summary:   summary,
+		})
+	}
+	for _, group := range cv.SkillGroups {
+		for _, skill := range group.Skills {
+			resume.Skills = append(resume.Skills, instances.ResumeSkill{
+				Name:     skill.Skill,
+				Level:    resumeLevel(skill),
+				Keywords: []string{group.SkillClass},
+			})
+		}
+	}
+	for _, c := range cv.Certifications {
+		resume.Certificates = append(resume.Certificates, instances.ResumeCertificate{
+			Name:   c.Name,
+			Date:   c.IssuedOn.String(),
+			Issuer: c.Issuer,
+			URL:    c.Evidence,
+		})
+	}
+	return resume
+}
+
+// splitName splits a full name into first names and the last name, e.g. "Ada King Lovelace" into "Ada King" and
+// "Lovelace"
+func splitName(full string) (string, string) {
+	fields := strings.Fields(full)
+	if len(fields) < 2 {
+		return strings.Join(fields, " "), ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+}
+
+// skillIndex finds skills by name or alias regardless of case. Deprecated skills resolve to their replacement.
+type skillIndex map[string]instances.Skill
+
+func newSkillIndex(skills []instances.Skill) skillIndex {
+	byId := make(map[int64]instances.Skill, len(skills))
+	for _, skill := range skills {
+		byId[int64(skill.SkillId)] = skill
+	}
+	index := make(skillIndex)
+	resolve := func(skill instances.Skill) instances.Skill {
+		if !skill.Deprecated || skill.ReplacedBy == nil {
+			return skill
+		}
+		if replacement, ok := byId[*skill.ReplacedBy]; ok {
+			return replacement
+		}
+		return skill
+	}
+	// names are indexed after aliases, so a skill's own name wins over an alias of another skill
+	for _, skill := range skills {
+		for _, alias := range skill.Aliases {
+			index[strings.ToLower(strings.TrimSpace(alias))] = resolve(skill)
+		}
+	}
+	for _, skill := range skills {
+		index[strings.ToLower(strings.TrimSpace(skill.Skill))] = resolve(skill)
+	}
+	return index
+}
+
+func (idx skillIndex) find(name string) (instances.Skill, bool) {
+	skill, ok := idx[strings.ToLower(strings.TrimSpace(name))]
+	return skill, ok
+}
+
+// parseLevel reads a level given as a number or as a label of scale
+func parseLevel(level string, scale instances.SkillScale) (int64, bool) {
+	level = strings.TrimSpace(level)
+	if n, err := strconv.Atoi(level); err == nil {
+		if _, ok := scale.Find(n); ok {
+			return int64(n), true
+		}
+		return 0, false
+	}
+	for _, l := range scale.Levels {
+		if strings.EqualFold(l.Label, level) {
+			return int64(l.Level), true
+		}
+	}
+	return 0, false
+}
+
+// mapResumeSkills matches the skills of a resume to known skills. scales maps skill classes to their scale, classes
+// without one use the default scale. A skill listed more than once keeps its highest level.
+func mapResumeSkills(skills []instances.ResumeSkill, index skillIndex, scales map[string]instances.SkillScale) ([]instances.EmployeeSkill, []instances.UnmappedSkill) {
+	mapped := []instances.EmployeeSkill{}
+	unmapped := []instances.UnmappedSkill{}
+	position := make(map[int64]int)
+	for _, rs := range skills {
+		skill, ok := index.find(rs.Name)
+		if !ok {
+			unmapped = append(unmapped, instances.UnmappedSkill{Name: rs.Name, Level: rs.Level, Reason: unmappedUnknownSkill})
+			continue
+		}
+		scale, ok := scales[skill.SkillClass]
+		if !ok {
+			scale = instances.DefaultSkillScale
+		}
+		level, ok := parseLevel(rs.Level, scale)
+		if !ok {
+			unmapped = append(unmapped, instances.UnmappedSkill{Name: rs.Name, Level: rs.Level, Reason: unmappedUnknownLevel})
+			continue
+		}
+		id := int64(skill.SkillId)
+		if i, seen := position[id]; seen {
+			mapped[i].SkillLevel = max(mapped[i].SkillLevel, level)
+			continue
+		}
+		position[id] = len(mapped)
+		mapped = append(mapped, instances.EmployeeSkill{SkillId: id, SkillLevel: level, Source: instances.AssessmentSelf})
+	}
+	return mapped, unmapped
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSkillCatalog = []instances.Skill{
+	{SkillId: 1, SkillClass: "Programming", Skill: "Go", Aliases: []string{"golang"}},
+	{SkillId: 2, SkillClass: "Language", Skill: "French"},
+	{SkillId: 3, SkillClass: "Programming", Skill: "JS", Deprecated: true, ReplacedBy: ptr(4)},
+	{SkillId: 4, SkillClass: "Programming", Skill: "JavaScript", Aliases: []string{"Go"}},
+}
+
+var testCEFR = instances.SkillScale{SkillClass: "Language", Name: "CEFR", Levels: []instances.SkillScaleLevel{
+	{Level: 1, Label: "A1"}, {Level: 2, Label: "A2"}, {Level: 3, Label: "B1"}, {Level: 4, Label: "B2"},
+}}
+
+func TestSplitName(t *testing.T) {
+	first, last := splitName(" Ada  King Lovelace ")
+	assert.Equal(t, "Ada King", first)
+	assert.Equal(t, "Lovelace", last)
+	first, last = splitName("Prince")
+	assert.Equal(t, "Prince", first)
+	assert.Empty(t, last)
+}
+
+func TestSkillIndex(t *testing.T) {
+	index := newSkillIndex(testSkillCatalog)
+	skill, ok := index.find("GOLANG")
+	assert.True(t, ok)
+	assert.Equal(t, 1, skill.SkillId)
+	skill, _ = index.find("go")
+	assert.Equal(t, 1, skill.SkillId, "a skill's name wins over an alias of another skill")
+	skill, _ = index.find("js")
+	assert.Equal(t, 4, skill.SkillId, "deprecated skills resolve to their replacement")
+	_, ok = index.find("Rust")
+	assert.False(t, ok)
+}
+
+func TestMapResumeSkills(t *testing.T) {
+	mapped, unmapped := mapResumeSkills([]instances.ResumeSkill{
+		{Name: "golang", Level: "Expert"},
+		{Name: "Go", Level: "3"},
+		{Name: "French", Level: "b2"},
+		{Name: "JavaScript", Level: "Guru"},
+		{Name: "Rust", Level: "4"},
+	}, newSkillIndex(testSkillCatalog), map[string]instances.SkillScale{"Language": testCEFR})
+
+	assert.Equal(t, []instances.EmployeeSkill{
+		{SkillId: 1, SkillLevel: 5, Source: instances.AssessmentSelf},
+		{SkillId: 2, SkillLevel: 4, Source: instances.AssessmentSelf},
+	}, mapped, "a skill listed twice keeps its highest level")
+	assert.Equal(t, []instances.UnmappedSkill{
+		{Name: "JavaScript", Level: "Guru", Reason: unmappedUnknownLevel},
+		{Name: "Rust", Level: "4", Reason: unmappedUnknownSkill},
+	}, unmapped)
+}
+
+func TestResumeFromCV(t *testing.T) {
+	resume := resumeFromCV(buildCV(testProfile, map[int64]string{7: "Acme"}))
+	assert.Equal(t, instances.ResumeBasics{Name: "Ada Lovelace", Email: "ada@example.com"}, resume.Basics)
+	assert.Equal(t, []instances.ResumeWork{{Name: "Acme", Position: "Developer", StartDate: "2023-04-01",
+		Summary: "Cloud"}}, resume.Work, "secret projects are left out")
+	assert.Contains(t, resume.Skills, instances.ResumeSkill{Name: "Ada", Level: "Expert", Keywords: []string{"Programming"}})
+	assert.Contains(t, resume.Skills, instances.ResumeSkill{Name: "French", Level: "4", Keywords: []string{"Languages"}})
+}
+
+func TestEuropass(t *testing.T) {
+	profile := testProfile
+	profile.Skills = append(profile.Skills, instances.Skill{SkillClass: "Language", Skill: "German", SkillLevel: 4,
+		SkillLevelLabel: "B2"})
+	out, err := renderEuropass(europassFromCV(buildCV(profile, map[int64]string{7: "Acme"}),
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.NoError(t, err)
+	doc := string(out)
+	assert.Contains(t, doc, `<SkillsPassport xmlns="http://europass.cedefop.europa.eu/Europass" locale="en">`)
+	assert.Contains(t, doc, "<FirstName>Ada</FirstName>")
+	assert.Contains(t, doc, `<From year="2023" month="--04" day="---01"></From>`)
+	assert.Contains(t, doc, "<Current>true</Current>")
+	assert.Contains(t, doc, "<Name>Acme</Name>")
+	assert.Contains(t, doc, "<Listening>B2</Listening>", "CEFR rated skills are foreign languages")
+	assert.Contains(t, doc, "Programming: Ada (Expert), Go (Intermediate)")
+	assert.False(t, strings.Contains(doc, "Defense"), "secret projects are left out")
+}
+
+// importingEmployeeStore saves every imported profile as a new employee
+type importingEmployeeStore struct {
+	employeeStore
+}
+
+func (s importingEmployeeStore) ImportProfile(ctx context.Context, emp instances.Employee, skills []instances.EmployeeSkill) (bool, error) {
+	return true, nil
+}
+
+type catalogSkillStore struct {
+	skillStore
+}
+
+func (s catalogSkillStore) List(ctx context.Context) ([]instances.Skill, error) {
+	return testSkillCatalog, nil
+}
+
+type cefrScaleStore struct {
+	skillScaleStore
+}
+
+func (s cefrScaleStore) List(ctx context.Context) ([]instances.SkillScale, error) {
+	return []instances.SkillScale{testCEFR}, nil
+}
+
+// failingRequestStore records change requests, except those of the skill 2
+type failingRequestStore struct {
+	skillChangeStore
+}
+
+func (s failingRequestStore) Request(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error) {
+	if empSkill.SkillId == 2 {
+		return -1, errors.New("connection reset")
+	}
+	return 10 + empSkill.SkillId, nil
+}
+
+func TestImportResumeReportsFailedRequests(t *testing.T) {
+	h := NewProfileHandler(importingEmployeeStore{}, nil, catalogSkillStore{}, cefrScaleStore{}, failingRequestStore{})
+	eng := gin.New()
+	eng.PUT("/v1/employees/:id/resume", h.importResume)
+
+	body := `{"basics": {"name": "Ada Lovelace"}, "skills": [{"name": "Go", "level": "4"}, {"name": "French", "level": "B1"}]}`
+	req, _ := http.NewRequest("PUT", "/v1/employees/7/resume", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code, "the employee is saved")
+
+	var result instances.ResumeImport
+	if assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result)) {
+		assert.Equal(t, []int64{11}, result.PendingRequests)
+		assert.Equal(t, []instances.UnmappedSkill{{Name: "French", Level: "3", Reason: unmappedRequestFailed}},
+			result.Unmapped)
+	}
+}
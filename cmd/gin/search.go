@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/search"
+	"strconv"
+	"sync"
+)
+
+// defaultSearchLimit is how many hits a search returns unless it asks for fewer or more
+const defaultSearchLimit = 20
+
+// searchIndex keeps a search.Index in sync with the stores. Writes through the indexed stores mark the kind of entity
+// they change as stale, and the next search reloads the stale kinds from their store before it runs, so a search
+// always sees the writes that completed before it.
+type searchIndex struct {
+	index   *search.Index
+	loaders map[string]func(ctx context.Context) ([]search.Document, error)
+
+	mu    sync.Mutex
+	stale map[string]bool
+}
+
+func newSearchIndex(employees employeeStore, skills skillStore, projects projectStore, clients clientStore) *searchIndex {
+	idx := &searchIndex{
+		index: search.NewIndex(),
+		loaders: map[string]func(ctx context.Context) ([]search.Document, error){
+			instances.SearchEmployee: func(ctx context.Context) ([]search.Document, error) {
+				list, err := employees.List(ctx)
+				return mapDocuments(list, employeeDocument), err
+			},
+			instances.SearchSkill: func(ctx context.Context) ([]search.Document, error) {
+				list, err := skills.List(ctx)
+				return mapDocuments(list, skillDocument), err
+			},
+			instances.SearchProject: func(ctx context.Context) ([]search.Document, error) {
+				list, err := projects.List(ctx)
+				var visible []instances.Project
+				for _, p := range list {
+					// secret projects are not searchable, their words would give them away
+					if !p.IsSecret {
+						visible = append(visible, p)
+					}
+				}
+				return mapDocuments(visible, projectDocument), err
+			},
+			instances.SearchClient: func(ctx context.Context) ([]search.Document, error) {
+				list, err := clients.List(ctx)
+				return mapDocuments(list, clientDocument), err
+			},
+		},
+		stale: make(map[string]bool),
+	}
+	for kind := range idx.loaders {
+		idx.stale[kind] = true
+	}
+	return idx
+}
+
+func mapDocuments[T any](list []T, toDocument func(T) search.Document) []search.Document {
+	docs := make([]search.Document, len(list))
+	for i, v := range list {
+		docs[i] = toDocument(v)
+	}
+	return docs
+}
+
+func employeeDocument(emp instances.Employee) search.Document {
+	return search.Document{Id: emp.EmployeeId, Title: employeeLabel(emp), Fields: []search.Field{
+		{Name: "name", Text: emp.Name, Weight: 3},
+		{Name: "lastname", Text: emp.Lastname, Weight: 3},
+		{Name: "email", Text: emp.Email, Weight: 2},
+		{Name: "focus_area", Text: emp.FocusArea, Weight: 1},
+	}}
+}
+
+func skillDocument(skill instances.Skill) search.Document {
+	fields := []search.Field{
+		{Name: "skill", Text: skill.Skill, Weight: 3},
+		{Name: "skill_class", Text: skill.SkillClass, Weight: 1},
+	}
+	for _, alias := range skill.Aliases {
+		fields = append(fields, search.Field{Name: "aliases", Text: alias, Weight: 2})
+	}
+	return search.Document{Id: int64(skill.SkillId), Title: skill.Skill, Fields: fields}
+}
+
+func projectDocument(p instances.Project) search.Document {
+	title := "Project " + strconv.FormatInt(p.ProjectId, 10)
+	if p.FocusArea != "" {
+		title += " (" + p.FocusArea + ")"
+	}
+	return search.Document{Id: p.ProjectId, Title: title, Fields: []search.Field{
+		{Name: "focus_area", Text: p.FocusArea, Weight: 2},
+		{Name: "description", Text: p.Description, Weight: 1},
+	}}
+}
+
+func clientDocument(c instances.Client) search.Document {
+	return search.Document{Id: c.ID, Title: c.Name, Fields: []search.Field{
+		{Name: "name", Text: c.Name, Weight: 3},
+		{Name: "description", Text: c.Description, Weight: 1},
+	}}
+}
+
+// invalidate marks kind to be reloaded before the next search
+func (idx *searchIndex) invalidate(kind string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.stale[kind] = true
+}
+
+// refresh reloads the stale kinds. A kind stays stale if loading it fails.
+func (idx *searchIndex) refresh(ctx context.Context) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for kind, stale := range idx.stale {
+		if !stale {
+			continue
+		}
+		docs, err := idx.loaders[kind](ctx)
+		if err != nil {
+			return err
+		}
+		idx.index.Replace(kind, docs)
+		idx.stale[kind] = false
+	}
+	return nil
+}
+
+// search runs query against the index after bringing it up to date
+func (idx *searchIndex) search(ctx context.Context, query instances.SearchQuery) ([]instances.SearchHit, error) {
+	if err := idx.refresh(ctx); err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	var kinds []string
+	if query.Kind != "" {
+		kinds = append(kinds, query.Kind)
+	}
+	hits := []instances.SearchHit{}
+	for _, h := range idx.index.Search(query.Q, limit, kinds...) {
+		hits = append(hits, instances.SearchHit{Kind: h.Kind, Id: h.Id, Title: h.Title, Score: h.Score, Fields: h.Fields})
+	}
+	return hits, nil
+}
+
+// indexedEmployeeStore marks employees stale in the search index after each write. The other store decorators below
+// do the same for their entities.
+type indexedEmployeeStore struct {
+	employeeStore
+	index *searchIndex
+}
+
+func (s indexedEmployeeStore) Add(ctx context.Context, emp instances.Employee) (int, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.employeeStore.Add(ctx, emp)
+}
+
+func (s indexedEmployeeStore) Update(ctx context.Context, currId int64, emp instances.Employee) (int64, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.employeeStore.Update(ctx, currId, emp)
+}
+
+func (s indexedEmployeeStore) Patch(ctx context.Context, employeeId int64, apply func(instances.Employee) (instances.Employee, error)) (instances.Employee, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.employeeStore.Patch(ctx, employeeId, apply)
+}
+
+func (s indexedEmployeeStore) Delete(ctx context.Context, employeeId int64) (int64, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.employeeStore.Delete(ctx, employeeId)
+}
+
+func (s indexedEmployeeStore) ImportProfile(ctx context.Context, emp instances.Employee, skills []instances.EmployeeSkill) (bool, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.employeeStore.ImportProfile(ctx, emp, skills)
+}
+
+type indexedSkillStore struct {
+	skillStore
+	index *searchIndex
+}
+
+func (s indexedSkillStore) Add(ctx context.Context, skill instances.Skill) (int, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.Add(ctx, skill)
+}
+
+func (s indexedSkillStore) Update(ctx context.Context, currId int64, skill instances.Skill) (int64, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.Update(ctx, currId, skill)
+}
+
+func (s indexedSkillStore) Patch(ctx context.Context, skillId int64, apply func(instances.Skill) (instances.Skill, error)) (instances.Skill, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.Patch(ctx, skillId, apply)
+}
+
+func (s indexedSkillStore) Delete(ctx context.Context, skillId int64) (int64, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.Delete(ctx, skillId)
+}
+
+func (s indexedSkillStore) AddAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.AddAlias(ctx, skillId, alias)
+}
+
+func (s indexedSkillStore) DeleteAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.DeleteAlias(ctx, skillId, alias)
+}
+
+func (s indexedSkillStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.skillStore.Rekey(ctx, currId, newId)
+}
+
+type indexedProjectStore struct {
+	projectStore
+	index *searchIndex
+}
+
+func (s indexedProjectStore) Add(ctx context.Context, proj instances.Project) (int, error) {
+	defer s.index.invalidate(instances.SearchProject)
+	return s.projectStore.Add(ctx, proj)
+}
+
+func (s indexedProjectStore) Update(ctx context.Context, currId int64, proj instances.Project) (int64, error) {
+	defer s.index.invalidate(instances.SearchProject)
+	return s.projectStore.Update(ctx, currId, proj)
+}
+
+func (s indexedProjectStore) Patch(ctx context.Context, projId int64, apply func(instances.Project) (instances.Project, error)) (instances.Project, error) {
+	defer s.index.invalidate(instances.SearchProject)
+	return s.projectStore.Patch(ctx, projId, apply)
+}
+
+func (s indexedProjectStore) Delete(ctx context.Context, projId int64) (int64, error) {
+	defer s.index.invalidate(instances.SearchProject)
+	return s.projectStore.Delete(ctx, projId)
+}
+
+func (s indexedProjectStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	defer s.index.invalidate(instances.SearchProject)
+	return s.projectStore.Rekey(ctx, currId, newId)
+}
+
+type indexedClientStore struct {
+	clientStore
+	index *searchIndex
+}
+
+func (s indexedClientStore) Add(ctx context.Context, client instances.Client) (int, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.clientStore.Add(ctx, client)
+}
+
+func (s indexedClientStore) Update(ctx context.Context, currId int64, client instances.Client) (int64, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.clientStore.Update(ctx, currId, client)
+}
+
+func (s indexedClientStore) Patch(ctx context.Context, clientId int64, apply func(instances.Client) (instances.Client, error)) (instances.Client, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.clientStore.Patch(ctx, clientId, apply)
+}
+
+func (s indexedClientStore) Delete(ctx context.Context, clientId int64) (int64, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.clientStore.Delete(ctx, clientId)
+}
+
+func (s indexedClientStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.clientStore.Rekey(ctx, currId, newId)
+}
+
+// indexedMergeStore marks the kind of the merged records stale, a merge deletes one of them
+type indexedMergeStore struct {
+	mergeStore
+	index *searchIndex
+}
+
+func (s indexedMergeStore) MergeSkills(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	defer s.index.invalidate(instances.SearchSkill)
+	return s.mergeStore.MergeSkills(ctx, source, target, req)
+}
+
+func (s indexedMergeStore) MergeEmployees(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	defer s.index.invalidate(instances.SearchEmployee)
+	return s.mergeStore.MergeEmployees(ctx, source, target, req)
+}
+
+func (s indexedMergeStore) MergeClients(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	defer s.index.invalidate(instances.SearchClient)
+	return s.mergeStore.MergeClients(ctx, source, target, req)
+}
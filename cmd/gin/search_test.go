@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// listedProjectStore lists projects and counts how often, writes only record the project
+type listedProjectStore struct {
+	projectStore
+	projects []instances.Project
+	lists    int
+}
+
+func (s *listedProjectStore) List(ctx context.Context) ([]instances.Project, error) {
+	s.lists++
+	return s.projects, nil
+}
+
+func (s *listedProjectStore) Add(ctx context.Context, proj instances.Project) (int, error) {
+	s.projects = append(s.projects, proj)
+	return int(proj.ProjectId), nil
+}
+
+type listedEmployeeStore struct{ employeeStore }
+
+func (listedEmployeeStore) List(ctx context.Context) ([]instances.Employee, error) {
+	return []instances.Employee{{EmployeeId: 1, Name: "Ada", Lastname: "Lovelace", Email: "ada@example.com", FocusArea: "Cloud"}}, nil
+}
+
+type listedSkillStore struct{ skillStore }
+
+func (listedSkillStore) List(ctx context.Context) ([]instances.Skill, error) {
+	return []instances.Skill{{SkillId: 4, SkillClass: "Platforms", Skill: "Kubernetes", Aliases: []string{"k8s"}}}, nil
+}
+
+type listedClientStore struct{ clientStore }
+
+func (listedClientStore) List(ctx context.Context) ([]instances.Client, error) {
+	return []instances.Client{{ID: 7, Name: "Acme", Description: "Cloud rockets"}}, nil
+}
+
+func testSearchIndex(projects *listedProjectStore) *searchIndex {
+	return newSearchIndex(listedEmployeeStore{}, listedSkillStore{}, projects, listedClientStore{})
+}
+
+func TestSearchHidesSecretProjects(t *testing.T) {
+	projects := &listedProjectStore{projects: []instances.Project{
+		{ProjectId: 5, FocusArea: "Cloud migration", Description: "Lift and shift"},
+		{ProjectId: 6, FocusArea: "Cloud defense", Description: "Classified", IsSecret: true},
+	}}
+	idx := testSearchIndex(projects)
+
+	hits, err := idx.search(context.Background(), instances.SearchQuery{Q: "clod"})
+	assert.NoError(t, err)
+	var found []string
+	for _, h := range hits {
+		found = append(found, h.Title)
+		assert.NotEqual(t, int64(6), h.Id, "secret project")
+	}
+	assert.ElementsMatch(t, []string{"Ada Lovelace", "Project 5 (Cloud migration)", "Acme"}, found)
+
+	hits, err = idx.search(context.Background(), instances.SearchQuery{Q: "classified", Kind: instances.SearchProject})
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+
+	hits, err = idx.search(context.Background(), instances.SearchQuery{Q: "k8s"})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, instances.SearchHit{Kind: instances.SearchSkill, Id: 4, Title: "Kubernetes", Score: hits[0].Score, Fields: []string{"aliases"}}, hits[0])
+	}
+}
+
+func TestSearchFollowsWrites(t *testing.T) {
+	projects := &listedProjectStore{}
+	idx := testSearchIndex(projects)
+	indexed := indexedProjectStore{projects, idx}
+
+	hits, err := idx.search(context.Background(), instances.SearchQuery{Q: "payments"})
+	assert.NoError(t, err)
+	assert.Empty(t, hits)
+	assert.Equal(t, 1, projects.lists)
+
+	_, err = indexed.Add(context.Background(), instances.Project{ProjectId: 9, FocusArea: "Payments"})
+	assert.NoError(t, err)
+	hits, err = idx.search(context.Background(), instances.SearchQuery{Q: "payments"})
+	assert.NoError(t, err)
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, int64(9), hits[0].Id)
+	}
+	assert.Equal(t, 2, projects.lists, "the write made the projects stale")
+
+	_, err = idx.search(context.Background(), instances.SearchQuery{Q: "payments"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, projects.lists, "nothing reloads without writes")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// newHTTPServer wraps handler in a server with the timeouts and header limit of cfg. Request bodies are limited
+// separately by the maxBodySize middleware, since http.Server has no setting for them.
+func newHTTPServer(cfg config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+}
+
+// maxBodySize rejects requests announcing a body larger than limit bytes and cuts off bodies that turn out
+// to be larger while they are read
+func maxBodySize(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge,
+				gin.H{"error": fmt.Sprintf("request body exceeds %d bytes", limit)})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// runServer serves until ctx is cancelled. It then calls beforeShutdown, waits for delay so load balancers
+// can notice the failing readiness probe, stops accepting connections and waits up to drainTimeout
+// for in-flight requests to complete. TLS is used when both certFile and keyFile are set.
+func runServer(ctx context.Context, srv *http.Server, certFile, keyFile string,
+	beforeShutdown func(), delay, drainTimeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if certFile != "" && keyFile != "" {
+			slog.Info("serving https", "addr", srv.Addr)
+			err = srv.ListenAndServeTLS(certFile, keyFile)
+		} else {
+			slog.Info("serving http", "addr", srv.Addr)
+			err = srv.ListenAndServe()
+		}
+		serveErr <- err
+	}()
+
+	select {
+	case err := <-serveErr:
+		// the server failed before any shutdown was requested, e.g. the address is in use
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down", "delay", delay, "drain_timeout", drainTimeout)
+	beforeShutdown()
+	time.Sleep(delay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("draining requests: %v", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("all requests drained")
+	return nil
+}
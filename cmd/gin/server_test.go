@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	eng := gin.New()
+	eng.Use(maxBodySize(8))
+	eng.POST("/echo", func(context *gin.Context) {
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			context.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		context.String(http.StatusOK, string(body))
+	})
+
+	req, _ := http.NewRequest("POST", "/echo", strings.NewReader("small"))
+	w := httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// rejected up front because of the Content-Length
+	req, _ = http.NewRequest("POST", "/echo", strings.NewReader("far too large"))
+	w = httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// cut off while reading when the length is unknown
+	req, _ = http.NewRequest("POST", "/echo", io.NopCloser(bytes.NewBufferString("far too large")))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	eng.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestRunServerShutsDownOnCancel(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	health := NewHealthHandler(nil, nil)
+	done := make(chan error, 1)
+	go func() {
+		done <- runServer(ctx, srv, "", "", health.setShuttingDown, 0, time.Second)
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+		assert.True(t, health.shuttingDown.Load())
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
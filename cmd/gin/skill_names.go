@@ -0,0 +1,165 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/search"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultSuggestLimit is how many skills a suggestion offers unless it asks for fewer or more
+const defaultSuggestLimit = 10
+
+// languageSuffixes are dropped from the end of skill names when comparing them, so Go lang and golang match Go
+var languageSuffixes = []string{"language", "lang"}
+
+// languageStems are the names a language suffix is dropped from when it is not a word of its own. Other names keep
+// it, Clang and Erlang are not C and Er.
+var languageStems = map[string]bool{"go": true, "rust": true}
+
+// skillKey normalizes a skill name for comparison: lower case letters and digits without spaces or punctuation,
+// without a trailing "lang" or "language" word. + and # are kept, they tell C, C++ and C# apart.
+func skillKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '+' && r != '#'
+	})
+	for _, suffix := range languageSuffixes {
+		if len(words) > 1 && words[len(words)-1] == suffix {
+			return strings.Join(words[:len(words)-1], "")
+		}
+	}
+	key := strings.Join(words, "")
+	for _, suffix := range languageSuffixes {
+		if trimmed, ok := strings.CutSuffix(key, suffix); ok && languageStems[trimmed] {
+			return trimmed
+		}
+	}
+	return key
+}
+
+// similarKeys reports whether two skill keys name the same skill, allowing one typo in keys of five or more
+// characters. Shorter names like Go, Java or Rust differ by a letter from unrelated skills too often.
+func similarKeys(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	if len([]rune(a)) < 5 || len([]rune(b)) < 5 {
+		return false
+	}
+	return search.Distance(a, b, 1) <= 1
+}
+
+// similarSkills finds the skills whose name or alias resembles name, so that a new skill does not duplicate one of
+// them. Deprecated skills are reported as their replacement if they have one.
+func similarSkills(name string, skills []instances.Skill) []instances.SkillSuggestion {
+	key := skillKey(name)
+	if key == "" {
+		return nil
+	}
+	byId := make(map[int64]instances.Skill, len(skills))
+	for _, skill := range skills {
+		byId[int64(skill.SkillId)] = skill
+	}
+	found := []instances.SkillSuggestion{}
+	seen := make(map[int]bool)
+	add := func(skill instances.Skill, alias string) {
+		if skill.Deprecated && skill.ReplacedBy != nil {
+			if replacement, ok := byId[*skill.ReplacedBy]; ok {
+				skill, alias = replacement, ""
+			}
+		}
+		if !seen[skill.SkillId] {
+			seen[skill.SkillId] = true
+			found = append(found, instances.SkillSuggestion{SkillId: skill.SkillId, Skill: skill.Skill,
+				SkillClass: skill.SkillClass, Alias: alias})
+		}
+	}
+	for _, skill := range skills {
+		if similarKeys(key, skillKey(skill.Skill)) {
+			add(skill, "")
+			continue
+		}
+		for _, alias := range skill.Aliases {
+			if similarKeys(key, skillKey(alias)) {
+				add(skill, alias)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// suggestSkills completes prefix to up to limit skills by name or alias, ignoring case, spaces and punctuation.
+// Skills found by name come before those found by alias, and shorter names before longer ones, so the closest
+// completion is first. Deprecated skills are not suggested, their names and aliases lead to their replacement.
+func suggestSkills(prefix string, skills []instances.Skill, limit int) []instances.SkillSuggestion {
+	lower, key := strings.ToLower(strings.TrimSpace(prefix)), skillKey(prefix)
+	matches := func(name string) bool {
+		return strings.HasPrefix(strings.ToLower(name), lower) || key != "" && strings.HasPrefix(skillKey(name), key)
+	}
+	byId := make(map[int64]instances.Skill, len(skills))
+	for _, skill := range skills {
+		byId[int64(skill.SkillId)] = skill
+	}
+	type candidate struct {
+		suggestion instances.SkillSuggestion
+		byAlias    bool
+	}
+	best := make(map[int]candidate)
+	offer := func(skill instances.Skill, alias string) {
+		c := candidate{
+			suggestion: instances.SkillSuggestion{SkillId: skill.SkillId, Skill: skill.Skill, SkillClass: skill.SkillClass,
+				Alias: alias},
+			byAlias: alias != "",
+		}
+		if prev, ok := best[skill.SkillId]; !ok || prev.byAlias && !c.byAlias {
+			best[skill.SkillId] = c
+		}
+	}
+	for _, skill := range skills {
+		target, name := skill, ""
+		if skill.Deprecated {
+			// a deprecated skill is offered as its replacement, its name serving as an alias
+			if skill.ReplacedBy == nil {
+				continue
+			}
+			replacement, ok := byId[*skill.ReplacedBy]
+			if !ok {
+				continue
+			}
+			target, name = replacement, skill.Skill
+		}
+		if matches(skill.Skill) {
+			offer(target, name)
+		}
+		for _, alias := range skill.Aliases {
+			if matches(alias) {
+				offer(target, alias)
+			}
+		}
+	}
+
+	suggestions := make([]candidate, 0, len(best))
+	for _, c := range best {
+		suggestions = append(suggestions, c)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.byAlias != b.byAlias {
+			return !a.byAlias
+		}
+		if len(a.suggestion.Skill) != len(b.suggestion.Skill) {
+			return len(a.suggestion.Skill) < len(b.suggestion.Skill)
+		}
+		return strings.ToLower(a.suggestion.Skill) < strings.ToLower(b.suggestion.Skill)
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	result := make([]instances.SkillSuggestion, len(suggestions))
+	for i, c := range suggestions {
+		result[i] = c.suggestion
+	}
+	return result
+}
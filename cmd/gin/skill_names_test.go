@@ -0,0 +1,57 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testSkillNames = []instances.Skill{
+	{SkillId: 1, SkillClass: "Programming", Skill: "Go", Aliases: []string{"golang"}},
+	{SkillId: 2, SkillClass: "Programming", Skill: "C++"},
+	{SkillId: 3, SkillClass: "Programming", Skill: "C#"},
+	{SkillId: 4, SkillClass: "Platforms", Skill: "Kubernetes", Aliases: []string{"k8s"}},
+	{SkillId: 5, SkillClass: "Platforms", Skill: "Docker Swarm", Deprecated: true, ReplacedBy: ptr(4)},
+	{SkillId: 6, SkillClass: "Programming", Skill: "Gleam"},
+}
+
+func TestSkillKey(t *testing.T) {
+	for _, name := range []string{"golang", "Go lang", "GO", " go-language "} {
+		assert.Equal(t, "go", skillKey(name), name)
+	}
+	assert.Equal(t, "c++", skillKey("C ++"))
+	assert.Equal(t, "lang", skillKey("Lang"), "nothing left to keep")
+	assert.Equal(t, "clang", skillKey("Clang"), "not C")
+	assert.Equal(t, "erlang", skillKey("Erlang"))
+	assert.Equal(t, "er", skillKey("Er lang"), "a separate word is dropped")
+	assert.Equal(t, "rust", skillKey("rustlang"))
+}
+
+func TestSimilarSkills(t *testing.T) {
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 1, Skill: "Go", SkillClass: "Programming"}},
+		similarSkills("Go lang", testSkillNames))
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 4, Skill: "Kubernetes", SkillClass: "Platforms"}},
+		similarSkills("kubernets", testSkillNames), "one typo")
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 4, Skill: "Kubernetes", SkillClass: "Platforms", Alias: "k8s"}},
+		similarSkills("K8S", testSkillNames))
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 4, Skill: "Kubernetes", SkillClass: "Platforms"}},
+		similarSkills("docker-swarm", testSkillNames), "deprecated skills point to their replacement")
+	assert.Empty(t, similarSkills("C", testSkillNames), "C is neither C++ nor C#")
+	assert.Empty(t, similarSkills("Clang", []instances.Skill{{SkillId: 7, Skill: "C"}}), "Clang is not C")
+	assert.Empty(t, similarSkills("Glem", testSkillNames), "short names must match exactly")
+	assert.Empty(t, similarSkills("Rust", testSkillNames))
+}
+
+func TestSuggestSkills(t *testing.T) {
+	assert.Equal(t, []instances.SkillSuggestion{
+		{SkillId: 1, Skill: "Go", SkillClass: "Programming"},
+		{SkillId: 6, Skill: "Gleam", SkillClass: "Programming"},
+	}, suggestSkills("G", testSkillNames, 0))
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 1, Skill: "Go", SkillClass: "Programming", Alias: "golang"}},
+		suggestSkills("gola", testSkillNames, 0), "found by alias")
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 4, Skill: "Kubernetes", SkillClass: "Platforms", Alias: "Docker Swarm"}},
+		suggestSkills("docker s", testSkillNames, 0), "deprecated skills suggest their replacement")
+	assert.Equal(t, []instances.SkillSuggestion{{SkillId: 2, Skill: "C++", SkillClass: "Programming"}},
+		suggestSkills("c+", testSkillNames, 0))
+	assert.Len(t, suggestSkills("c", testSkillNames, 1), 1)
+}
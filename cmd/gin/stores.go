@@ -1,54 +1,104 @@
 package main
 
-//TODO maybe there is a way to at least make the constructor generic?
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"esmAPI/pkg/instances"
 	"fmt"
+	"github.com/XSAM/otelsql"
 	"github.com/go-sql-driver/mysql"
-	"log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"log/slog"
+	"strings"
 )
 
 // data store interface for employee
 type employeeStore interface {
-	Add(emp instances.Employee) (int, error)
-	Get(employeeId int64) (emp instances.Employee, err error)
-	List() ([]instances.Employee, error)
-	Update(currId int64, emp instances.Employee) (int64, error)
-	Delete(employeeId int64) (int64, error)
-	GetFull(employeeId int64) (emp instances.EmployeeFull, err error)
-	ListFull() ([]instances.EmployeeFull, error)
-	AddSkill(employeeId int64, skillId int64, skillLevel int64) (int64, error)
-	DeleteSkill(employeeId int64, skillId int64) (int64, error)
-	UpdateSkill(employeeId int64, skillId int64, skillLevel int64) (int64, error)
-	AddProject(projectId int64, employeeId int64, employeeRole string) (int64, error)
-	DeleteProject(projectId int64, employeeId int64) (int64, error)
-	UpdateProject(projectId int64, employeeId int64, employeeRole string) (int64, error)
+	Add(ctx context.Context, emp instances.Employee) (int, error)
+	Get(ctx context.Context, employeeId int64) (emp instances.Employee, err error)
+	List(ctx context.Context) ([]instances.Employee, error)
+	Update(ctx context.Context, currId int64, emp instances.Employee) (int64, error)
+	// Patch changes an employee to what apply makes of the current one, in one transaction
+	Patch(ctx context.Context, employeeId int64, apply func(instances.Employee) (instances.Employee, error)) (instances.Employee, error)
+	Delete(ctx context.Context, employeeId int64) (int64, error)
+	GetFull(ctx context.Context, employeeId int64, filter instances.AssignmentFilter) (emp instances.EmployeeFull, err error)
+	ListFull(ctx context.Context, filter instances.AssignmentFilter) ([]instances.EmployeeFull, error)
+	AddSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error)
+	DeleteSkill(ctx context.Context, employeeId int64, skillId int64) (int64, error)
+	UpdateSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error)
+	PatchSkill(ctx context.Context, employeeId int64, skillId int64, apply func(instances.EmployeeSkill) (instances.EmployeeSkill, error)) (instances.EmployeeSkill, error)
+	SkillHistory(ctx context.Context, employeeId int64, skillId int64) (instances.SkillHistory, error)
+	ImportProfile(ctx context.Context, emp instances.Employee, skills []instances.EmployeeSkill) (bool, error)
+	AddProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error)
+	DeleteProject(ctx context.Context, projectId int64, employeeId int64) (int64, error)
+	UpdateProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error)
+	Projects(ctx context.Context, employeeId int64, filter instances.AssignmentFilter) ([]instances.ProjectFull, error)
+	UpdateAssignment(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error)
+	PatchAssignment(ctx context.Context, employeeId int64, assignmentId int64, apply func(instances.EmployeeProject) (instances.EmployeeProject, error)) (instances.EmployeeProject, error)
+	DeleteAssignment(ctx context.Context, employeeId int64, assignmentId int64) (int64, error)
+	Search(ctx context.Context, filter instances.SkillSearch) ([]instances.EmployeeMatch, error)
 	//TODO associate a project with an employee
 }
 
 type skillStore interface {
-	Add(skill instances.Skill) (int, error)
-	Get(skillId int64) (emp instances.Skill, err error)
-	List() ([]instances.Skill, error)
-	Update(currId int64, skill instances.Skill) (int64, error)
-	Delete(skillId int64) (int64, error)
+	Add(ctx context.Context, skill instances.Skill) (int, error)
+	Get(ctx context.Context, skillId int64) (emp instances.Skill, err error)
+	List(ctx context.Context) ([]instances.Skill, error)
+	// Update changes a skill except for its id, see Rekey
+	Update(ctx context.Context, currId int64, skill instances.Skill) (int64, error)
+	// Patch changes a skill to what apply makes of the current one, in one transaction
+	Patch(ctx context.Context, skillId int64, apply func(instances.Skill) (instances.Skill, error)) (instances.Skill, error)
+	Delete(ctx context.Context, skillId int64) (int64, error)
+	// Rekey changes the id of a skill and of every reference to it
+	Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error)
+	AddAlias(ctx context.Context, skillId int64, alias string) (int64, error)
+	DeleteAlias(ctx context.Context, skillId int64, alias string) (int64, error)
 }
 
 type projectStore interface {
-	Add(proj instances.Project) (int, error)
-	Get(projId int64) (proj instances.Project, err error)
-	List() ([]instances.Project, error)
-	Update(currId int64, proj instances.Project) (int64, error)
-	Delete(projId int64) (int64, error)
+	Add(ctx context.Context, proj instances.Project) (int, error)
+	Get(ctx context.Context, projId int64) (proj instances.Project, err error)
+	List(ctx context.Context) ([]instances.Project, error)
+	// Update changes a project except for its id, see Rekey
+	Update(ctx context.Context, currId int64, proj instances.Project) (int64, error)
+	// Patch changes a project to what apply makes of the current one, in one transaction
+	Patch(ctx context.Context, projId int64, apply func(instances.Project) (instances.Project, error)) (instances.Project, error)
+	Delete(ctx context.Context, projId int64) (int64, error)
+	// Rekey changes the id of a project and of every reference to it
+	Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error)
 }
 
 type clientStore interface {
-	Add(client instances.Client) (int, error)
-	Get(clientId int64) (client instances.Client, err error)
-	List() ([]instances.Client, error)
-	Update(currId int64, client instances.Client) (int64, error)
-	Delete(clientId int64) (int64, error)
+	Add(ctx context.Context, client instances.Client) (int, error)
+	Get(ctx context.Context, clientId int64) (client instances.Client, err error)
+	List(ctx context.Context) ([]instances.Client, error)
+	// Update changes a client except for its id, see Rekey
+	Update(ctx context.Context, currId int64, client instances.Client) (int64, error)
+	// Patch changes a client to what apply makes of the current one, in one transaction
+	Patch(ctx context.Context, clientId int64, apply func(instances.Client) (instances.Client, error)) (instances.Client, error)
+	Delete(ctx context.Context, clientId int64) (int64, error)
+	// Rekey changes the id of a client and of every reference to it
+	Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error)
+}
+
+// openDB opens a database handle and verifies the connection. store names the store the handle belongs to,
+// which is only used for logging.
+func openDB(cfg mysql.Config, store string) (*sql.DB, error) {
+	// every statement executed through the handle gets its own span
+	db, err := otelsql.Open("mysql", cfg.FormatDSN(),
+		otelsql.WithAttributes(semconv.DBSystemMySQL, semconv.DBNamespace(cfg.DBName)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("connecting %s store to %s: %v", store, cfg.Addr, err)
+	}
+	slog.Info("connected to database", "store", store, "addr", cfg.Addr, "db", cfg.DBName)
+	return db, nil
 }
 
 type MySQLEmployeeStore struct {
@@ -56,83 +106,175 @@ type MySQLEmployeeStore struct {
 }
 
 func NewEmployeeStore(cfg mysql.Config) (*MySQLEmployeeStore, error) {
-
-	// Get a database handle.
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+	db, err := openDB(cfg, "employees")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	return &MySQLEmployeeStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLEmployeeStore) Close() error {
+	return s.db.Close()
+}
+
+// employeeColumns are read by scanEmployee
+const employeeColumns = "employee_id, name, lastname, focus_area, email, manager_id, department_id"
+
+// qualifiedColumns prefixes each of the comma separated columns with the table alias, for queries joining tables
+// with columns of the same name
+func qualifiedColumns(alias string, columns string) string {
+	names := strings.Split(columns, ", ")
+	for i, name := range names {
+		names[i] = alias + "." + name
 	}
+	return strings.Join(names, ", ")
+}
 
-	pingErr := db.Ping()
-	if pingErr != nil {
-		log.Fatal(pingErr)
+// scanEmployee reads employeeColumns, followed by the columns read into extra if the query selects more
+func scanEmployee(row rowScanner, extra ...any) (instances.Employee, error) {
+	var emp instances.Employee
+	var focusArea, email sql.NullString
+	var managerId, departmentId sql.NullInt64
+	dest := append([]any{&emp.EmployeeId, &emp.Name, &emp.Lastname, &focusArea, &email, &managerId, &departmentId},
+		extra...)
+	if err := row.Scan(dest...); err != nil {
+		return instances.Employee{}, err
 	}
-	fmt.Println("Connected!")
-	return &MySQLEmployeeStore{db: db}, nil
+	emp.FocusArea = focusArea.String
+	emp.Email = email.String
+	emp.ManagerId = nullInt64Ptr(managerId)
+	emp.DepartmentId = nullInt64Ptr(departmentId)
+	return emp, nil
 }
 
-func (s *MySQLEmployeeStore) Add(emp instances.Employee) (int, error) {
-	result, err := s.db.Exec(
-		"INSERT INTO Employees (employee_id, name, lastname, focus_area, email) VALUES (?,?,?,?,?)",
-		emp.EmployeeId, emp.Name, emp.Lastname, emp.FocusArea, emp.Email)
+// Add creates an employee. Employees managing themselves fail with errManagerCycle.
+func (s *MySQLEmployeeStore) Add(ctx context.Context, emp instances.Employee) (int, error) {
+	if emp.ManagerId != nil && *emp.ManagerId == emp.EmployeeId {
+		return -1, errManagerCycle
+	}
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO Employees (employee_id, name, lastname, focus_area, email, manager_id, department_id) "+
+			"VALUES (?,?,?,?,?,?,?)",
+		emp.EmployeeId, emp.Name, emp.Lastname, emp.FocusArea, emp.Email, emp.ManagerId, emp.DepartmentId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.Add", err, "employee_id", emp.EmployeeId)
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.Add", err, "employee_id", emp.EmployeeId)
 	}
 	return int(id), nil
 }
 
-func (s *MySQLEmployeeStore) Delete(employeeId int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM Employees WHERE employee_id=?", employeeId)
+func (s *MySQLEmployeeStore) Delete(ctx context.Context, employeeId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Employees WHERE employee_id=?", employeeId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.Delete", err, "employee_id", employeeId)
 	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLEmployeeStore) Update(currId int64, emp instances.Employee) (int64, error) {
-	result, err := s.db.Exec(
-		"UPDATE Employees SET name=?, lastname=?, focus_area=?, email=? WHERE employee_id = ?",
-		emp.Name, emp.Lastname, emp.FocusArea, emp.Email, currId)
+// Update changes an employee. Reporting to one of their own reports fails with errManagerCycle.
+func (s *MySQLEmployeeStore) Update(ctx context.Context, currId int64, emp instances.Employee) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return -1, queryError(ctx, "employees.Update", err, "employee_id", currId)
+	}
+	defer tx.Rollback()
+
+	result, err := updateEmployee(ctx, tx, currId, emp)
+	if errors.Is(err, errManagerCycle) {
 		return -1, err
 	}
+	if err != nil {
+		return -1, queryError(ctx, "employees.Update", err, "employee_id", currId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.Update", err, "employee_id", currId)
+	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLEmployeeStore) Get(employeeId int64) (instances.Employee, error) {
-	var emp instances.Employee
+// updateEmployee writes emp over the employee currId, except for the id. Reporting to one of their own reports
+// fails with errManagerCycle.
+func updateEmployee(ctx context.Context, tx *sql.Tx, currId int64, emp instances.Employee) (sql.Result, error) {
+	if emp.ManagerId != nil {
+		// lock the reporting lines, so two concurrent changes cannot create a cycle together
+		employees, err := lockReportingLines(ctx, tx, currId, *emp.ManagerId)
+		if err != nil {
+			return nil, err
+		}
+		if createsReportingCycle(employees, currId, emp.ManagerId) {
+			return nil, errManagerCycle
+		}
+	}
+	return tx.ExecContext(ctx,
+		"UPDATE Employees SET name=?, lastname=?, focus_area=?, email=?, manager_id=?, department_id=? "+
+			"WHERE employee_id = ?",
+		emp.Name, emp.Lastname, emp.FocusArea, emp.Email, emp.ManagerId, emp.DepartmentId, currId)
+}
 
-	row := s.db.QueryRow("SELECT * FROM Employees WHERE employee_id = ?", employeeId)
-	if err := row.Scan(&emp.EmployeeId, &emp.Name, &emp.Lastname, &emp.FocusArea, &emp.Email); err != nil {
-		return instances.Employee{}, err
+func (s *MySQLEmployeeStore) Get(ctx context.Context, employeeId int64) (instances.Employee, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+employeeColumns+" FROM Employees WHERE employee_id = ?", employeeId)
+	emp, err := scanEmployee(row)
+	if err != nil {
+		return instances.Employee{}, queryError(ctx, "employees.Get", err, "employee_id", employeeId)
 	}
 	return emp, nil
 }
 
-func (s *MySQLEmployeeStore) List() ([]instances.Employee, error) {
-	var employees []instances.Employee
+func (s *MySQLEmployeeStore) List(ctx context.Context) ([]instances.Employee, error) {
+	employees, err := queryEmployees(ctx, s.db, "TRUE")
+	if err != nil {
+		return nil, queryError(ctx, "employees.List", fmt.Errorf("sqlGetAllEmployees %v", err))
+	}
+	return employees, nil
+}
 
-	rows, err := s.db.Query("SELECT * FROM Employees")
+// queryEmployees returns the employees matching where
+func queryEmployees(ctx context.Context, q queryer, where string, args ...any) ([]instances.Employee, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+employeeColumns+" FROM Employees WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanEmployees(rows)
+}
+
+// lockEmployees returns the employees ids and locks their rows until tx ends, ids that do not exist are skipped
+func lockEmployees(ctx context.Context, tx *sql.Tx, ids ...int64) ([]instances.Employee, error) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT "+employeeColumns+" FROM Employees WHERE employee_id IN ("+
+		placeholders(len(ids))+") FOR UPDATE", args...)
 	if err != nil {
-		return nil, fmt.Errorf("sqlGetAllEmployees %v", err)
+		return nil, err
 	}
+	return scanEmployees(rows)
+}
+
+// lockReportingLines returns the employees ids and everyone they report to directly or indirectly, and locks their
+// rows until tx ends. A change that could close a reporting cycle with the locked lines has to wait for tx.
+func lockReportingLines(ctx context.Context, tx *sql.Tx, ids ...int64) ([]instances.Employee, error) {
+	return reportingLines(ids, func(ids []int64) ([]instances.Employee, error) {
+		return lockEmployees(ctx, tx, ids...)
+	})
+}
+
+func scanEmployees(rows *sql.Rows) ([]instances.Employee, error) {
 	defer rows.Close()
 
+	var employees []instances.Employee
 	for rows.Next() {
-		var emp instances.Employee
-		if err := rows.Scan(&emp.EmployeeId, &emp.Name, &emp.Lastname, &emp.FocusArea, &emp.Email); err != nil {
-			return nil, fmt.Errorf("sqlGetAllEmployees %v", err)
+		emp, err := scanEmployee(rows)
+		if err != nil {
+			return nil, err
 		}
 		employees = append(employees, emp)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("sqlGetAllEmployees %v", err)
-	}
-	return employees, nil
+	return employees, rows.Err()
 }
 
 type MySQLSkillStore struct {
@@ -140,163 +282,296 @@ type MySQLSkillStore struct {
 }
 
 func NewSkillStore(cfg mysql.Config) (*MySQLSkillStore, error) {
-
-	// Get a database handle.
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+	db, err := openDB(cfg, "skills")
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	pingErr := db.Ping()
-	if pingErr != nil {
-		log.Fatal(pingErr)
+		return nil, err
 	}
-	fmt.Println("Connected!")
 	return &MySQLSkillStore{db: db}, nil
 }
 
-func (s *MySQLSkillStore) Delete(id int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM Skills WHERE skill_id=?", id)
+// Close closes the store's connection pool
+func (s *MySQLSkillStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLSkillStore) Delete(ctx context.Context, id int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Skills WHERE skill_id=?", id)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "skills.Delete", err, "skill_id", id)
 	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLSkillStore) Update(currId int64, skill instances.Skill) (int64, error) {
-	result, err := s.db.Exec(
-		"UPDATE Skills SET skill_id=?, skill_class=?, skill=? WHERE skill_id = ?",
-		skill.SkillId, skill.SkillClass, skill.Skill, currId)
+func (s *MySQLSkillStore) Update(ctx context.Context, currId int64, skill instances.Skill) (int64, error) {
+	result, err := updateSkill(ctx, s.db, currId, skill)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "skills.Update", err, "skill_id", currId)
 	}
 	return result.RowsAffected()
 }
 
+// updateSkill writes skill over the skill currId, except for the id and the aliases
+func updateSkill(ctx context.Context, e execer, currId int64, skill instances.Skill) (sql.Result, error) {
+	return e.ExecContext(ctx,
+		"UPDATE Skills SET skill_class=?, skill=?, category_id=?, deprecated=?, replaced_by=? WHERE skill_id = ?",
+		skill.SkillClass, skill.Skill, skill.CategoryId, skill.Deprecated, skill.ReplacedBy, currId)
+}
+
 // We use Skill struct which also contains skill level, as it is usually associated with an Employee.
 // In this case however, we only want to see what Skills are available in database, thus skill level is nil
-func (s *MySQLSkillStore) List() ([]instances.Skill, error) {
+func (s *MySQLSkillStore) List(ctx context.Context) ([]instances.Skill, error) {
 	var skills []instances.Skill
 
-	rows, err := s.db.Query("SELECT skill_id, skill_class, skill FROM Skills")
+	rows, err := s.db.QueryContext(ctx, "SELECT skill_id, skill_class, skill, category_id, deprecated, replaced_by FROM Skills")
 	if err != nil {
-		return nil, err
+		return nil, queryError(ctx, "skills.List", err)
 	}
 
 	defer rows.Close()
 
 	for rows.Next() {
-		var skill instances.Skill
-
-		if err := rows.Scan(&skill.SkillId, &skill.SkillClass, &skill.Skill); err != nil {
-			return nil, err
+		skill, err := scanSkill(rows)
+		if err != nil {
+			return nil, queryError(ctx, "skills.List", err)
 		}
 
 		skills = append(skills, skill)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "skills.List", err)
+	}
+
+	aliases, err := queryAliases(ctx, s.db, 0)
+	if err != nil {
+		return nil, queryError(ctx, "skills.List", err)
+	}
+	for i := range skills {
+		skills[i].Aliases = aliases[int64(skills[i].SkillId)]
+	}
 	return skills, nil
 }
 
-func (s *MySQLSkillStore) Add(skill instances.Skill) (int, error) {
-	result, err := s.db.Exec(
-		"INSERT INTO Skills (skill_id, skill_class, skill) VALUES (?,?,?)",
-		skill.SkillId, skill.SkillClass, skill.Skill)
+func (s *MySQLSkillStore) Add(ctx context.Context, skill instances.Skill) (int, error) {
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO Skills (skill_id, skill_class, skill, category_id, deprecated, replaced_by) VALUES (?,?,?,?,?,?)",
+		skill.SkillId, skill.SkillClass, skill.Skill, skill.CategoryId, skill.Deprecated, skill.ReplacedBy)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "skills.Add", err, "skill_id", skill.SkillId)
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "skills.Add", err, "skill_id", skill.SkillId)
 	}
 	return int(id), nil
 }
 
-func (s *MySQLSkillStore) Get(id int64) (instances.Skill, error) {
+func (s *MySQLSkillStore) Get(ctx context.Context, id int64) (instances.Skill, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT skill_id, skill_class, skill, category_id, deprecated, replaced_by "+
+		"FROM Skills WHERE skill_id=?", id)
+	skill, err := scanSkill(row)
+	if err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Get", err, "skill_id", id)
+	}
+	aliases, err := queryAliases(ctx, s.db, id)
+	if err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Get", err, "skill_id", id)
+	}
+	skill.Aliases = aliases[id]
+	return skill, nil
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// scanSkill reads the columns skill_id, skill_class, skill, category_id, deprecated, replaced_by
+func scanSkill(row rowScanner) (instances.Skill, error) {
 	var skill instances.Skill
-	row := s.db.QueryRow("SELECT * FROM Skills WHERE skill_id=?", id)
-	if err := row.Scan(&skill.SkillId, &skill.SkillClass, &skill.Skill); err != nil {
+	var categoryId, replacedBy sql.NullInt64
+	if err := row.Scan(&skill.SkillId, &skill.SkillClass, &skill.Skill, &categoryId, &skill.Deprecated, &replacedBy); err != nil {
 		return instances.Skill{}, err
 	}
+	skill.CategoryId = nullInt64Ptr(categoryId)
+	skill.ReplacedBy = nullInt64Ptr(replacedBy)
 	return skill, nil
 }
 
+func nullInt64Ptr(n sql.NullInt64) *int64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int64
+}
+
+// projectColumns are the columns scanProject reads
+const projectColumns = "project_id, client_id, focus_area, description, isSecret"
+
+// scanProject reads the projectColumns, NULL columns are read as zero values
+func scanProject(row rowScanner) (instances.Project, error) {
+	var proj instances.Project
+	var clientId sql.NullInt64
+	var focusArea, description sql.NullString
+	var isSecret sql.NullBool
+	if err := row.Scan(&proj.ProjectId, &clientId, &focusArea, &description, &isSecret); err != nil {
+		return instances.Project{}, err
+	}
+	proj.ClientId = int(clientId.Int64)
+	proj.FocusArea = focusArea.String
+	proj.Description = description.String
+	proj.IsSecret = isSecret.Bool
+	return proj, nil
+}
+
+// scanClient reads the columns id, name, description
+func scanClient(row rowScanner) (instances.Client, error) {
+	var client instances.Client
+	var description sql.NullString
+	if err := row.Scan(&client.ID, &client.Name, &description); err != nil {
+		return instances.Client{}, err
+	}
+	client.Description = description.String
+	return client, nil
+}
+
+// nullInt64 stores 0 as NULL, for references that are optional
+func nullInt64(n int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: n, Valid: n != 0}
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// queryAliases returns the aliases of a skill, or of all skills if skillId is 0, by skill id
+func queryAliases(ctx context.Context, q queryer, skillId int64) (map[int64][]string, error) {
+	query := "SELECT skill_id, alias FROM SkillAliases"
+	var args []any
+	if skillId != 0 {
+		query += " WHERE skill_id = ?"
+		args = append(args, skillId)
+	}
+	rows, err := q.QueryContext(ctx, query+" ORDER BY alias", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aliases := make(map[int64][]string)
+	for rows.Next() {
+		var id int64
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			return nil, err
+		}
+		aliases[id] = append(aliases[id], alias)
+	}
+	return aliases, rows.Err()
+}
+
+func (s *MySQLSkillStore) AddAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO SkillAliases (alias, skill_id) VALUES (?,?)", alias, skillId)
+	if err != nil {
+		return -1, queryError(ctx, "skills.AddAlias", err, "skill_id", skillId, "alias", alias)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLSkillStore) DeleteAlias(ctx context.Context, skillId int64, alias string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM SkillAliases WHERE skill_id = ? AND alias = ?", skillId, alias)
+	if err != nil {
+		return -1, queryError(ctx, "skills.DeleteAlias", err, "skill_id", skillId, "alias", alias)
+	}
+	return result.RowsAffected()
+}
+
 type MySQLProjectStore struct {
 	db *sql.DB
 }
 
 func NewProjectStore(cfg mysql.Config) (*MySQLProjectStore, error) {
-	// Get a database handle.
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+	db, err := openDB(cfg, "projects")
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	pingErr := db.Ping()
-	if pingErr != nil {
-		log.Fatal(pingErr)
+		return nil, err
 	}
-	fmt.Println("Connected!")
 	return &MySQLProjectStore{db: db}, nil
 }
 
-func (s *MySQLProjectStore) List() ([]instances.Project, error) {
+// Close closes the store's connection pool
+func (s *MySQLProjectStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLProjectStore) List(ctx context.Context) ([]instances.Project, error) {
 	var projects []instances.Project
 
-	rows, err := s.db.Query("SELECT * FROM projects")
+	rows, err := s.db.QueryContext(ctx, "SELECT * FROM projects")
 	if err != nil {
-		return nil, fmt.Errorf("sqlGetAllProjects: %v", err)
+		return nil, queryError(ctx, "projects.List", fmt.Errorf("sqlGetAllProjects: %v", err))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var project instances.Project
 		if err := rows.Scan(&project.ProjectId, &project.ClientId, &project.FocusArea, &project.Description, &project.IsSecret); err != nil {
-			return nil, fmt.Errorf("sqlGetAllProjects: %v", err)
+			return nil, queryError(ctx, "projects.List", fmt.Errorf("sqlGetAllProjects: %v", err))
 		}
 		projects = append(projects, project)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("sqlGetAllProjects: %v", err)
+		return nil, queryError(ctx, "projects.List", fmt.Errorf("sqlGetAllProjects: %v", err))
 	}
 	return projects, nil
 }
 
-func (s *MySQLProjectStore) Get(id int64) (instances.Project, error) {
+func (s *MySQLProjectStore) Get(ctx context.Context, id int64) (instances.Project, error) {
 	var proj instances.Project
 
-	row := s.db.QueryRow("SELECT * FROM Projects WHERE project_id = ?", id)
+	row := s.db.QueryRowContext(ctx, "SELECT * FROM Projects WHERE project_id = ?", id)
 	if err := row.Scan(&proj.ProjectId, &proj.ClientId, &proj.FocusArea, &proj.Description, &proj.IsSecret); err != nil {
-		return instances.Project{}, err
+		return instances.Project{}, queryError(ctx, "projects.Get", err, "project_id", id)
 	}
 	return proj, nil
 }
 
-func (s *MySQLProjectStore) Add(proj instances.Project) (int, error) {
-	result, err := s.db.Exec("INSERT INTO Projects (project_id, client_id, focus_area, description, isSecret)"+
+func (s *MySQLProjectStore) Add(ctx context.Context, proj instances.Project) (int, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO Projects (project_id, client_id, focus_area, description, isSecret)"+
 		" VALUES(?, ?, ?, ?, ?)", proj.ProjectId, proj.ClientId, proj.FocusArea, proj.Description, proj.IsSecret)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "projects.Add", err, "project_id", proj.ProjectId)
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "projects.Add", err, "project_id", proj.ProjectId)
 	}
 	return int(id), nil
 }
 
-func (s *MySQLProjectStore) Update(currId int64, proj instances.Project) (int64, error) {
-	result, err := s.db.Exec(
-		"UPDATE Projects SET project_id=?, client_id=?, focus_area=?, description=?, isSecret=? WHERE project_id = ?",
-		proj.ProjectId, proj.ClientId, proj.FocusArea, proj.Description, proj.IsSecret, currId)
+func (s *MySQLProjectStore) Update(ctx context.Context, currId int64, proj instances.Project) (int64, error) {
+	result, err := updateProject(ctx, s.db, currId, proj)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "projects.Update", err, "project_id", currId)
 	}
 	return result.RowsAffected()
 }
-func (s *MySQLProjectStore) Delete(projId int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM Projects WHERE project_id = ?", projId)
+
+// updateProject writes proj over the project currId, except for the id
+func updateProject(ctx context.Context, e execer, currId int64, proj instances.Project) (sql.Result, error) {
+	return e.ExecContext(ctx,
+		"UPDATE Projects SET client_id=?, focus_area=?, description=?, isSecret=? WHERE project_id = ?",
+		nullInt64(int64(proj.ClientId)), proj.FocusArea, proj.Description, proj.IsSecret, currId)
+}
+
+func (s *MySQLProjectStore) Delete(ctx context.Context, projId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Projects WHERE project_id = ?", projId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "projects.Delete", err, "project_id", projId)
 	}
 	return result.RowsAffected()
 }
@@ -306,93 +581,96 @@ type MySQLClientStore struct {
 }
 
 func NewClientStore(cfg mysql.Config) (*MySQLClientStore, error) {
-	// Get a database handle.
-	db, err := sql.Open("mysql", cfg.FormatDSN())
+	db, err := openDB(cfg, "clients")
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	pingErr := db.Ping()
-	if pingErr != nil {
-		log.Fatal(pingErr)
+		return nil, err
 	}
-	fmt.Println("Connected!")
 	return &MySQLClientStore{db: db}, nil
 }
 
-func (s *MySQLClientStore) List() ([]instances.Client, error) {
+// Close closes the store's connection pool
+func (s *MySQLClientStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLClientStore) List(ctx context.Context) ([]instances.Client, error) {
 	var clients []instances.Client
 
-	rows, err := s.db.Query("SELECT * FROM Clients")
+	rows, err := s.db.QueryContext(ctx, "SELECT * FROM Clients")
 	if err != nil {
-		return nil, fmt.Errorf("sqlGetAllClients: %v", err)
+		return nil, queryError(ctx, "clients.List", fmt.Errorf("sqlGetAllClients: %v", err))
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var client instances.Client
 		if err := rows.Scan(&client.ID, &client.Name, &client.Description); err != nil {
-			return nil, fmt.Errorf("sqlGetAllClients: %v", err)
+			return nil, queryError(ctx, "clients.List", fmt.Errorf("sqlGetAllClients: %v", err))
 		}
 		clients = append(clients, client)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("sqlGetAllClients: %v", err)
+		return nil, queryError(ctx, "clients.List", fmt.Errorf("sqlGetAllClients: %v", err))
 	}
 	return clients, nil
 }
 
-func (s *MySQLClientStore) Get(id int64) (instances.Client, error) {
+func (s *MySQLClientStore) Get(ctx context.Context, id int64) (instances.Client, error) {
 	var client instances.Client
-	row := s.db.QueryRow("SELECT * FROM Clients WHERE id = ?", id)
+	row := s.db.QueryRowContext(ctx, "SELECT * FROM Clients WHERE id = ?", id)
 	if err := row.Scan(&client.ID, &client.Name, &client.Description); err != nil {
-		return instances.Client{}, err
+		return instances.Client{}, queryError(ctx, "clients.Get", err, "client_id", id)
 	}
 	return client, nil
 }
 
-func (s *MySQLClientStore) Add(client instances.Client) (int, error) {
-	result, err := s.db.Exec("INSERT INTO Clients (id, name, description)"+
+func (s *MySQLClientStore) Add(ctx context.Context, client instances.Client) (int, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO Clients (id, name, description)"+
 		" VALUES(?, ?, ?)", client.ID, client.Name, client.Description)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "clients.Add", err, "client_id", client.ID)
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "clients.Add", err, "client_id", client.ID)
 	}
 	return int(id), nil
 }
 
-func (s *MySQLClientStore) Update(currId int64, client instances.Client) (int64, error) {
-	result, err := s.db.Exec(
-		"UPDATE Clients SET id=?, name=?, description=? WHERE id = ?",
-		client.ID, client.Name, client.Description, currId)
+func (s *MySQLClientStore) Update(ctx context.Context, currId int64, client instances.Client) (int64, error) {
+	result, err := updateClient(ctx, s.db, currId, client)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "clients.Update", err, "client_id", currId)
 	}
 	return result.RowsAffected()
 }
-func (s *MySQLClientStore) Delete(clientId int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM Clients WHERE id = ?", clientId)
+
+// updateClient writes client over the client currId, except for the id
+func updateClient(ctx context.Context, e execer, currId int64, client instances.Client) (sql.Result, error) {
+	return e.ExecContext(ctx, "UPDATE Clients SET name=?, description=? WHERE id = ?",
+		client.Name, client.Description, currId)
+}
+
+func (s *MySQLClientStore) Delete(ctx context.Context, clientId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Clients WHERE id = ?", clientId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "clients.Delete", err, "client_id", clientId)
 	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLEmployeeStore) ListFull() ([]instances.EmployeeFull, error) {
+func (s *MySQLEmployeeStore) ListFull(ctx context.Context, filter instances.AssignmentFilter) ([]instances.EmployeeFull, error) {
 	var employeesFull []instances.EmployeeFull
 
 	//first, get all the employees
-	employees, err := s.List()
+	employees, err := s.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("sqlGetAllProjects: %v", err)
 	}
 
 	//iterate through each employee and find associated projects and skills. Then append employeesFull
 	for _, employee := range employees {
-		employeeFull, err := s.GetFull(employee.EmployeeId)
+		employeeFull, err := s.GetFull(ctx, employee.EmployeeId, filter)
 		if err != nil {
 			return nil, fmt.Errorf("sqlGetFullEmployeeById: %v", err)
 		}
@@ -402,101 +680,141 @@ func (s *MySQLEmployeeStore) ListFull() ([]instances.EmployeeFull, error) {
 	return employeesFull, nil
 }
 
-func (s *MySQLEmployeeStore) GetFull(id int64) (instances.EmployeeFull, error) {
-	employee, err := s.Get(id)
+func (s *MySQLEmployeeStore) GetFull(ctx context.Context, id int64, filter instances.AssignmentFilter) (instances.EmployeeFull, error) {
+	employee, err := s.Get(ctx, id)
 	if err != nil {
 		return instances.EmployeeFull{}, err
 	}
 
 	var employeeFull instances.EmployeeFull
 	var skills []instances.Skill
-	var projects []instances.ProjectFull
 
 	//find associated skills
-	rows, err := s.db.Query("SELECT s.skill_id,s.skill_class, s.skill, e.skill_level FROM EmployeeSkills AS e "+
-		"INNER JOIN Skills AS s ON e.skill_id = s.skill_id WHERE employee_id = ?", employee.EmployeeId)
+	rows, err := s.db.QueryContext(ctx, "SELECT s.skill_id,s.skill_class, s.skill, e.skill_level, l.label, "+
+		"sc.skill_class IS NOT NULL, (SELECT MAX(a.assessed_at) FROM SkillAssessments AS a "+
+		"WHERE a.employee_id = e.employee_id AND a.skill_id = e.skill_id) FROM EmployeeSkills AS e "+
+		"INNER JOIN Skills AS s ON e.skill_id = s.skill_id "+
+		"LEFT JOIN SkillScales AS sc ON sc.skill_class = s.skill_class "+
+		"LEFT JOIN SkillScaleLevels AS l ON l.skill_class = s.skill_class AND l.level = e.skill_level "+
+		"WHERE employee_id = ?", employee.EmployeeId)
 	if err != nil {
-		return instances.EmployeeFull{}, fmt.Errorf("sqlGetFullEmployees: %v", err)
+		return instances.EmployeeFull{}, queryError(ctx, "employees.GetFull", fmt.Errorf("sqlGetFullEmployees: %v", err), "employee_id", id)
 	}
 	for rows.Next() {
 		var skill instances.Skill
-		if err := rows.Scan(&skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel); err != nil {
-			return instances.EmployeeFull{}, fmt.Errorf("sqlGetFullEmployees: %v", err)
+		var label sql.NullString
+		var hasScale bool
+		var lastAssessed sql.NullTime
+		if err := rows.Scan(&skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel, &label, &hasScale,
+			&lastAssessed); err != nil {
+			return instances.EmployeeFull{}, queryError(ctx, "employees.GetFull", fmt.Errorf("sqlGetFullEmployees: %v", err), "employee_id", id)
+		}
+		skill.SkillLevelLabel = label.String
+		if lastAssessed.Valid {
+			skill.LastAssessed = &lastAssessed.Time
+		}
+		if !hasScale {
+			if level, ok := instances.DefaultSkillScale.Find(skill.SkillLevel); ok {
+				skill.SkillLevelLabel = level.Label
+			}
 		}
 		skills = append(skills, skill)
 	}
 
 	//find associate projects
-	rows, err = s.db.Query("SELECT a.*, b.employee_role FROM Projects AS a "+
-		"INNER JOIN ProjectDetails as b  ON a.project_id = b.project_id WHERE employee_id = ?", employee.EmployeeId)
+	projects, err := queryAssignments(ctx, s.db, employee.EmployeeId, filter)
 	if err != nil {
-		return instances.EmployeeFull{}, fmt.Errorf("sqlGetFullEmployees: %v", err)
+		return instances.EmployeeFull{}, queryError(ctx, "employees.GetFull", fmt.Errorf("sqlGetFullEmployees: %v", err), "employee_id", id)
 	}
-	for rows.Next() {
-		var projectFull instances.ProjectFull
 
-		if err := rows.Scan(&projectFull.Project.ProjectId,
-			&projectFull.Project.ClientId, &projectFull.Project.FocusArea,
-			&projectFull.Project.Description, &projectFull.Project.IsSecret, &projectFull.EmployeeRole); err != nil {
-			return instances.EmployeeFull{}, fmt.Errorf("sqlGetFullEmployees: %v", err)
-		}
-		projects = append(projects, projectFull)
+	certifications, err := queryCertifications(ctx, s.db, "employee_id = ? ORDER BY issued_on", employee.EmployeeId)
+	if err != nil {
+		return instances.EmployeeFull{}, queryError(ctx, "employees.GetFull", fmt.Errorf("sqlGetFullEmployees: %v", err), "employee_id", id)
 	}
 	employeeFull.Employee = employee
 	employeeFull.Skills = skills
 	employeeFull.Projects = projects
+	employeeFull.Certifications = certifications
 
 	return employeeFull, nil
 }
 
-func (s *MySQLEmployeeStore) AddSkill(employeeId int64, skillId int64, skillLevel int64) (int64, error) {
-	result, err := s.db.Exec("INSERT INTO EmployeeSkills (employee_id, skill_id, skill_level) VALUES(?,?,?)",
-		employeeId, skillId, skillLevel)
+// AddSkill assigns a skill to an employee and records the level as the first assessment
+func (s *MySQLEmployeeStore) AddSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.AddSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO EmployeeSkills (employee_id, skill_id, skill_level) VALUES(?,?,?)",
+		employeeId, empSkill.SkillId, empSkill.SkillLevel)
+	if err != nil {
+		return -1, queryError(ctx, "employees.AddSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	if err := insertAssessment(ctx, tx, employeeId, empSkill); err != nil {
+		return -1, queryError(ctx, "employees.AddSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.AddSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
 	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLEmployeeStore) DeleteSkill(employeeId int64, skillId int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM EmployeeSkills WHERE employee_id=? AND skill_id = ?",
+func (s *MySQLEmployeeStore) DeleteSkill(ctx context.Context, employeeId int64, skillId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM EmployeeSkills WHERE employee_id=? AND skill_id = ?",
 		employeeId, skillId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.DeleteSkill", err, "employee_id", employeeId, "skill_id", skillId)
 	}
 	return result.RowsAffected()
 }
 
-func (s *MySQLEmployeeStore) UpdateSkill(employeeId int64, skillId int64, skillLevel int64) (int64, error) {
-	results, err := s.db.Exec("UPDATE EmployeeSkills SET skill_level=? WHERE employee_id=? AND skill_id=?",
-		skillLevel, employeeId, skillId)
+// UpdateSkill sets the current level of an employee skill and records it as a new assessment. Assessing the
+// same level again is recorded as well, it confirms the level at a later date.
+func (s *MySQLEmployeeStore) UpdateSkill(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.UpdateSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
 	}
-	return results.RowsAffected()
-}
+	defer tx.Rollback()
 
-func (s *MySQLEmployeeStore) AddProject(projectId int64, employeeId int64, projectRole string) (int64, error) {
-	result, err := s.db.Exec("INSERT INTO ProjectDetails (project_id, employee_id, employee_role) VALUES (?,?,?)",
-		projectId, employeeId, projectRole)
+	var current sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT skill_level FROM EmployeeSkills WHERE employee_id=? AND skill_id=? FOR UPDATE",
+		employeeId, empSkill.SkillId).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the employee does not have the skill, nothing to update
+		return 0, nil
+	}
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.UpdateSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
 	}
-	return result.RowsAffected()
+
+	results, err := updateEmployeeSkill(ctx, tx, employeeId, empSkill)
+	if err != nil {
+		return -1, queryError(ctx, "employees.UpdateSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.UpdateSkill", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	return results.RowsAffected()
 }
-func (s *MySQLEmployeeStore) UpdateProject(projectId int64, employeeId int64, projectRole string) (int64, error) {
-	result, err := s.db.Exec("UPDATE ProjectDetails SET employee_role=? WHERE project_id=? AND employee_id=?",
-		projectRole, projectId, employeeId)
+
+// updateEmployeeSkill sets the level of an employee skill and records it as an assessment
+func updateEmployeeSkill(ctx context.Context, tx *sql.Tx, employeeId int64, empSkill instances.EmployeeSkill) (sql.Result, error) {
+	result, err := tx.ExecContext(ctx, "UPDATE EmployeeSkills SET skill_level=? WHERE employee_id=? AND skill_id=?",
+		empSkill.SkillLevel, employeeId, empSkill.SkillId)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
-	return result.RowsAffected()
+	return result, insertAssessment(ctx, tx, employeeId, empSkill)
 }
-func (s *MySQLEmployeeStore) DeleteProject(projectId int64, employeeId int64) (int64, error) {
-	result, err := s.db.Exec("DELETE FROM ProjectDetails WHERE project_id=? AND employee_id=?",
+
+func (s *MySQLEmployeeStore) DeleteProject(ctx context.Context, projectId int64, employeeId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ProjectDetails WHERE project_id=? AND employee_id=?",
 		projectId, employeeId)
 	if err != nil {
-		return -1, err
+		return -1, queryError(ctx, "employees.DeleteProject", err, "employee_id", employeeId, "project_id", projectId)
 	}
 	return result.RowsAffected()
 }
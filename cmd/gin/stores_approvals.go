@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// errChangePending is returned when an employee requests a change of a skill that already has a pending one
+	errChangePending = errors.New("a change of this skill is already waiting for approval")
+	// errChangeReviewed is returned when a request that is no longer pending is reviewed
+	errChangeReviewed = errors.New("the change request has already been reviewed")
+	// errNotApprover is returned when the reviewer may not review the request
+	errNotApprover = errors.New("the reviewer neither owns the skill nor manages the employee")
+	// errOwnChange is returned when employees review their own request
+	errOwnChange = errors.New("employees cannot review their own skill changes")
+	// errAmbiguousCaller is returned when the authenticated principal is the email of several employees
+	errAmbiguousCaller = errors.New("several employees have the email of the caller")
+)
+
+// skillChangeStore keeps the self-assessed skill levels that wait for approval
+type skillChangeStore interface {
+	// Request records a pending change of an employee skill, EmployeeSkills stays unchanged
+	Request(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error)
+	Get(ctx context.Context, requestId int64) (instances.SkillChangeRequest, error)
+	List(ctx context.Context, filter instances.SkillChangeFilter) ([]instances.SkillChangeRequest, error)
+	// Review approves or rejects a pending request. An approved level is written to EmployeeSkills and recorded
+	// as an assessment.
+	Review(ctx context.Context, requestId int64, review instances.SkillReview, approve bool) (instances.SkillChangeRequest, error)
+	Owners(ctx context.Context, skillId int64) ([]instances.SkillOwner, error)
+	AddOwner(ctx context.Context, skillId int64, employeeId int64) (int64, error)
+	DeleteOwner(ctx context.Context, skillId int64, employeeId int64) (int64, error)
+	// Caller returns the id of the employee whose email is the authenticated principal, sql.ErrNoRows if no
+	// employee has it
+	Caller(ctx context.Context, principal string) (int64, error)
+}
+
+type MySQLSkillChangeStore struct {
+	db *sql.DB
+}
+
+func NewSkillChangeStore(cfg mysql.Config) (*MySQLSkillChangeStore, error) {
+	db, err := openDB(cfg, "skillChanges")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLSkillChangeStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLSkillChangeStore) Close() error {
+	return s.db.Close()
+}
+
+const changeRequestColumns = "request_id, employee_id, skill_id, skill_level, previous_level, note, status, " +
+	"requested_at, reviewer_id, review_comment, reviewed_at"
+
+func scanChangeRequest(row rowScanner) (instances.SkillChangeRequest, error) {
+	var req instances.SkillChangeRequest
+	var previous, reviewer sql.NullInt64
+	var note, comment sql.NullString
+	var reviewedAt sql.NullTime
+	if err := row.Scan(&req.RequestId, &req.EmployeeId, &req.SkillId, &req.SkillLevel, &previous, &note, &req.Status,
+		&req.RequestedAt, &reviewer, &comment, &reviewedAt); err != nil {
+		return instances.SkillChangeRequest{}, err
+	}
+	req.PreviousLevel = nullInt64Ptr(previous)
+	req.ReviewerId = nullInt64Ptr(reviewer)
+	req.Note = note.String
+	req.ReviewComment = comment.String
+	if reviewedAt.Valid {
+		req.ReviewedAt = &reviewedAt.Time
+	}
+	return req, nil
+}
+
+func queryChangeRequests(ctx context.Context, q queryer, where string, args ...any) ([]instances.SkillChangeRequest, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+changeRequestColumns+" FROM SkillChangeRequests WHERE "+where+
+		" ORDER BY requested_at, request_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []instances.SkillChangeRequest{}
+	for rows.Next() {
+		req, err := scanChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+func (s *MySQLSkillChangeStore) Request(ctx context.Context, employeeId int64, empSkill instances.EmployeeSkill) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, queryError(ctx, "skillChanges.Request", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	defer tx.Rollback()
+
+	var current sql.NullInt64
+	err = tx.QueryRowContext(ctx, "SELECT skill_level FROM EmployeeSkills WHERE employee_id=? AND skill_id=? FOR UPDATE",
+		employeeId, empSkill.SkillId).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return -1, queryError(ctx, "skillChanges.Request", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	var pending int64
+	err = tx.QueryRowContext(ctx, "SELECT request_id FROM SkillChangeRequests WHERE employee_id=? AND skill_id=? "+
+		"AND status=? LIMIT 1 FOR UPDATE", employeeId, empSkill.SkillId, instances.ChangePending).Scan(&pending)
+	if err == nil {
+		return -1, errChangePending
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return -1, queryError(ctx, "skillChanges.Request", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+
+	result, err := tx.ExecContext(ctx, "INSERT INTO SkillChangeRequests (employee_id, skill_id, skill_level, previous_level, note) "+
+		"VALUES (?,?,?,?,?)", employeeId, empSkill.SkillId, empSkill.SkillLevel, current,
+		nullString(empSkill.Note))
+	if err != nil {
+		return -1, queryError(ctx, "skillChanges.Request", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "skillChanges.Request", err, "employee_id", employeeId, "skill_id", empSkill.SkillId)
+	}
+	return result.LastInsertId()
+}
+
+func (s *MySQLSkillChangeStore) Get(ctx context.Context, requestId int64) (instances.SkillChangeRequest, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+changeRequestColumns+" FROM SkillChangeRequests WHERE request_id = ?", requestId)
+	req, err := scanChangeRequest(row)
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Get", err, "request_id", requestId)
+	}
+	return req, nil
+}
+
+func (s *MySQLSkillChangeStore) List(ctx context.Context, filter instances.SkillChangeFilter) ([]instances.SkillChangeRequest, error) {
+	status := filter.Status
+	if status == "" {
+		status = instances.ChangePending
+	}
+	where := "status = ?"
+	args := []any{status}
+	if filter.EmployeeId != 0 {
+		where += " AND employee_id = ?"
+		args = append(args, filter.EmployeeId)
+	}
+	if filter.SkillId != 0 {
+		where += " AND skill_id = ?"
+		args = append(args, filter.SkillId)
+	}
+	requests, err := queryChangeRequests(ctx, s.db, where, args...)
+	if err != nil {
+		return nil, queryError(ctx, "skillChanges.List", err)
+	}
+	return requests, nil
+}
+
+func (s *MySQLSkillChangeStore) Review(ctx context.Context, requestId int64, review instances.SkillReview, approve bool) (instances.SkillChangeRequest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+	defer tx.Rollback()
+
+	req, err := scanChangeRequest(tx.QueryRowContext(ctx, "SELECT "+changeRequestColumns+
+		" FROM SkillChangeRequests WHERE request_id = ? FOR UPDATE", requestId))
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+	if req.Status != instances.ChangePending {
+		return instances.SkillChangeRequest{}, errChangeReviewed
+	}
+	if req.EmployeeId == review.ReviewerId {
+		return instances.SkillChangeRequest{}, errOwnChange
+	}
+	// skill owners and the employee's direct manager may review
+	var approver bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM SkillOwners WHERE skill_id = ? AND employee_id = ?) "+
+		"OR EXISTS (SELECT 1 FROM Employees WHERE employee_id = ? AND manager_id = ?)",
+		req.SkillId, review.ReviewerId, req.EmployeeId, review.ReviewerId).Scan(&approver)
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+	if !approver {
+		return instances.SkillChangeRequest{}, errNotApprover
+	}
+
+	status := instances.ChangeRejected
+	if approve {
+		status = instances.ChangeApproved
+		_, err := tx.ExecContext(ctx, "INSERT INTO EmployeeSkills (employee_id, skill_id, skill_level) VALUES (?,?,?) "+
+			"ON DUPLICATE KEY UPDATE skill_level = VALUES(skill_level)", req.EmployeeId, req.SkillId, req.SkillLevel)
+		if err != nil {
+			return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+		}
+		err = insertAssessment(ctx, tx, req.EmployeeId, instances.EmployeeSkill{
+			SkillId:    req.SkillId,
+			SkillLevel: req.SkillLevel,
+			Source:     instances.AssessmentSelf,
+			Note:       fmt.Sprintf("approved with change request %d", req.RequestId),
+		})
+		if err != nil {
+			return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+		}
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE SkillChangeRequests SET status=?, reviewer_id=?, review_comment=?, "+
+		"reviewed_at=CURRENT_TIMESTAMP WHERE request_id=?", status, review.ReviewerId,
+		nullString(review.Comment), requestId)
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+
+	req, err = scanChangeRequest(tx.QueryRowContext(ctx, "SELECT "+changeRequestColumns+
+		" FROM SkillChangeRequests WHERE request_id = ?", requestId))
+	if err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.SkillChangeRequest{}, queryError(ctx, "skillChanges.Review", err, "request_id", requestId)
+	}
+	return req, nil
+}
+
+func (s *MySQLSkillChangeStore) Owners(ctx context.Context, skillId int64) ([]instances.SkillOwner, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT employee_id FROM SkillOwners WHERE skill_id = ? ORDER BY employee_id", skillId)
+	if err != nil {
+		return nil, queryError(ctx, "skillChanges.Owners", err, "skill_id", skillId)
+	}
+	defer rows.Close()
+
+	owners := []instances.SkillOwner{}
+	for rows.Next() {
+		var owner instances.SkillOwner
+		if err := rows.Scan(&owner.EmployeeId); err != nil {
+			return nil, queryError(ctx, "skillChanges.Owners", err, "skill_id", skillId)
+		}
+		owners = append(owners, owner)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "skillChanges.Owners", err, "skill_id", skillId)
+	}
+	return owners, nil
+}
+
+func (s *MySQLSkillChangeStore) AddOwner(ctx context.Context, skillId int64, employeeId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO SkillOwners (skill_id, employee_id) VALUES (?,?)", skillId, employeeId)
+	if err != nil {
+		return -1, queryError(ctx, "skillChanges.AddOwner", err, "skill_id", skillId, "employee_id", employeeId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLSkillChangeStore) DeleteOwner(ctx context.Context, skillId int64, employeeId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM SkillOwners WHERE skill_id = ? AND employee_id = ?", skillId, employeeId)
+	if err != nil {
+		return -1, queryError(ctx, "skillChanges.DeleteOwner", err, "skill_id", skillId, "employee_id", employeeId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLSkillChangeStore) Caller(ctx context.Context, principal string) (int64, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT employee_id FROM Employees WHERE email = ? LIMIT 2", principal)
+	if err != nil {
+		return -1, queryError(ctx, "skillChanges.Caller", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return -1, queryError(ctx, "skillChanges.Caller", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return -1, queryError(ctx, "skillChanges.Caller", err)
+	}
+	switch len(ids) {
+	case 0:
+		return -1, sql.ErrNoRows
+	case 1:
+		return ids[0], nil
+	default:
+		// the caller cannot be told apart from another employee
+		return -1, errAmbiguousCaller
+	}
+}
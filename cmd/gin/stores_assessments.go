@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+)
+
+// insertAssessment records the level of empSkill as an assessment, it runs in the transaction changing the level.
+// A level without a source is recorded as of an unknown source.
+func insertAssessment(ctx context.Context, tx *sql.Tx, employeeId int64, empSkill instances.EmployeeSkill) error {
+	source := empSkill.Source
+	if source == "" {
+		source = instances.AssessmentUnknown
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO SkillAssessments (employee_id, skill_id, skill_level, source, assessor, note) "+
+		"VALUES (?,?,?,?,?,?)", employeeId, empSkill.SkillId, empSkill.SkillLevel, source,
+		nullString(empSkill.Assessor),
+		nullString(empSkill.Note))
+	return err
+}
+
+// SkillHistory returns every assessment and change request of an employee skill. It returns sql.ErrNoRows if the
+// skill was never assessed nor requested.
+func (s *MySQLEmployeeStore) SkillHistory(ctx context.Context, employeeId int64, skillId int64) (instances.SkillHistory, error) {
+	history := instances.SkillHistory{EmployeeId: employeeId, SkillId: skillId}
+	var current sql.NullInt64
+	row := s.db.QueryRowContext(ctx, "SELECT s.skill, e.skill_level FROM Skills AS s "+
+		"LEFT JOIN EmployeeSkills AS e ON e.skill_id = s.skill_id AND e.employee_id = ? WHERE s.skill_id = ?",
+		employeeId, skillId)
+	if err := row.Scan(&history.Skill, &current); err != nil {
+		return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	if current.Valid {
+		history.SkillLevel = &current.Int64
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT assessment_id, skill_level, source, assessor, note, assessed_at "+
+		"FROM SkillAssessments WHERE employee_id = ? AND skill_id = ? ORDER BY assessed_at, assessment_id",
+		employeeId, skillId)
+	if err != nil {
+		return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assessment instances.SkillAssessment
+		var assessor, note sql.NullString
+		if err := rows.Scan(&assessment.AssessmentId, &assessment.SkillLevel, &assessment.Source, &assessor, &note,
+			&assessment.AssessedAt); err != nil {
+			return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", err, "employee_id", employeeId, "skill_id", skillId)
+		}
+		assessment.Assessor = assessor.String
+		assessment.Note = note.String
+		history.Assessments = append(history.Assessments, assessment)
+	}
+	if err := rows.Err(); err != nil {
+		return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+
+	history.ChangeRequests, err = queryChangeRequests(ctx, s.db, "employee_id = ? AND skill_id = ?", employeeId, skillId)
+	if err != nil {
+		return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	if len(history.Assessments) == 0 && len(history.ChangeRequests) == 0 {
+		return instances.SkillHistory{}, queryError(ctx, "employees.SkillHistory", sql.ErrNoRows, "employee_id", employeeId, "skill_id", skillId)
+	}
+
+	if len(history.Assessments) > 0 {
+		first, latest := history.Assessments[0], history.Assessments[len(history.Assessments)-1]
+		history.LastAssessed = &latest.AssessedAt
+		history.Change = latest.SkillLevel - first.SkillLevel
+	}
+	return history, nil
+}
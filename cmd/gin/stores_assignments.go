@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"esmAPI/pkg/validation"
+	"time"
+)
+
+// errOverlappingStint is returned when two stints of an employee on the same project would overlap
+var errOverlappingStint = errors.New("the employee is already assigned to the project during this period")
+
+// Open ends of a stint are compared as the earliest and latest date MySQL knows
+const (
+	unknownStart = "1000-01-01"
+	openEnd      = "9999-12-31"
+)
+
+// today returns the current date of the server
+func today() instances.Date {
+	return instances.NewDate(time.Now().Date())
+}
+
+// assignmentCondition restricts the stints aliased b to those in the state status, relative to the database's date
+func assignmentCondition(status string) string {
+	switch status {
+	case instances.AssignmentCurrent:
+		return " AND (b.start_date IS NULL OR b.start_date <= CURDATE()) AND (b.end_date IS NULL OR b.end_date >= CURDATE())"
+	case instances.AssignmentPast:
+		return " AND b.end_date < CURDATE()"
+	case instances.AssignmentUpcoming:
+		return " AND b.start_date > CURDATE()"
+	default:
+		return ""
+	}
+}
+
+// queryAssignments returns the stints of an employee with their projects, oldest first
+func queryAssignments(ctx context.Context, q queryer, employeeId int64, filter instances.AssignmentFilter) ([]instances.ProjectFull, error) {
+	rows, err := q.QueryContext(ctx, "SELECT a.project_id, a.client_id, a.focus_area, a.description, a.isSecret, "+
+		"b.employee_role, b.assignment_id, b.start_date, b.end_date, b.allocation FROM Projects AS a "+
+		"INNER JOIN ProjectDetails AS b ON a.project_id = b.project_id WHERE b.employee_id = ?"+
+		assignmentCondition(filter.Projects)+" ORDER BY b.start_date, b.assignment_id", employeeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []instances.ProjectFull{}
+	for rows.Next() {
+		var p instances.ProjectFull
+		if err := rows.Scan(&p.Project.ProjectId, &p.Project.ClientId, &p.Project.FocusArea, &p.Project.Description,
+			&p.Project.IsSecret, &p.EmployeeRole, &p.AssignmentId, &p.StartDate, &p.EndDate, &p.Allocation); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// overlapsStint reports whether the period from start to end overlaps another stint of the employee on the project.
+// It locks the stints it looks at, so the check holds until the transaction ends.
+func overlapsStint(ctx context.Context, tx *sql.Tx, employeeId int64, ep instances.EmployeeProject, exclude int64) (bool, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, "SELECT assignment_id FROM ProjectDetails WHERE employee_id=? AND project_id=? "+
+		"AND assignment_id <> ? AND COALESCE(start_date, ?) <= COALESCE(?, ?) AND COALESCE(end_date, ?) >= COALESCE(?, ?) "+
+		"LIMIT 1 FOR UPDATE", employeeId, ep.ProjectId, exclude, unknownStart, ep.EndDate, openEnd, openEnd, ep.StartDate,
+		unknownStart).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// withDefaults fills the optional fields of a new stint
+func withDefaults(ep instances.EmployeeProject) instances.EmployeeProject {
+	if ep.StartDate == nil {
+		start := today()
+		ep.StartDate = &start
+	}
+	if ep.Allocation == 0 {
+		ep.Allocation = 100
+	}
+	return ep
+}
+
+// AddProject starts a stint of an employee on a project
+func (s *MySQLEmployeeStore) AddProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error) {
+	ep := withDefaults(empProject)
+	if err := validation.Period("start_date", *ep.StartDate, "end_date", ep.EndDate); err != nil {
+		return -1, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, queryError(ctx, "employees.AddProject", err, "employee_id", employeeId, "project_id", ep.ProjectId)
+	}
+	defer tx.Rollback()
+
+	overlaps, err := overlapsStint(ctx, tx, employeeId, ep, 0)
+	if err != nil {
+		return -1, queryError(ctx, "employees.AddProject", err, "employee_id", employeeId, "project_id", ep.ProjectId)
+	}
+	if overlaps {
+		return -1, errOverlappingStint
+	}
+	result, err := tx.ExecContext(ctx, "INSERT INTO ProjectDetails (project_id, employee_id, employee_role, start_date, "+
+		"end_date, allocation) VALUES (?,?,?,?,?,?)", ep.ProjectId, employeeId, ep.ProjectRole, ep.StartDate, ep.EndDate,
+		ep.Allocation)
+	if err != nil {
+		return -1, queryError(ctx, "employees.AddProject", err, "employee_id", employeeId, "project_id", ep.ProjectId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.AddProject", err, "employee_id", employeeId, "project_id", ep.ProjectId)
+	}
+	return result.RowsAffected()
+}
+
+// UpdateProject changes the role of an employee on a project. With a start date, the stint running on that date
+// ends the day before and a new stint with the new role starts, so the previous role stays on record. Without one,
+// the role of the current stint is corrected in place.
+func (s *MySQLEmployeeStore) UpdateProject(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error) {
+	if empProject.StartDate == nil {
+		result, err := s.db.ExecContext(ctx, "UPDATE ProjectDetails AS b SET b.employee_role=?, "+
+			"b.allocation=IF(? > 0, ?, b.allocation) WHERE b.project_id=? AND b.employee_id=?"+
+			assignmentCondition(instances.AssignmentCurrent), empProject.ProjectRole, empProject.Allocation,
+			empProject.Allocation, empProject.ProjectId, employeeId)
+		if err != nil {
+			return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+		}
+		return result.RowsAffected()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+	}
+	defer tx.Rollback()
+
+	change := *empProject.StartDate
+	var running instances.EmployeeProject
+	err = tx.QueryRowContext(ctx, "SELECT assignment_id, start_date, end_date, allocation FROM ProjectDetails "+
+		"WHERE employee_id=? AND project_id=? AND COALESCE(start_date, ?) <= ? AND COALESCE(end_date, ?) >= ? "+
+		"LIMIT 1 FOR UPDATE", employeeId, empProject.ProjectId, unknownStart, change, openEnd, change).
+		Scan(&running.AssignmentId, &running.StartDate, &running.EndDate, &running.Allocation)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the employee does not work on the project at that date
+		return 0, nil
+	}
+	if err != nil {
+		return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+	}
+	allocation := running.Allocation
+	if empProject.Allocation > 0 {
+		allocation = empProject.Allocation
+	}
+
+	var affected int64
+	if running.StartDate != nil && running.StartDate.Equal(change.Time) {
+		// the stint starts on the day of the change, there is no previous role to keep
+		result, err := tx.ExecContext(ctx, "UPDATE ProjectDetails SET employee_role=?, allocation=? WHERE assignment_id=?",
+			empProject.ProjectRole, allocation, running.AssignmentId)
+		if err != nil {
+			return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+		}
+		affected, _ = result.RowsAffected()
+	} else {
+		previousEnd := instances.Date{Time: change.AddDate(0, 0, -1)}
+		result, err := tx.ExecContext(ctx, "UPDATE ProjectDetails SET end_date=? WHERE assignment_id=?",
+			previousEnd, running.AssignmentId)
+		if err != nil {
+			return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+		}
+		closed, _ := result.RowsAffected()
+		result, err = tx.ExecContext(ctx, "INSERT INTO ProjectDetails (project_id, employee_id, employee_role, start_date, "+
+			"end_date, allocation) VALUES (?,?,?,?,?,?)", empProject.ProjectId, employeeId, empProject.ProjectRole, change,
+			running.EndDate, allocation)
+		if err != nil {
+			return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+		}
+		started, _ := result.RowsAffected()
+		affected = closed + started
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.UpdateProject", err, "employee_id", employeeId, "project_id", empProject.ProjectId)
+	}
+	return affected, nil
+}
+
+// Projects returns the stints of an employee
+func (s *MySQLEmployeeStore) Projects(ctx context.Context, employeeId int64, filter instances.AssignmentFilter) ([]instances.ProjectFull, error) {
+	projects, err := queryAssignments(ctx, s.db, employeeId, filter)
+	if err != nil {
+		return nil, queryError(ctx, "employees.Projects", err, "employee_id", employeeId)
+	}
+	return projects, nil
+}
+
+// UpdateAssignment replaces a stint of the employee, fields left empty keep their value. An ended stint stays
+// ended, PatchAssignment makes it open-ended again.
+func (s *MySQLEmployeeStore) UpdateAssignment(ctx context.Context, employeeId int64, empProject instances.EmployeeProject) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, queryError(ctx, "employees.UpdateAssignment", err, "assignment_id", empProject.AssignmentId)
+	}
+	defer tx.Rollback()
+
+	var current instances.EmployeeProject
+	var role sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT project_id, employee_role, start_date, end_date, allocation FROM ProjectDetails "+
+		"WHERE assignment_id=? AND employee_id=? FOR UPDATE", empProject.AssignmentId, employeeId).
+		Scan(&current.ProjectId, &role, &current.StartDate, &current.EndDate, &current.Allocation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return -1, queryError(ctx, "employees.UpdateAssignment", err, "assignment_id", empProject.AssignmentId)
+	}
+
+	current.ProjectRole = role.String
+	result, err := updateStint(ctx, tx, employeeId, keepStint(current, empProject))
+	if err != nil {
+		return -1, stintError(ctx, "employees.UpdateAssignment", err, "assignment_id", empProject.AssignmentId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "employees.UpdateAssignment", err, "assignment_id", empProject.AssignmentId)
+	}
+	return result.RowsAffected()
+}
+
+// keepStint fills the fields left empty in ep with those of the current stint
+func keepStint(current, ep instances.EmployeeProject) instances.EmployeeProject {
+	if ep.ProjectId == 0 {
+		ep.ProjectId = current.ProjectId
+	}
+	if ep.ProjectRole == "" {
+		ep.ProjectRole = current.ProjectRole
+	}
+	if ep.StartDate == nil {
+		ep.StartDate = current.StartDate
+	}
+	if ep.EndDate == nil {
+		ep.EndDate = current.EndDate
+	}
+	if ep.Allocation == 0 {
+		ep.Allocation = current.Allocation
+	}
+	return ep
+}
+
+// updateStint writes ep over the stint of the employee with the same assignment id. Periods ending before they
+// start fail with validation errors, overlapping another stint on the project with errOverlappingStint.
+func updateStint(ctx context.Context, tx *sql.Tx, employeeId int64, ep instances.EmployeeProject) (sql.Result, error) {
+	if ep.StartDate != nil {
+		if err := validation.Period("start_date", *ep.StartDate, "end_date", ep.EndDate); err != nil {
+			return nil, err
+		}
+	}
+	overlaps, err := overlapsStint(ctx, tx, employeeId, ep, ep.AssignmentId)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, errOverlappingStint
+	}
+	return tx.ExecContext(ctx, "UPDATE ProjectDetails SET project_id=?, employee_role=?, start_date=?, end_date=?, "+
+		"allocation=? WHERE assignment_id=?", ep.ProjectId, ep.ProjectRole, ep.StartDate, ep.EndDate, ep.Allocation,
+		ep.AssignmentId)
+}
+
+// stintError logs err unless it is an invalid or overlapping stint, which are not failures of the store
+func stintError(ctx context.Context, op string, err error, attrs ...any) error {
+	var verrs validation.Errors
+	if errors.As(err, &verrs) || errors.Is(err, errOverlappingStint) {
+		return err
+	}
+	return queryError(ctx, op, err, attrs...)
+}
+
+func (s *MySQLEmployeeStore) DeleteAssignment(ctx context.Context, employeeId int64, assignmentId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ProjectDetails WHERE assignment_id=? AND employee_id=?",
+		assignmentId, employeeId)
+	if err != nil {
+		return -1, queryError(ctx, "employees.DeleteAssignment", err, "assignment_id", assignmentId)
+	}
+	return result.RowsAffected()
+}
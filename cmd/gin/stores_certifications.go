@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/go-sql-driver/mysql"
+)
+
+type certificationStore interface {
+	Add(ctx context.Context, cert instances.Certification) (int64, error)
+	Get(ctx context.Context, certificationId int64) (instances.Certification, error)
+	List(ctx context.Context, filter instances.CertificationFilter) ([]instances.Certification, error)
+	Update(ctx context.Context, currId int64, cert instances.Certification) (int64, error)
+	Delete(ctx context.Context, certificationId int64) (int64, error)
+	// Expiring lists the certifications expiring within the filter's days, soonest first
+	Expiring(ctx context.Context, filter instances.ExpiringFilter) ([]instances.Certification, error)
+	// Headcount counts the employees holding each certification that has not expired
+	Headcount(ctx context.Context) ([]instances.CertifiedHeadcount, error)
+}
+
+type MySQLCertificationStore struct {
+	db *sql.DB
+}
+
+func NewCertificationStore(cfg mysql.Config) (*MySQLCertificationStore, error) {
+	db, err := openDB(cfg, "certifications")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLCertificationStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLCertificationStore) Close() error {
+	return s.db.Close()
+}
+
+// certificationColumns are read by scanCertification, expiry is judged by the database's clock
+const certificationColumns = "certification_id, employee_id, skill_id, name, issuer, credential_id, issued_on, " +
+	"expires_on, evidence, COALESCE(expires_on < CURDATE(), FALSE)"
+
+func scanCertification(row rowScanner) (instances.Certification, error) {
+	var cert instances.Certification
+	var skillId sql.NullInt64
+	var credentialId, evidence sql.NullString
+	var expiresOn *instances.Date
+	if err := row.Scan(&cert.CertificationId, &cert.EmployeeId, &skillId, &cert.Name, &cert.Issuer, &credentialId,
+		&cert.IssuedOn, &expiresOn, &evidence, &cert.Expired); err != nil {
+		return instances.Certification{}, err
+	}
+	cert.SkillId = nullInt64Ptr(skillId)
+	cert.CredentialId = credentialId.String
+	cert.ExpiresOn = expiresOn
+	cert.Evidence = evidence.String
+	return cert, nil
+}
+
+func queryCertifications(ctx context.Context, q queryer, where string, args ...any) ([]instances.Certification, error) {
+	rows, err := q.QueryContext(ctx, "SELECT "+certificationColumns+" FROM Certifications WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []instances.Certification{}
+	for rows.Next() {
+		cert, err := scanCertification(rows)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, rows.Err()
+}
+
+func (s *MySQLCertificationStore) Add(ctx context.Context, cert instances.Certification) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO Certifications (employee_id, skill_id, name, issuer, credential_id, "+
+		"issued_on, expires_on, evidence) VALUES (?,?,?,?,?,?,?,?)", cert.EmployeeId, cert.SkillId, cert.Name, cert.Issuer,
+		nullString(cert.CredentialId), cert.IssuedOn, cert.ExpiresOn, nullString(cert.Evidence))
+	if err != nil {
+		return -1, queryError(ctx, "certifications.Add", err, "employee_id", cert.EmployeeId)
+	}
+	return result.LastInsertId()
+}
+
+func (s *MySQLCertificationStore) Get(ctx context.Context, certificationId int64) (instances.Certification, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+certificationColumns+" FROM Certifications WHERE certification_id = ?",
+		certificationId)
+	cert, err := scanCertification(row)
+	if err != nil {
+		return instances.Certification{}, queryError(ctx, "certifications.Get", err, "certification_id", certificationId)
+	}
+	return cert, nil
+}
+
+func (s *MySQLCertificationStore) List(ctx context.Context, filter instances.CertificationFilter) ([]instances.Certification, error) {
+	where := "TRUE"
+	var args []any
+	if filter.EmployeeId != 0 {
+		where += " AND employee_id = ?"
+		args = append(args, filter.EmployeeId)
+	}
+	if filter.SkillId != 0 {
+		where += " AND skill_id = ?"
+		args = append(args, filter.SkillId)
+	}
+	if filter.Name != "" {
+		where += " AND name = ?"
+		args = append(args, filter.Name)
+	}
+	certs, err := queryCertifications(ctx, s.db, where+" ORDER BY employee_id, issued_on", args...)
+	if err != nil {
+		return nil, queryError(ctx, "certifications.List", err)
+	}
+	return certs, nil
+}
+
+func (s *MySQLCertificationStore) Update(ctx context.Context, currId int64, cert instances.Certification) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE Certifications SET employee_id=?, skill_id=?, name=?, issuer=?, "+
+		"credential_id=?, issued_on=?, expires_on=?, evidence=? WHERE certification_id=?", cert.EmployeeId, cert.SkillId,
+		cert.Name, cert.Issuer, nullString(cert.CredentialId), cert.IssuedOn, cert.ExpiresOn, nullString(cert.Evidence), currId)
+	if err != nil {
+		return -1, queryError(ctx, "certifications.Update", err, "certification_id", currId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLCertificationStore) Delete(ctx context.Context, certificationId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Certifications WHERE certification_id = ?", certificationId)
+	if err != nil {
+		return -1, queryError(ctx, "certifications.Delete", err, "certification_id", certificationId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLCertificationStore) Expiring(ctx context.Context, filter instances.ExpiringFilter) ([]instances.Certification, error) {
+	where := "expires_on IS NOT NULL AND expires_on <= CURDATE() + INTERVAL ? DAY"
+	if !filter.IncludeExpired {
+		where += " AND expires_on >= CURDATE()"
+	}
+	certs, err := queryCertifications(ctx, s.db, where+" ORDER BY expires_on, employee_id", filter.Days)
+	if err != nil {
+		return nil, queryError(ctx, "certifications.Expiring", err, "days", filter.Days)
+	}
+	return certs, nil
+}
+
+func (s *MySQLCertificationStore) Headcount(ctx context.Context) ([]instances.CertifiedHeadcount, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name, issuer, COUNT(DISTINCT employee_id) FROM Certifications "+
+		"WHERE expires_on IS NULL OR expires_on >= CURDATE() GROUP BY name, issuer ORDER BY name, issuer")
+	if err != nil {
+		return nil, queryError(ctx, "certifications.Headcount", err)
+	}
+	defer rows.Close()
+
+	counts := []instances.CertifiedHeadcount{}
+	for rows.Next() {
+		var count instances.CertifiedHeadcount
+		if err := rows.Scan(&count.Name, &count.Issuer, &count.Employees); err != nil {
+			return nil, queryError(ctx, "certifications.Headcount", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "certifications.Headcount", err)
+	}
+	return counts, nil
+}
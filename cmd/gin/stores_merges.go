@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mergeStore merges duplicate records. A merge repoints every reference to the merged record, the source, to the
+// record kept, the target, deletes the source and records the merge in the audit trail, all in one transaction.
+type mergeStore interface {
+	MergeSkills(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error)
+	// MergeEmployees fails with errManagerCycle if the reports of source would end up above target
+	MergeEmployees(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error)
+	MergeClients(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error)
+	// List returns the audit trail, the oldest merge first
+	List(ctx context.Context, filter instances.MergeFilter) ([]instances.Merge, error)
+}
+
+type MySQLMergeStore struct {
+	db *sql.DB
+}
+
+func NewMergeStore(cfg mysql.Config) (*MySQLMergeStore, error) {
+	db, err := openDB(cfg, "merges")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLMergeStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLMergeStore) Close() error {
+	return s.db.Close()
+}
+
+// conflictValues are the values a row both records have keeps under each policy. t is the row of the target, s the
+// row of the source and %[1]s the column. keep_target keeps the row of the target as it is.
+var conflictValues = map[string]string{
+	instances.MergeKeepMax:    "GREATEST(COALESCE(t.%[1]s, s.%[1]s), COALESCE(s.%[1]s, t.%[1]s))",
+	instances.MergeKeepSource: "COALESCE(s.%[1]s, t.%[1]s)",
+}
+
+// mergeTx is a merge in progress. It counts the references it moved by table and column and the conflicts it
+// resolved with policy.
+type mergeTx struct {
+	ctx       context.Context
+	tx        *sql.Tx
+	source    int64
+	target    int64
+	policy    string
+	moved     map[string]int64
+	conflicts int64
+}
+
+// move repoints column of table from the source to the target
+func (m *mergeTx) move(table string, column string) error {
+	result, err := m.tx.ExecContext(m.ctx, fmt.Sprintf("UPDATE %[1]s SET %[2]s = ? WHERE %[2]s = ?", table, column),
+		m.target, m.source)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		m.moved[table+"."+column] += n
+	}
+	return nil
+}
+
+// moveUnique repoints column of table like move, where column and the match columns are unique together. Rows of the
+// source matching a row of the target are conflicts: the policy picks the value to keep in the target's row and the
+// source's row is dropped. value may be empty for tables with nothing to choose from.
+func (m *mergeTx) moveUnique(table string, column string, match []string, value string) error {
+	on := make([]string, len(match))
+	for i, c := range match {
+		on[i] = "t." + c + " = s." + c
+	}
+	join := fmt.Sprintf("%[1]s t JOIN %[1]s s ON %[2]s", table, strings.Join(on, " AND "))
+	if expr, ok := conflictValues[m.policy]; ok && value != "" {
+		_, err := m.tx.ExecContext(m.ctx, fmt.Sprintf("UPDATE %s SET t.%s = %s WHERE t.%s = ? AND s.%s = ?",
+			join, value, fmt.Sprintf(expr, value), column, column), m.target, m.source)
+		if err != nil {
+			return err
+		}
+	}
+	result, err := m.tx.ExecContext(m.ctx, fmt.Sprintf("DELETE s FROM %s WHERE t.%s = ? AND s.%s = ?",
+		join, column, column), m.target, m.source)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	m.conflicts += n
+	return m.move(table, column)
+}
+
+// resolveOverlaps resolves the overlapping stints of the source and the target under the policy before the stints of
+// the source are moved. Every stint deleted counts as a conflict.
+func (m *mergeTx) resolveOverlaps() error {
+	rows, err := m.tx.QueryContext(m.ctx, "SELECT assignment_id, employee_id, project_id, employee_role, start_date, "+
+		"end_date, allocation FROM ProjectDetails WHERE employee_id IN (?, ?) FOR UPDATE", m.source, m.target)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var target, source []instances.EmployeeProject
+	for rows.Next() {
+		var ep instances.EmployeeProject
+		var employeeId int64
+		var role sql.NullString
+		if err := rows.Scan(&ep.AssignmentId, &employeeId, &ep.ProjectId, &role, &ep.StartDate, &ep.EndDate,
+			&ep.Allocation); err != nil {
+			return err
+		}
+		ep.ProjectRole = role.String
+		if employeeId == m.target {
+			target = append(target, ep)
+		} else {
+			source = append(source, ep)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	update, drop := resolveStints(target, source, m.policy)
+	for _, id := range drop {
+		if _, err := m.tx.ExecContext(m.ctx, "DELETE FROM ProjectDetails WHERE assignment_id = ?", id); err != nil {
+			return err
+		}
+	}
+	for _, ep := range update {
+		_, err := m.tx.ExecContext(m.ctx, "UPDATE ProjectDetails SET start_date = ?, end_date = ?, allocation = ? "+
+			"WHERE assignment_id = ?", ep.StartDate, ep.EndDate, ep.Allocation, ep.AssignmentId)
+		if err != nil {
+			return err
+		}
+	}
+	m.conflicts += int64(len(drop))
+	return nil
+}
+
+// startsBefore reports whether the stint a starts before b, stints without a start come first
+func startsBefore(a instances.EmployeeProject, b instances.EmployeeProject) bool {
+	if a.StartDate == nil || b.StartDate == nil {
+		return a.StartDate == nil && b.StartDate != nil
+	}
+	return a.StartDate.Before(b.StartDate.Time)
+}
+
+// endsAfter reports whether the stint a ends after b, open-ended stints come last
+func endsAfter(a instances.EmployeeProject, b instances.EmployeeProject) bool {
+	if a.EndDate == nil || b.EndDate == nil {
+		return a.EndDate == nil && b.EndDate != nil
+	}
+	return a.EndDate.After(b.EndDate.Time)
+}
+
+// stintsOverlap reports whether two stints on the same project share a day, like overlapsStint
+func stintsOverlap(a instances.EmployeeProject, b instances.EmployeeProject) bool {
+	if a.ProjectId != b.ProjectId {
+		return false
+	}
+	startsAfterEnd := func(x, y instances.EmployeeProject) bool {
+		return x.StartDate != nil && y.EndDate != nil && x.StartDate.After(y.EndDate.Time)
+	}
+	return !startsAfterEnd(a, b) && !startsAfterEnd(b, a)
+}
+
+// resolveStints resolves the overlaps between the stints of the target and the source of a merge, so that the merged
+// employee has no overlapping stints on a project. keep_target drops the stints of the source overlapping one of the
+// target, keep_source the other way round. keep_max joins overlapping stints into the earliest stint of the target,
+// which then spans all of them with the higher allocation. It returns the stints to write and the ids of those to
+// delete.
+func resolveStints(target []instances.EmployeeProject, source []instances.EmployeeProject, policy string) ([]instances.EmployeeProject, []int64) {
+	var drop []int64
+	switch policy {
+	case instances.MergeKeepTarget, instances.MergeKeepSource:
+		kept, dropped := target, source
+		if policy == instances.MergeKeepSource {
+			kept, dropped = source, target
+		}
+		for _, d := range dropped {
+			for _, k := range kept {
+				if stintsOverlap(d, k) {
+					drop = append(drop, d.AssignmentId)
+					break
+				}
+			}
+		}
+		return nil, drop
+	}
+
+	fromTarget := make(map[int64]bool, len(target))
+	for _, ep := range target {
+		fromTarget[ep.AssignmentId] = true
+	}
+	all := append(append([]instances.EmployeeProject{}, target...), source...)
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].ProjectId != all[j].ProjectId {
+			return all[i].ProjectId < all[j].ProjectId
+		}
+		return startsBefore(all[i], all[j])
+	})
+	var update []instances.EmployeeProject
+	// each group is a run of stints overlapping the ones before, the target's stints never overlap each other
+	for i := 0; i < len(all); {
+		group, hull := []instances.EmployeeProject{all[i]}, all[i]
+		for i++; i < len(all) && stintsOverlap(hull, all[i]); i++ {
+			group = append(group, all[i])
+			if endsAfter(all[i], hull) {
+				hull.EndDate = all[i].EndDate
+			}
+			hull.Allocation = max(hull.Allocation, all[i].Allocation)
+		}
+		if len(group) == 1 {
+			continue
+		}
+		keep := -1
+		for j, ep := range group {
+			if fromTarget[ep.AssignmentId] {
+				keep = j
+				break
+			}
+		}
+		joined := group[keep]
+		joined.StartDate, joined.EndDate, joined.Allocation = hull.StartDate, hull.EndDate, hull.Allocation
+		update = append(update, joined)
+		for j, ep := range group {
+			if j != keep {
+				drop = append(drop, ep.AssignmentId)
+			}
+		}
+	}
+	return update, drop
+}
+
+// merge runs a merge of entity: snapshot locks and reads the source and checks that the target exists, steps
+// repoints the references, then the source is deleted from table and the merge recorded.
+func (s *MySQLMergeStore) merge(ctx context.Context, op string, entity string, table string, idColumn string,
+	source int64, target int64, req instances.MergeRequest,
+	snapshot func(m *mergeTx) (any, error), steps func(m *mergeTx) error) (instances.Merge, error) {
+	merge := instances.Merge{
+		Entity:   entity,
+		SourceId: source,
+		TargetId: target,
+		Policy:   req.Policy,
+		MergedBy: req.MergedBy,
+		Note:     req.Note,
+		MergedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if merge.Policy == "" {
+		merge.Policy = instances.MergeKeepMax
+	}
+	fail := func(err error) (instances.Merge, error) {
+		return instances.Merge{}, queryError(ctx, op, err, "source_id", source, "target_id", target)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback()
+	m := &mergeTx{ctx: ctx, tx: tx, source: source, target: target, policy: merge.Policy, moved: map[string]int64{}}
+
+	record, err := snapshot(m)
+	if err != nil {
+		return fail(err)
+	}
+	if merge.Source, err = json.Marshal(record); err != nil {
+		return fail(err)
+	}
+	if err := steps(m); err != nil {
+		if errors.Is(err, errManagerCycle) {
+			return instances.Merge{}, err
+		}
+		return fail(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE "+idColumn+" = ?", source); err != nil {
+		return fail(err)
+	}
+	merge.Moved, merge.Conflicts = m.moved, m.conflicts
+	moved, err := json.Marshal(merge.Moved)
+	if err != nil {
+		return fail(err)
+	}
+	result, err := tx.ExecContext(ctx, "INSERT INTO Merges (entity, source_id, target_id, policy, merged_by, note, "+
+		"source, moved, conflicts, merged_at) VALUES (?,?,?,?,?,?,?,?,?,?)", merge.Entity, source, target, merge.Policy,
+		merge.MergedBy, merge.Note, string(merge.Source), string(moved), merge.Conflicts, merge.MergedAt)
+	if err != nil {
+		return fail(err)
+	}
+	if merge.MergeId, err = result.LastInsertId(); err != nil {
+		return fail(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fail(err)
+	}
+	return merge, nil
+}
+
+// lockRow fails with sql.ErrNoRows unless the row id of table exists, and locks it
+func lockRow(m *mergeTx, table string, idColumn string, id int64) error {
+	var found int
+	return m.tx.QueryRowContext(m.ctx, "SELECT 1 FROM "+table+" WHERE "+idColumn+" = ? FOR UPDATE", id).Scan(&found)
+}
+
+// MergeSkills also keeps the name of source as an alias of target, so the old name still finds it
+func (s *MySQLMergeStore) MergeSkills(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	var merged, kept instances.Skill
+	snapshot := func(m *mergeTx) (any, error) {
+		var err error
+		query := "SELECT skill_id, skill_class, skill, category_id, deprecated, replaced_by FROM Skills " +
+			"WHERE skill_id = ? FOR UPDATE"
+		if merged, err = scanSkill(m.tx.QueryRowContext(ctx, query, source)); err != nil {
+			return nil, err
+		}
+		if kept, err = scanSkill(m.tx.QueryRowContext(ctx, query, target)); err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+	steps := func(m *mergeTx) error {
+		// a target deprecated in favour of the source is the skill to use from now on
+		if kept.ReplacedBy != nil && *kept.ReplacedBy == source {
+			_, err := m.tx.ExecContext(ctx, "UPDATE Skills SET deprecated = FALSE, replaced_by = NULL WHERE skill_id = ?",
+				target)
+			if err != nil {
+				return err
+			}
+		}
+		if err := m.moveUnique("EmployeeSkills", "skill_id", []string{"employee_id"}, "skill_level"); err != nil {
+			return err
+		}
+		if err := m.moveUnique("ProjectRequirements", "skill_id", []string{"project_id", "min_level"}, "headcount"); err != nil {
+			return err
+		}
+		if err := m.moveUnique("SkillOwners", "skill_id", []string{"employee_id"}, ""); err != nil {
+			return err
+		}
+		for _, table := range []string{"SkillAssessments", "SkillChangeRequests", "Certifications", "SkillAliases"} {
+			if err := m.move(table, "skill_id"); err != nil {
+				return err
+			}
+		}
+		if err := m.move("Skills", "replaced_by"); err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(merged.Skill), strings.TrimSpace(kept.Skill)) {
+			_, err := m.tx.ExecContext(ctx, "INSERT IGNORE INTO SkillAliases (alias, skill_id) VALUES (?, ?)",
+				merged.Skill, target)
+			return err
+		}
+		return nil
+	}
+	return s.merge(ctx, "merges.MergeSkills", instances.MergeSkill, "Skills", "skill_id", source, target, req,
+		snapshot, steps)
+}
+
+// MergeEmployees moves the reports of source to target. If target reported to source, they report to the manager of
+// source instead. Overlapping stints of both on a project are resolved under the policy.
+func (s *MySQLMergeStore) MergeEmployees(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	var employees []instances.Employee
+	snapshot := func(m *mergeTx) (any, error) {
+		var err error
+		// lock the reporting lines, so a concurrent change cannot create a cycle with the merge
+		if employees, err = lockReportingLines(ctx, m.tx, source, target); err != nil {
+			return nil, err
+		}
+		var merged *instances.Employee
+		found := false
+		for i, emp := range employees {
+			switch emp.EmployeeId {
+			case source:
+				merged = &employees[i]
+			case target:
+				found = true
+			}
+		}
+		if merged == nil || !found {
+			return nil, sql.ErrNoRows
+		}
+		return *merged, nil
+	}
+	steps := func(m *mergeTx) error {
+		manager, err := mergedManager(employees, source, target)
+		if err != nil {
+			return err
+		}
+		if _, err := m.tx.ExecContext(ctx, "UPDATE Employees SET manager_id = ? WHERE employee_id = ?", manager, target); err != nil {
+			return err
+		}
+		if err := m.move("Employees", "manager_id"); err != nil {
+			return err
+		}
+		if err := m.moveUnique("EmployeeSkills", "employee_id", []string{"skill_id"}, "skill_level"); err != nil {
+			return err
+		}
+		if err := m.moveUnique("SkillOwners", "employee_id", []string{"skill_id"}, ""); err != nil {
+			return err
+		}
+		if err := m.resolveOverlaps(); err != nil {
+			return err
+		}
+		for _, table := range []string{"ProjectDetails", "SkillAssessments", "SkillChangeRequests", "Certifications"} {
+			if err := m.move(table, "employee_id"); err != nil {
+				return err
+			}
+		}
+		return m.move("SkillChangeRequests", "reviewer_id")
+	}
+	return s.merge(ctx, "merges.MergeEmployees", instances.MergeEmployee, "Employees", "employee_id", source, target,
+		req, snapshot, steps)
+}
+
+func (s *MySQLMergeStore) MergeClients(ctx context.Context, source int64, target int64, req instances.MergeRequest) (instances.Merge, error) {
+	snapshot := func(m *mergeTx) (any, error) {
+		var client instances.Client
+		var description sql.NullString
+		err := m.tx.QueryRowContext(ctx, "SELECT id, name, description FROM Clients WHERE id = ? FOR UPDATE", source).
+			Scan(&client.ID, &client.Name, &description)
+		if err != nil {
+			return nil, err
+		}
+		client.Description = description.String
+		return client, lockRow(m, "Clients", "id", target)
+	}
+	steps := func(m *mergeTx) error {
+		return m.move("Projects", "client_id")
+	}
+	return s.merge(ctx, "merges.MergeClients", instances.MergeClient, "Clients", "id", source, target, req,
+		snapshot, steps)
+}
+
+func (s *MySQLMergeStore) List(ctx context.Context, filter instances.MergeFilter) ([]instances.Merge, error) {
+	where, args := []string{"TRUE"}, []any{}
+	if filter.Entity != "" {
+		where, args = append(where, "entity = ?"), append(args, filter.Entity)
+	}
+	if filter.Id != 0 {
+		where, args = append(where, "(source_id = ? OR target_id = ?)"), append(args, filter.Id, filter.Id)
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT merge_id, entity, source_id, target_id, policy, merged_by, note, "+
+		"source, moved, conflicts, merged_at FROM Merges WHERE "+strings.Join(where, " AND ")+
+		" ORDER BY merged_at, merge_id", args...)
+	if err != nil {
+		return nil, queryError(ctx, "merges.List", err)
+	}
+	defer rows.Close()
+
+	merges := []instances.Merge{}
+	for rows.Next() {
+		var merge instances.Merge
+		var note sql.NullString
+		var source, moved []byte
+		if err := rows.Scan(&merge.MergeId, &merge.Entity, &merge.SourceId, &merge.TargetId, &merge.Policy,
+			&merge.MergedBy, &note, &source, &moved, &merge.Conflicts, &merge.MergedAt); err != nil {
+			return nil, queryError(ctx, "merges.List", err)
+		}
+		merge.Note = note.String
+		merge.Source = json.RawMessage(source)
+		if err := json.Unmarshal(moved, &merge.Moved); err != nil {
+			return nil, queryError(ctx, "merges.List", err, "merge_id", merge.MergeId)
+		}
+		merges = append(merges, merge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "merges.List", err)
+	}
+	return merges, nil
+}
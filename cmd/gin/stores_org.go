@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/go-sql-driver/mysql"
+)
+
+// errManagerCycle is returned when an employee would end up reporting to themselves
+var errManagerCycle = errors.New("the employee cannot report to themselves or to one of their reports")
+
+type departmentStore interface {
+	Add(ctx context.Context, dept instances.Department) (int64, error)
+	Get(ctx context.Context, departmentId int64) (instances.Department, error)
+	List(ctx context.Context) ([]instances.Department, error)
+	Update(ctx context.Context, currId int64, dept instances.Department) (int64, error)
+	// Delete removes a department, departments that still have members cannot be deleted
+	Delete(ctx context.Context, departmentId int64) (int64, error)
+	// Members returns the employees of a department
+	Members(ctx context.Context, departmentId int64) ([]instances.Employee, error)
+}
+
+type MySQLDepartmentStore struct {
+	db *sql.DB
+}
+
+func NewDepartmentStore(cfg mysql.Config) (*MySQLDepartmentStore, error) {
+	db, err := openDB(cfg, "departments")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLDepartmentStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLDepartmentStore) Close() error {
+	return s.db.Close()
+}
+
+func scanDepartment(row rowScanner) (instances.Department, error) {
+	var dept instances.Department
+	var description sql.NullString
+	if err := row.Scan(&dept.DepartmentId, &dept.Name, &description); err != nil {
+		return instances.Department{}, err
+	}
+	dept.Description = description.String
+	return dept, nil
+}
+
+func (s *MySQLDepartmentStore) Add(ctx context.Context, dept instances.Department) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO Departments (name, description) VALUES (?,?)",
+		dept.Name, nullString(dept.Description))
+	if err != nil {
+		return -1, queryError(ctx, "departments.Add", err, "name", dept.Name)
+	}
+	return result.LastInsertId()
+}
+
+func (s *MySQLDepartmentStore) Get(ctx context.Context, departmentId int64) (instances.Department, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT department_id, name, description FROM Departments WHERE department_id = ?",
+		departmentId)
+	dept, err := scanDepartment(row)
+	if err != nil {
+		return instances.Department{}, queryError(ctx, "departments.Get", err, "department_id", departmentId)
+	}
+	return dept, nil
+}
+
+func (s *MySQLDepartmentStore) List(ctx context.Context) ([]instances.Department, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT department_id, name, description FROM Departments ORDER BY name")
+	if err != nil {
+		return nil, queryError(ctx, "departments.List", err)
+	}
+	defer rows.Close()
+
+	depts := []instances.Department{}
+	for rows.Next() {
+		dept, err := scanDepartment(rows)
+		if err != nil {
+			return nil, queryError(ctx, "departments.List", err)
+		}
+		depts = append(depts, dept)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "departments.List", err)
+	}
+	return depts, nil
+}
+
+func (s *MySQLDepartmentStore) Update(ctx context.Context, currId int64, dept instances.Department) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE Departments SET name=?, description=? WHERE department_id=?",
+		dept.Name, nullString(dept.Description), currId)
+	if err != nil {
+		return -1, queryError(ctx, "departments.Update", err, "department_id", currId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLDepartmentStore) Delete(ctx context.Context, departmentId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM Departments WHERE department_id=?", departmentId)
+	if err != nil {
+		return -1, queryError(ctx, "departments.Delete", err, "department_id", departmentId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLDepartmentStore) Members(ctx context.Context, departmentId int64) ([]instances.Employee, error) {
+	employees, err := queryEmployees(ctx, s.db, "department_id = ? ORDER BY employee_id", departmentId)
+	if err != nil {
+		return nil, queryError(ctx, "departments.Members", err, "department_id", departmentId)
+	}
+	if employees == nil {
+		employees = []instances.Employee{}
+	}
+	return employees, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+)
+
+// A patch of a record runs in one transaction: the record is read and locked, apply turns it into the patched
+// record, which is written unless apply fails. Errors of apply are returned as they are, records that do not exist
+// as sql.ErrNoRows.
+
+// Patch changes an employee to what apply makes of it. Reporting to one of their own reports fails with
+// errManagerCycle.
+func (s *MySQLEmployeeStore) Patch(ctx context.Context, employeeId int64, apply func(instances.Employee) (instances.Employee, error)) (instances.Employee, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.Employee{}, queryError(ctx, "employees.Patch", err, "employee_id", employeeId)
+	}
+	defer tx.Rollback()
+
+	employees, err := lockEmployees(ctx, tx, employeeId)
+	if err != nil {
+		return instances.Employee{}, queryError(ctx, "employees.Patch", err, "employee_id", employeeId)
+	}
+	if len(employees) == 0 {
+		return instances.Employee{}, sql.ErrNoRows
+	}
+	emp, err := apply(employees[0])
+	if err != nil {
+		return instances.Employee{}, err
+	}
+	if _, err := updateEmployee(ctx, tx, employeeId, emp); err != nil {
+		if errors.Is(err, errManagerCycle) {
+			return instances.Employee{}, err
+		}
+		return instances.Employee{}, queryError(ctx, "employees.Patch", err, "employee_id", employeeId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.Employee{}, queryError(ctx, "employees.Patch", err, "employee_id", employeeId)
+	}
+	return emp, nil
+}
+
+// PatchSkill changes the level of an employee skill to what apply makes of it and records it as a new assessment.
+// The current skill passed to apply has no source, assessor or note, these describe the new assessment.
+func (s *MySQLEmployeeStore) PatchSkill(ctx context.Context, employeeId int64, skillId int64, apply func(instances.EmployeeSkill) (instances.EmployeeSkill, error)) (instances.EmployeeSkill, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.EmployeeSkill{}, queryError(ctx, "employees.PatchSkill", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	defer tx.Rollback()
+
+	current := instances.EmployeeSkill{SkillId: skillId}
+	err = tx.QueryRowContext(ctx, "SELECT skill_level FROM EmployeeSkills WHERE employee_id=? AND skill_id=? FOR UPDATE",
+		employeeId, skillId).Scan(&current.SkillLevel)
+	if err != nil {
+		return instances.EmployeeSkill{}, queryError(ctx, "employees.PatchSkill", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	empSkill, err := apply(current)
+	if err != nil {
+		return instances.EmployeeSkill{}, err
+	}
+	if _, err := updateEmployeeSkill(ctx, tx, employeeId, empSkill); err != nil {
+		return instances.EmployeeSkill{}, queryError(ctx, "employees.PatchSkill", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.EmployeeSkill{}, queryError(ctx, "employees.PatchSkill", err, "employee_id", employeeId, "skill_id", skillId)
+	}
+	return empSkill, nil
+}
+
+// PatchAssignment changes a stint of the employee to what apply makes of it. The same rules apply as to
+// UpdateAssignment, except that empty fields are cleared: no end date makes the stint open-ended, no allocation
+// resets it to 100.
+func (s *MySQLEmployeeStore) PatchAssignment(ctx context.Context, employeeId int64, assignmentId int64, apply func(instances.EmployeeProject) (instances.EmployeeProject, error)) (instances.EmployeeProject, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.EmployeeProject{}, queryError(ctx, "employees.PatchAssignment", err, "assignment_id", assignmentId)
+	}
+	defer tx.Rollback()
+
+	current := instances.EmployeeProject{AssignmentId: assignmentId}
+	var role sql.NullString
+	err = tx.QueryRowContext(ctx, "SELECT project_id, employee_role, start_date, end_date, allocation FROM ProjectDetails "+
+		"WHERE assignment_id=? AND employee_id=? FOR UPDATE", assignmentId, employeeId).
+		Scan(&current.ProjectId, &role, &current.StartDate, &current.EndDate, &current.Allocation)
+	if err != nil {
+		return instances.EmployeeProject{}, queryError(ctx, "employees.PatchAssignment", err, "assignment_id", assignmentId)
+	}
+	current.ProjectRole = role.String
+	ep, err := apply(current)
+	if err != nil {
+		return instances.EmployeeProject{}, err
+	}
+	if ep.Allocation == 0 {
+		ep.Allocation = 100
+	}
+	if _, err := updateStint(ctx, tx, employeeId, ep); err != nil {
+		return instances.EmployeeProject{}, stintError(ctx, "employees.PatchAssignment", err, "assignment_id", assignmentId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.EmployeeProject{}, queryError(ctx, "employees.PatchAssignment", err, "assignment_id", assignmentId)
+	}
+	return ep, nil
+}
+
+// Patch changes a skill to what apply makes of it. Aliases are read only, changes to them are ignored.
+func (s *MySQLSkillStore) Patch(ctx context.Context, skillId int64, apply func(instances.Skill) (instances.Skill, error)) (instances.Skill, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Patch", err, "skill_id", skillId)
+	}
+	defer tx.Rollback()
+
+	current, err := scanSkill(tx.QueryRowContext(ctx, "SELECT skill_id, skill_class, skill, category_id, deprecated, "+
+		"replaced_by FROM Skills WHERE skill_id=? FOR UPDATE", skillId))
+	if err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Patch", err, "skill_id", skillId)
+	}
+	aliases, err := queryAliases(ctx, tx, skillId)
+	if err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Patch", err, "skill_id", skillId)
+	}
+	current.Aliases = aliases[skillId]
+
+	skill, err := apply(current)
+	if err != nil {
+		return instances.Skill{}, err
+	}
+	if _, err := updateSkill(ctx, tx, skillId, skill); err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Patch", err, "skill_id", skillId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.Skill{}, queryError(ctx, "skills.Patch", err, "skill_id", skillId)
+	}
+	skill.Aliases = current.Aliases
+	return skill, nil
+}
+
+// Patch changes a project to what apply makes of it
+func (s *MySQLProjectStore) Patch(ctx context.Context, projId int64, apply func(instances.Project) (instances.Project, error)) (instances.Project, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.Project{}, queryError(ctx, "projects.Patch", err, "project_id", projId)
+	}
+	defer tx.Rollback()
+
+	current, err := scanProject(tx.QueryRowContext(ctx, "SELECT "+projectColumns+" FROM Projects "+
+		"WHERE project_id = ? FOR UPDATE", projId))
+	if err != nil {
+		return instances.Project{}, queryError(ctx, "projects.Patch", err, "project_id", projId)
+	}
+	proj, err := apply(current)
+	if err != nil {
+		return instances.Project{}, err
+	}
+	if _, err := updateProject(ctx, tx, projId, proj); err != nil {
+		return instances.Project{}, queryError(ctx, "projects.Patch", err, "project_id", projId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.Project{}, queryError(ctx, "projects.Patch", err, "project_id", projId)
+	}
+	return proj, nil
+}
+
+// Patch changes a client to what apply makes of it
+func (s *MySQLClientStore) Patch(ctx context.Context, clientId int64, apply func(instances.Client) (instances.Client, error)) (instances.Client, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return instances.Client{}, queryError(ctx, "clients.Patch", err, "client_id", clientId)
+	}
+	defer tx.Rollback()
+
+	current, err := scanClient(tx.QueryRowContext(ctx, "SELECT id, name, description FROM Clients WHERE id = ? FOR UPDATE",
+		clientId))
+	if err != nil {
+		return instances.Client{}, queryError(ctx, "clients.Patch", err, "client_id", clientId)
+	}
+	client, err := apply(current)
+	if err != nil {
+		return instances.Client{}, err
+	}
+	if _, err := updateClient(ctx, tx, clientId, client); err != nil {
+		return instances.Client{}, queryError(ctx, "clients.Patch", err, "client_id", clientId)
+	}
+	if err := tx.Commit(); err != nil {
+		return instances.Client{}, queryError(ctx, "clients.Patch", err, "client_id", clientId)
+	}
+	return client, nil
+}
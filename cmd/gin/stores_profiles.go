@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+)
+
+// ImportProfile creates the employee emp.EmployeeId or updates their name, focus area and email, then sets the
+// levels of skills. Manager and department of existing employees are kept. It reports whether the employee was
+// created.
+func (s *MySQLEmployeeStore) ImportProfile(ctx context.Context, emp instances.Employee, skills []instances.EmployeeSkill) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, "SELECT 1 FROM Employees WHERE employee_id = ? FOR UPDATE", emp.EmployeeId).Scan(&exists)
+	created := errors.Is(err, sql.ErrNoRows)
+	if err != nil && !created {
+		return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId)
+	}
+	if created {
+		_, err = tx.ExecContext(ctx, "INSERT INTO Employees (employee_id, name, lastname, focus_area, email) "+
+			"VALUES (?,?,?,?,?)", emp.EmployeeId, emp.Name, emp.Lastname, emp.FocusArea, emp.Email)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE Employees SET name=?, lastname=?, focus_area=?, email=? "+
+			"WHERE employee_id = ?", emp.Name, emp.Lastname, emp.FocusArea, emp.Email, emp.EmployeeId)
+	}
+	if err != nil {
+		return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId)
+	}
+
+	for _, empSkill := range skills {
+		_, err := tx.ExecContext(ctx, "INSERT INTO EmployeeSkills (employee_id, skill_id, skill_level) VALUES (?,?,?) "+
+			"ON DUPLICATE KEY UPDATE skill_level = VALUES(skill_level)", emp.EmployeeId, empSkill.SkillId, empSkill.SkillLevel)
+		if err != nil {
+			return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId,
+				"skill_id", empSkill.SkillId)
+		}
+		if err := insertAssessment(ctx, tx, emp.EmployeeId, empSkill); err != nil {
+			return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId,
+				"skill_id", empSkill.SkillId)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, queryError(ctx, "employees.ImportProfile", err, "employee_id", emp.EmployeeId)
+	}
+	return created, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+)
+
+// Updates never change ids, the rows referencing a record would fail their foreign keys or be orphaned. Re-keying a
+// record instead copies it under the new id, moves every reference to the copy and deletes the original, all in one
+// transaction.
+
+// columnRef is a column referencing the id of a record
+type columnRef struct {
+	table  string
+	column string
+}
+
+var (
+	skillRefs = []columnRef{
+		{"EmployeeSkills", "skill_id"}, {"ProjectRequirements", "skill_id"}, {"SkillOwners", "skill_id"},
+		{"SkillAssessments", "skill_id"}, {"SkillChangeRequests", "skill_id"}, {"Certifications", "skill_id"},
+		{"SkillAliases", "skill_id"}, {"Skills", "replaced_by"},
+	}
+	projectRefs = []columnRef{{"ProjectDetails", "project_id"}, {"ProjectRequirements", "project_id"}}
+	clientRefs  = []columnRef{{"Projects", "client_id"}}
+)
+
+// rekey changes the id of the row currId of table to newId. copyRow inserts the copy, taking the new and the current
+// id as its arguments. It fails with sql.ErrNoRows if there is no row currId, and with a duplicate key error if newId
+// is taken.
+func rekey(ctx context.Context, db *sql.DB, op string, table string, idColumn string, copyRow string, refs []columnRef,
+	currId int64, newId int64) (instances.Rekey, error) {
+	fail := func(err error) (instances.Rekey, error) {
+		return instances.Rekey{}, queryError(ctx, op, err, "id", currId, "new_id", newId)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, copyRow, newId, currId)
+	if err != nil {
+		return fail(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		return fail(err)
+	}
+	m := &mergeTx{ctx: ctx, tx: tx, source: currId, target: newId, moved: map[string]int64{}}
+	for _, ref := range refs {
+		if err := m.move(ref.table, ref.column); err != nil {
+			return fail(err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE "+idColumn+" = ?", currId); err != nil {
+		return fail(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fail(err)
+	}
+	return instances.Rekey{OldId: currId, NewId: newId, Moved: m.moved}, nil
+}
+
+func (s *MySQLSkillStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	return rekey(ctx, s.db, "skills.Rekey", "Skills", "skill_id",
+		"INSERT INTO Skills (skill_id, skill_class, skill, category_id, deprecated, replaced_by) "+
+			"SELECT ?, skill_class, skill, category_id, deprecated, replaced_by FROM Skills WHERE skill_id = ?",
+		skillRefs, currId, newId)
+}
+
+func (s *MySQLProjectStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	return rekey(ctx, s.db, "projects.Rekey", "Projects", "project_id",
+		"INSERT INTO Projects (project_id, client_id, focus_area, description, isSecret) "+
+			"SELECT ?, client_id, focus_area, description, isSecret FROM Projects WHERE project_id = ?",
+		projectRefs, currId, newId)
+}
+
+func (s *MySQLClientStore) Rekey(ctx context.Context, currId int64, newId int64) (instances.Rekey, error) {
+	return rekey(ctx, s.db, "clients.Rekey", "Clients", "id",
+		"INSERT INTO Clients (id, name, description) SELECT ?, name, description FROM Clients WHERE id = ?",
+		clientRefs, currId, newId)
+}
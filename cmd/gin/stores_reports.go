@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/go-sql-driver/mysql"
+)
+
+type reportStore interface {
+	// Employees returns every employee, ordered by id
+	Employees(ctx context.Context) ([]instances.Employee, error)
+	// Stints returns the assignments overlapping the period from from to to
+	Stints(ctx context.Context, from instances.Date, to instances.Date) ([]stint, error)
+	// TopSkills returns up to n of the highest rated skills of each employee
+	TopSkills(ctx context.Context, employeeIds []int64, n int) (map[int64][]instances.Skill, error)
+	// Staffing returns who works on which project, for the assignments in the state status or all of them if empty
+	Staffing(ctx context.Context, status string) ([]staffingLink, error)
+}
+
+type MySQLReportStore struct {
+	db *sql.DB
+}
+
+func NewReportStore(cfg mysql.Config) (*MySQLReportStore, error) {
+	db, err := openDB(cfg, "reports")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLReportStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLReportStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLReportStore) Employees(ctx context.Context) ([]instances.Employee, error) {
+	employees, err := queryEmployees(ctx, s.db, "TRUE ORDER BY employee_id")
+	if err != nil {
+		return nil, queryError(ctx, "reports.Employees", err)
+	}
+	if employees == nil {
+		employees = []instances.Employee{}
+	}
+	return employees, nil
+}
+
+func (s *MySQLReportStore) Stints(ctx context.Context, from instances.Date, to instances.Date) ([]stint, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT b.employee_id, COALESCE(a.focus_area, ''), b.start_date, b.end_date, "+
+		"b.allocation FROM ProjectDetails AS b INNER JOIN Projects AS a ON a.project_id = b.project_id "+
+		"WHERE COALESCE(b.start_date, ?) <= ? AND COALESCE(b.end_date, ?) >= ?", unknownStart, to, openEnd, from)
+	if err != nil {
+		return nil, queryError(ctx, "reports.Stints", err, "from", from.String(), "to", to.String())
+	}
+	defer rows.Close()
+
+	var stints []stint
+	for rows.Next() {
+		var st stint
+		if err := rows.Scan(&st.EmployeeId, &st.FocusArea, &st.Start, &st.End, &st.Allocation); err != nil {
+			return nil, queryError(ctx, "reports.Stints", err)
+		}
+		stints = append(stints, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "reports.Stints", err)
+	}
+	return stints, nil
+}
+
+func (s *MySQLReportStore) TopSkills(ctx context.Context, employeeIds []int64, n int) (map[int64][]instances.Skill, error) {
+	top := make(map[int64][]instances.Skill)
+	if len(employeeIds) == 0 {
+		return top, nil
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT es.employee_id, s.skill_id, s.skill_class, s.skill, es.skill_level "+
+		"FROM EmployeeSkills AS es INNER JOIN Skills AS s ON s.skill_id = es.skill_id "+
+		"WHERE es.employee_id IN ("+placeholders(len(employeeIds))+") "+
+		"ORDER BY es.employee_id, es.skill_level DESC, s.skill", int64sToArgs(employeeIds)...)
+	if err != nil {
+		return nil, queryError(ctx, "reports.TopSkills", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var employeeId int64
+		var skill instances.Skill
+		if err := rows.Scan(&employeeId, &skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel); err != nil {
+			return nil, queryError(ctx, "reports.TopSkills", err)
+		}
+		if len(top[employeeId]) < n {
+			top[employeeId] = append(top[employeeId], skill)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "reports.TopSkills", err)
+	}
+	return top, nil
+}
+
+func (s *MySQLReportStore) Staffing(ctx context.Context, status string) ([]staffingLink, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT b.employee_id, COALESCE(b.employee_role, ''), a.project_id, "+
+		"COALESCE(a.focus_area, ''), COALESCE(a.description, ''), COALESCE(a.isSecret, FALSE), c.id, c.name "+
+		"FROM ProjectDetails AS b INNER JOIN Projects AS a ON a.project_id = b.project_id "+
+		"LEFT JOIN Clients AS c ON c.id = a.client_id WHERE TRUE"+assignmentCondition(status)+
+		" ORDER BY b.employee_id, a.project_id")
+	if err != nil {
+		return nil, queryError(ctx, "reports.Staffing", err, "status", status)
+	}
+	defer rows.Close()
+
+	var links []staffingLink
+	for rows.Next() {
+		var link staffingLink
+		var clientId sql.NullInt64
+		var clientName sql.NullString
+		if err := rows.Scan(&link.EmployeeId, &link.Role, &link.Project.ProjectId, &link.Project.FocusArea,
+			&link.Project.Description, &link.Project.IsSecret, &clientId, &clientName); err != nil {
+			return nil, queryError(ctx, "reports.Staffing", err)
+		}
+		if clientId.Valid {
+			link.Project.ClientId = int(clientId.Int64)
+			link.Client = &instances.Client{ID: clientId.Int64, Name: clientName.String}
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "reports.Staffing", err)
+	}
+	return links, nil
+}
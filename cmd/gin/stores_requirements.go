@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"github.com/go-sql-driver/mysql"
+)
+
+type requirementStore interface {
+	Add(ctx context.Context, req instances.ProjectRequirement) (int64, error)
+	Get(ctx context.Context, projectId int64, requirementId int64) (instances.ProjectRequirement, error)
+	List(ctx context.Context, projectId int64) ([]instances.ProjectRequirement, error)
+	Update(ctx context.Context, projectId int64, requirementId int64, req instances.ProjectRequirement) (int64, error)
+	Delete(ctx context.Context, projectId int64, requirementId int64) (int64, error)
+	// Team returns the employees currently on the project with those of their skills the project requires
+	Team(ctx context.Context, projectId int64) ([]instances.EmployeeMatch, error)
+	// Candidates returns the employees having any skill the project requires, with those skills. The current team
+	// is left out unless includeTeam is set.
+	Candidates(ctx context.Context, projectId int64, includeTeam bool) ([]instances.EmployeeMatch, error)
+	// Experience maps employees to the other projects of the project's focus area they worked on. Secret projects are
+	// left out, like in exports, so candidates do not reveal who works on them.
+	Experience(ctx context.Context, projectId int64) (map[int64][]int64, error)
+	// SkillHolders returns the employees having any of the skills, with those skills
+	SkillHolders(ctx context.Context, skillIds []int64) ([]instances.EmployeeMatch, error)
+}
+
+type MySQLRequirementStore struct {
+	db *sql.DB
+}
+
+func NewRequirementStore(cfg mysql.Config) (*MySQLRequirementStore, error) {
+	db, err := openDB(cfg, "requirements")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLRequirementStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLRequirementStore) Close() error {
+	return s.db.Close()
+}
+
+const requirementColumns = "r.requirement_id, r.project_id, r.skill_id, s.skill, r.min_level, r.headcount " +
+	"FROM ProjectRequirements AS r INNER JOIN Skills AS s ON s.skill_id = r.skill_id"
+
+func scanRequirement(row rowScanner) (instances.ProjectRequirement, error) {
+	var req instances.ProjectRequirement
+	err := row.Scan(&req.RequirementId, &req.ProjectId, &req.SkillId, &req.Skill, &req.MinLevel, &req.Headcount)
+	return req, err
+}
+
+func (s *MySQLRequirementStore) Add(ctx context.Context, req instances.ProjectRequirement) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO ProjectRequirements (project_id, skill_id, min_level, headcount) "+
+		"VALUES (?,?,?,?)", req.ProjectId, req.SkillId, req.MinLevel, req.Headcount)
+	if err != nil {
+		return -1, queryError(ctx, "requirements.Add", err, "project_id", req.ProjectId, "skill_id", req.SkillId)
+	}
+	return result.LastInsertId()
+}
+
+func (s *MySQLRequirementStore) Get(ctx context.Context, projectId int64, requirementId int64) (instances.ProjectRequirement, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+requirementColumns+" WHERE r.project_id = ? AND r.requirement_id = ?",
+		projectId, requirementId)
+	req, err := scanRequirement(row)
+	if err != nil {
+		return instances.ProjectRequirement{}, queryError(ctx, "requirements.Get", err, "requirement_id", requirementId)
+	}
+	return req, nil
+}
+
+func (s *MySQLRequirementStore) List(ctx context.Context, projectId int64) ([]instances.ProjectRequirement, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+requirementColumns+" WHERE r.project_id = ? "+
+		"ORDER BY s.skill, r.min_level DESC", projectId)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.List", err, "project_id", projectId)
+	}
+	defer rows.Close()
+
+	reqs := []instances.ProjectRequirement{}
+	for rows.Next() {
+		req, err := scanRequirement(rows)
+		if err != nil {
+			return nil, queryError(ctx, "requirements.List", err, "project_id", projectId)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "requirements.List", err, "project_id", projectId)
+	}
+	return reqs, nil
+}
+
+func (s *MySQLRequirementStore) Update(ctx context.Context, projectId int64, requirementId int64, req instances.ProjectRequirement) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "UPDATE ProjectRequirements SET skill_id=?, min_level=?, headcount=? "+
+		"WHERE project_id=? AND requirement_id=?", req.SkillId, req.MinLevel, req.Headcount, projectId, requirementId)
+	if err != nil {
+		return -1, queryError(ctx, "requirements.Update", err, "requirement_id", requirementId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLRequirementStore) Delete(ctx context.Context, projectId int64, requirementId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM ProjectRequirements WHERE project_id=? AND requirement_id=?",
+		projectId, requirementId)
+	if err != nil {
+		return -1, queryError(ctx, "requirements.Delete", err, "requirement_id", requirementId)
+	}
+	return result.RowsAffected()
+}
+
+func (s *MySQLRequirementStore) Team(ctx context.Context, projectId int64) ([]instances.EmployeeMatch, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT e.employee_id, e.name, e.lastname, COALESCE(e.focus_area, ''), "+
+		"COALESCE(e.email, ''), s.skill_id, s.skill_class, s.skill, es.skill_level FROM ProjectDetails AS b "+
+		"INNER JOIN Employees AS e ON e.employee_id = b.employee_id "+
+		"INNER JOIN EmployeeSkills AS es ON es.employee_id = e.employee_id "+
+		"INNER JOIN Skills AS s ON s.skill_id = es.skill_id "+
+		"WHERE b.project_id = ? AND es.skill_id IN (SELECT skill_id FROM ProjectRequirements WHERE project_id = ?)"+
+		assignmentCondition(instances.AssignmentCurrent)+" ORDER BY e.employee_id, es.skill_level DESC",
+		projectId, projectId)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.Team", err, "project_id", projectId)
+	}
+	defer rows.Close()
+
+	team, err := scanEmployeeMatches(rows)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.Team", err, "project_id", projectId)
+	}
+	return team, nil
+}
+
+// scanEmployeeMatches groups rows of employees and skills, ordered by employee, into one match per employee
+func scanEmployeeMatches(rows *sql.Rows) ([]instances.EmployeeMatch, error) {
+	matches := []instances.EmployeeMatch{}
+	for rows.Next() {
+		var emp instances.Employee
+		var skill instances.Skill
+		if err := rows.Scan(&emp.EmployeeId, &emp.Name, &emp.Lastname, &emp.FocusArea, &emp.Email,
+			&skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel); err != nil {
+			return nil, err
+		}
+		if n := len(matches); n == 0 || matches[n-1].Employee.EmployeeId != emp.EmployeeId {
+			matches = append(matches, instances.EmployeeMatch{Employee: emp})
+		}
+		last := &matches[len(matches)-1]
+		last.Skills = append(last.Skills, skill)
+	}
+	return matches, rows.Err()
+}
+
+func (s *MySQLRequirementStore) Candidates(ctx context.Context, projectId int64, includeTeam bool) ([]instances.EmployeeMatch, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT e.employee_id, e.name, e.lastname, COALESCE(e.focus_area, ''), "+
+		"COALESCE(e.email, ''), s.skill_id, s.skill_class, s.skill, es.skill_level FROM EmployeeSkills AS es "+
+		"INNER JOIN Employees AS e ON e.employee_id = es.employee_id "+
+		"INNER JOIN Skills AS s ON s.skill_id = es.skill_id "+
+		"WHERE es.skill_id IN (SELECT skill_id FROM ProjectRequirements WHERE project_id = ?) "+
+		"AND (? OR NOT EXISTS (SELECT 1 FROM ProjectDetails AS b WHERE b.employee_id = e.employee_id "+
+		"AND b.project_id = ?"+assignmentCondition(instances.AssignmentCurrent)+")) "+
+		"ORDER BY e.employee_id, es.skill_level DESC", projectId, includeTeam, projectId)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.Candidates", err, "project_id", projectId)
+	}
+	defer rows.Close()
+
+	candidates, err := scanEmployeeMatches(rows)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.Candidates", err, "project_id", projectId)
+	}
+	return candidates, nil
+}
+
+func (s *MySQLRequirementStore) Experience(ctx context.Context, projectId int64) (map[int64][]int64, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT b.employee_id, b.project_id FROM ProjectDetails AS b "+
+		"INNER JOIN Projects AS a ON a.project_id = b.project_id "+
+		"INNER JOIN Projects AS p ON p.focus_area = a.focus_area "+
+		"WHERE p.project_id = ? AND b.project_id <> ? AND NOT COALESCE(a.isSecret, FALSE) "+
+		"AND (b.start_date IS NULL OR b.start_date <= CURDATE()) "+
+		"ORDER BY b.employee_id, b.project_id", projectId, projectId)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.Experience", err, "project_id", projectId)
+	}
+	defer rows.Close()
+
+	experience := make(map[int64][]int64)
+	for rows.Next() {
+		var employeeId, otherId int64
+		if err := rows.Scan(&employeeId, &otherId); err != nil {
+			return nil, queryError(ctx, "requirements.Experience", err, "project_id", projectId)
+		}
+		experience[employeeId] = append(experience[employeeId], otherId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "requirements.Experience", err, "project_id", projectId)
+	}
+	return experience, nil
+}
+
+func (s *MySQLRequirementStore) SkillHolders(ctx context.Context, skillIds []int64) ([]instances.EmployeeMatch, error) {
+	if len(skillIds) == 0 {
+		return []instances.EmployeeMatch{}, nil
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT e.employee_id, e.name, e.lastname, COALESCE(e.focus_area, ''), "+
+		"COALESCE(e.email, ''), s.skill_id, s.skill_class, s.skill, es.skill_level FROM EmployeeSkills AS es "+
+		"INNER JOIN Employees AS e ON e.employee_id = es.employee_id "+
+		"INNER JOIN Skills AS s ON s.skill_id = es.skill_id "+
+		"WHERE es.skill_id IN ("+placeholders(len(skillIds))+") ORDER BY e.employee_id, es.skill_level DESC",
+		int64sToArgs(skillIds)...)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.SkillHolders", err)
+	}
+	defer rows.Close()
+
+	holders, err := scanEmployeeMatches(rows)
+	if err != nil {
+		return nil, queryError(ctx, "requirements.SkillHolders", err)
+	}
+	return holders, nil
+}
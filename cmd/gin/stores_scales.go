@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+)
+
+// errScaleInUse is returned when a scale change would leave assigned skill levels without a definition
+var errScaleInUse = errors.New("employees hold levels that the new scale does not define")
+
+type skillScaleStore interface {
+	List(ctx context.Context) ([]instances.SkillScale, error)
+	Get(ctx context.Context, skillClass string) (instances.SkillScale, error)
+	// ForSkill returns the scale of the skill's class, or the default scale if the class has none
+	ForSkill(ctx context.Context, skillId int64) (instances.SkillScale, error)
+	Put(ctx context.Context, scale instances.SkillScale) error
+	Delete(ctx context.Context, skillClass string) (int64, error)
+}
+
+type MySQLSkillScaleStore struct {
+	db *sql.DB
+}
+
+func NewSkillScaleStore(cfg mysql.Config) (*MySQLSkillScaleStore, error) {
+	db, err := openDB(cfg, "skillScales")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLSkillScaleStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLSkillScaleStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *MySQLSkillScaleStore) List(ctx context.Context) ([]instances.SkillScale, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT skill_class, name, description FROM SkillScales ORDER BY skill_class")
+	if err != nil {
+		return nil, queryError(ctx, "skillScales.List", err)
+	}
+	defer rows.Close()
+
+	var scales []instances.SkillScale
+	for rows.Next() {
+		var scale instances.SkillScale
+		var description sql.NullString
+		if err := rows.Scan(&scale.SkillClass, &scale.Name, &description); err != nil {
+			return nil, queryError(ctx, "skillScales.List", err)
+		}
+		scale.Description = description.String
+		scales = append(scales, scale)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "skillScales.List", err)
+	}
+
+	for i := range scales {
+		if scales[i].Levels, err = s.levels(ctx, scales[i].SkillClass); err != nil {
+			return nil, queryError(ctx, "skillScales.List", err, "skill_class", scales[i].SkillClass)
+		}
+	}
+	return scales, nil
+}
+
+func (s *MySQLSkillScaleStore) Get(ctx context.Context, skillClass string) (instances.SkillScale, error) {
+	var scale instances.SkillScale
+	var description sql.NullString
+	row := s.db.QueryRowContext(ctx, "SELECT skill_class, name, description FROM SkillScales WHERE skill_class = ?", skillClass)
+	if err := row.Scan(&scale.SkillClass, &scale.Name, &description); err != nil {
+		return instances.SkillScale{}, queryError(ctx, "skillScales.Get", err, "skill_class", skillClass)
+	}
+	scale.Description = description.String
+
+	levels, err := s.levels(ctx, skillClass)
+	if err != nil {
+		return instances.SkillScale{}, queryError(ctx, "skillScales.Get", err, "skill_class", skillClass)
+	}
+	scale.Levels = levels
+	return scale, nil
+}
+
+func (s *MySQLSkillScaleStore) ForSkill(ctx context.Context, skillId int64) (instances.SkillScale, error) {
+	var skillClass string
+	var hasScale bool
+	row := s.db.QueryRowContext(ctx, "SELECT s.skill_class, sc.skill_class IS NOT NULL FROM Skills AS s "+
+		"LEFT JOIN SkillScales AS sc ON sc.skill_class = s.skill_class WHERE s.skill_id = ?", skillId)
+	if err := row.Scan(&skillClass, &hasScale); err != nil {
+		return instances.SkillScale{}, queryError(ctx, "skillScales.ForSkill", err, "skill_id", skillId)
+	}
+	if !hasScale {
+		scale := instances.DefaultSkillScale
+		scale.SkillClass = skillClass
+		return scale, nil
+	}
+	return s.Get(ctx, skillClass)
+}
+
+func (s *MySQLSkillScaleStore) levels(ctx context.Context, skillClass string) ([]instances.SkillScaleLevel, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT level, label, description FROM SkillScaleLevels "+
+		"WHERE skill_class = ? ORDER BY level", skillClass)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []instances.SkillScaleLevel
+	for rows.Next() {
+		var level instances.SkillScaleLevel
+		var description sql.NullString
+		if err := rows.Scan(&level.Level, &level.Label, &description); err != nil {
+			return nil, err
+		}
+		level.Description = description.String
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
+
+// Put creates or replaces the scale of a skill class. Levels already assigned to employees have to stay defined,
+// otherwise errScaleInUse is returned and nothing is changed.
+func (s *MySQLSkillScaleStore) Put(ctx context.Context, scale instances.SkillScale) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return queryError(ctx, "skillScales.Put", err, "skill_class", scale.SkillClass)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO SkillScales (skill_class, name, description) VALUES (?,?,?) "+
+		"ON DUPLICATE KEY UPDATE name = VALUES(name), description = VALUES(description)",
+		scale.SkillClass, scale.Name, scale.Description)
+	if err != nil {
+		return queryError(ctx, "skillScales.Put", err, "skill_class", scale.SkillClass)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM SkillScaleLevels WHERE skill_class = ?", scale.SkillClass); err != nil {
+		return queryError(ctx, "skillScales.Put", err, "skill_class", scale.SkillClass)
+	}
+	for _, level := range scale.Levels {
+		_, err := tx.ExecContext(ctx, "INSERT INTO SkillScaleLevels (skill_class, level, label, description) VALUES (?,?,?,?)",
+			scale.SkillClass, level.Level, level.Label, level.Description)
+		if err != nil {
+			return queryError(ctx, "skillScales.Put", err, "skill_class", scale.SkillClass)
+		}
+	}
+
+	var undefined int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM EmployeeSkills AS e "+
+		"INNER JOIN Skills AS s ON e.skill_id = s.skill_id "+
+		"LEFT JOIN SkillScaleLevels AS l ON l.skill_class = s.skill_class AND l.level = e.skill_level "+
+		"WHERE s.skill_class = ? AND l.level IS NULL", scale.SkillClass).Scan(&undefined)
+	if err != nil {
+		return queryError(ctx, "skillScales.Put", err, "skill_class", scale.SkillClass)
+	}
+	if undefined > 0 {
+		return fmt.Errorf("%w (%d assignments)", errScaleInUse, undefined)
+	}
+	return tx.Commit()
+}
+
+// Delete removes the scale of a skill class, which falls back to the default scale afterwards
+func (s *MySQLSkillScaleStore) Delete(ctx context.Context, skillClass string) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM SkillScales WHERE skill_class = ?", skillClass)
+	if err != nil {
+		return -1, queryError(ctx, "skillScales.Delete", err, "skill_class", skillClass)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/go-sql-driver/mysql"
+	"strings"
+)
+
+// errCategoryCycle is returned when a category would become its own ancestor
+var errCategoryCycle = errors.New("a category cannot be nested below itself or one of its subcategories")
+
+type skillCategoryStore interface {
+	Add(ctx context.Context, category instances.SkillCategory) (int64, error)
+	Get(ctx context.Context, categoryId int64) (instances.SkillCategory, error)
+	List(ctx context.Context) ([]instances.SkillCategory, error)
+	Update(ctx context.Context, currId int64, category instances.SkillCategory) (int64, error)
+	Delete(ctx context.Context, categoryId int64) (int64, error)
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type MySQLSkillCategoryStore struct {
+	db *sql.DB
+}
+
+func NewSkillCategoryStore(cfg mysql.Config) (*MySQLSkillCategoryStore, error) {
+	db, err := openDB(cfg, "skillCategories")
+	if err != nil {
+		return nil, err
+	}
+	return &MySQLSkillCategoryStore{db: db}, nil
+}
+
+// Close closes the store's connection pool
+func (s *MySQLSkillCategoryStore) Close() error {
+	return s.db.Close()
+}
+
+// Add creates a category and returns its id
+func (s *MySQLSkillCategoryStore) Add(ctx context.Context, category instances.SkillCategory) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "INSERT INTO SkillCategories (name, parent_id, description) VALUES (?,?,?)",
+		category.Name, category.ParentId, category.Description)
+	if err != nil {
+		return -1, queryError(ctx, "skillCategories.Add", err)
+	}
+	return result.LastInsertId()
+}
+
+func (s *MySQLSkillCategoryStore) Get(ctx context.Context, categoryId int64) (instances.SkillCategory, error) {
+	var category instances.SkillCategory
+	var parentId sql.NullInt64
+	var description sql.NullString
+	row := s.db.QueryRowContext(ctx, "SELECT category_id, name, parent_id, description FROM SkillCategories "+
+		"WHERE category_id = ?", categoryId)
+	if err := row.Scan(&category.CategoryId, &category.Name, &parentId, &description); err != nil {
+		return instances.SkillCategory{}, queryError(ctx, "skillCategories.Get", err, "category_id", categoryId)
+	}
+	category.ParentId = nullInt64Ptr(parentId)
+	category.Description = description.String
+	return category, nil
+}
+
+func (s *MySQLSkillCategoryStore) List(ctx context.Context) ([]instances.SkillCategory, error) {
+	categories, err := listCategories(ctx, s.db)
+	if err != nil {
+		return nil, queryError(ctx, "skillCategories.List", err)
+	}
+	return categories, nil
+}
+
+// Update changes a category. Moving it below one of its own subcategories fails with errCategoryCycle.
+func (s *MySQLSkillCategoryStore) Update(ctx context.Context, currId int64, category instances.SkillCategory) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return -1, queryError(ctx, "skillCategories.Update", err, "category_id", currId)
+	}
+	defer tx.Rollback()
+
+	// lock the taxonomy, so two concurrent moves cannot create a cycle together
+	categories, err := listCategoriesForUpdate(ctx, tx)
+	if err != nil {
+		return -1, queryError(ctx, "skillCategories.Update", err, "category_id", currId)
+	}
+	if createsCycle(categories, currId, category.ParentId) {
+		return -1, errCategoryCycle
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE SkillCategories SET name=?, parent_id=?, description=? WHERE category_id=?",
+		category.Name, category.ParentId, category.Description, currId)
+	if err != nil {
+		return -1, queryError(ctx, "skillCategories.Update", err, "category_id", currId)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, queryError(ctx, "skillCategories.Update", err, "category_id", currId)
+	}
+	return result.RowsAffected()
+}
+
+// Delete removes a category. Categories that still have subcategories or skills cannot be deleted.
+func (s *MySQLSkillCategoryStore) Delete(ctx context.Context, categoryId int64) (int64, error) {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM SkillCategories WHERE category_id = ?", categoryId)
+	if err != nil {
+		return -1, queryError(ctx, "skillCategories.Delete", err, "category_id", categoryId)
+	}
+	return result.RowsAffected()
+}
+
+func listCategories(ctx context.Context, q queryer) ([]instances.SkillCategory, error) {
+	return queryCategories(ctx, q, "SELECT category_id, name, parent_id, description FROM SkillCategories")
+}
+
+func listCategoriesForUpdate(ctx context.Context, q queryer) ([]instances.SkillCategory, error) {
+	return queryCategories(ctx, q, "SELECT category_id, name, parent_id, description FROM SkillCategories FOR UPDATE")
+}
+
+func queryCategories(ctx context.Context, q queryer, query string) ([]instances.SkillCategory, error) {
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []instances.SkillCategory
+	for rows.Next() {
+		var category instances.SkillCategory
+		var parentId sql.NullInt64
+		var description sql.NullString
+		if err := rows.Scan(&category.CategoryId, &category.Name, &parentId, &description); err != nil {
+			return nil, err
+		}
+		category.ParentId = nullInt64Ptr(parentId)
+		category.Description = description.String
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// Search finds the employees holding any skill selected by filter at filter.MinLevel or above, within the part of
+// the organisation the filter selects
+func (s *MySQLEmployeeStore) Search(ctx context.Context, filter instances.SkillSearch) ([]instances.EmployeeMatch, error) {
+	skillIds, err := s.searchSkillIds(ctx, filter)
+	if err != nil {
+		return nil, queryError(ctx, "employees.Search", err)
+	}
+	if len(skillIds) == 0 {
+		return []instances.EmployeeMatch{}, nil
+	}
+
+	args := make([]any, 0, len(skillIds)+1)
+	for _, id := range skillIds {
+		args = append(args, id)
+	}
+	args = append(args, filter.MinLevel)
+	rows, err := s.db.QueryContext(ctx, "SELECT "+qualifiedColumns("e", employeeColumns)+", "+
+		"s.skill_id, s.skill_class, s.skill, es.skill_level FROM EmployeeSkills AS es "+
+		"INNER JOIN Employees AS e ON e.employee_id = es.employee_id "+
+		"INNER JOIN Skills AS s ON s.skill_id = es.skill_id "+
+		"WHERE es.skill_id IN ("+placeholders(len(skillIds))+") AND es.skill_level >= ? "+
+		"ORDER BY e.employee_id, es.skill_level DESC", args...)
+	if err != nil {
+		return nil, queryError(ctx, "employees.Search", err)
+	}
+	defer rows.Close()
+
+	matches := []instances.EmployeeMatch{}
+	for rows.Next() {
+		var skill instances.Skill
+		emp, err := scanEmployee(rows, &skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel)
+		if err != nil {
+			return nil, queryError(ctx, "employees.Search", err)
+		}
+		if n := len(matches); n == 0 || matches[n-1].Employee.EmployeeId != emp.EmployeeId {
+			matches = append(matches, instances.EmployeeMatch{Employee: emp})
+		}
+		last := &matches[len(matches)-1]
+		last.Skills = append(last.Skills, skill)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "employees.Search", err)
+	}
+
+	if filter.OrgFilter.IsSet() {
+		employees, err := queryEmployees(ctx, s.db, "TRUE")
+		if err != nil {
+			return nil, queryError(ctx, "employees.Search", err)
+		}
+		scope := orgScope(employees, filter.OrgFilter)
+		inScope := matches[:0]
+		for _, m := range matches {
+			if scope[m.Employee.EmployeeId] {
+				inScope = append(inScope, m)
+			}
+		}
+		matches = inScope
+	}
+	return matches, nil
+}
+
+// searchSkillIds resolves the skill and category of a search to skill ids. When both are set, a skill has to match both.
+func (s *MySQLEmployeeStore) searchSkillIds(ctx context.Context, filter instances.SkillSearch) ([]int64, error) {
+	var byName, byCategory map[int64]bool
+
+	if filter.Skill != "" {
+		// the collation makes the comparison case-insensitive
+		ids, err := s.queryIds(ctx, "SELECT skill_id FROM Skills WHERE skill = ? "+
+			"UNION SELECT skill_id FROM SkillAliases WHERE alias = ?", filter.Skill, filter.Skill)
+		if err != nil {
+			return nil, err
+		}
+		byName = make(map[int64]bool)
+		for _, id := range ids {
+			byName[id] = true
+		}
+		// holders of a deprecated skill also hold its replacement
+		if len(ids) > 0 {
+			replaced, err := s.queryIds(ctx, "SELECT skill_id FROM Skills WHERE replaced_by IN ("+placeholders(len(ids))+")",
+				int64sToArgs(ids)...)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range replaced {
+				byName[id] = true
+			}
+		}
+	}
+
+	if filter.CategoryId != 0 {
+		categories, err := listCategories(ctx, s.db)
+		if err != nil {
+			return nil, err
+		}
+		categoryIds := descendantIds(categories, filter.CategoryId)
+		ids, err := s.queryIds(ctx, "SELECT skill_id FROM Skills WHERE category_id IN ("+placeholders(len(categoryIds))+")",
+			int64sToArgs(categoryIds)...)
+		if err != nil {
+			return nil, err
+		}
+		byCategory = make(map[int64]bool)
+		for _, id := range ids {
+			byCategory[id] = true
+		}
+	}
+
+	var result []int64
+	switch {
+	case byName != nil && byCategory != nil:
+		for id := range byName {
+			if byCategory[id] {
+				result = append(result, id)
+			}
+		}
+	case byName != nil:
+		for id := range byName {
+			result = append(result, id)
+		}
+	default:
+		for id := range byCategory {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func (s *MySQLEmployeeStore) queryIds(ctx context.Context, query string, args ...any) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// placeholders returns n comma separated question marks for an IN clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func int64sToArgs(ids []int64) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
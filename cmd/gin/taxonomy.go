@@ -0,0 +1,93 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"sort"
+)
+
+// The taxonomy is small enough to be loaded as a whole, so it is walked in memory instead of with recursive queries.
+
+// childrenByParent indexes categories by their parent id, top level categories are found under 0
+func childrenByParent(categories []instances.SkillCategory) map[int64][]instances.SkillCategory {
+	children := make(map[int64][]instances.SkillCategory)
+	for _, c := range categories {
+		var parent int64
+		if c.ParentId != nil {
+			parent = *c.ParentId
+		}
+		children[parent] = append(children[parent], c)
+	}
+	for _, list := range children {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	return children
+}
+
+// descendantIds returns id and the ids of all categories below it
+func descendantIds(categories []instances.SkillCategory, id int64) []int64 {
+	children := childrenByParent(categories)
+	ids := []int64{id}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			ids = append(ids, child.CategoryId)
+		}
+	}
+	return ids
+}
+
+// createsCycle reports whether making parent the parent of category id would make id its own ancestor
+func createsCycle(categories []instances.SkillCategory, id int64, parent *int64) bool {
+	if parent == nil {
+		return false
+	}
+	parents := make(map[int64]*int64, len(categories))
+	for _, c := range categories {
+		parents[c.CategoryId] = c.ParentId
+	}
+	// walk up from the new parent, the walk is bounded in case the stored data already contains a cycle
+	current := parent
+	for steps := 0; current != nil && steps <= len(categories); steps++ {
+		if *current == id {
+			return true
+		}
+		current = parents[*current]
+	}
+	return false
+}
+
+// buildCategoryTree nests categories and their skills below root, 0 builds the whole forest
+func buildCategoryTree(categories []instances.SkillCategory, skills []instances.Skill, root int64) []instances.SkillCategory {
+	children := childrenByParent(categories)
+	skillsByCategory := make(map[int64][]instances.Skill)
+	for _, skill := range skills {
+		if skill.CategoryId != nil {
+			skillsByCategory[*skill.CategoryId] = append(skillsByCategory[*skill.CategoryId], skill)
+		}
+	}
+
+	var build func(parent int64, depth int) []instances.SkillCategory
+	build = func(parent int64, depth int) []instances.SkillCategory {
+		if depth > len(categories) {
+			return nil
+		}
+		var nodes []instances.SkillCategory
+		for _, c := range children[parent] {
+			c.Skills = skillsByCategory[c.CategoryId]
+			c.Children = build(c.CategoryId, depth+1)
+			nodes = append(nodes, c)
+		}
+		return nodes
+	}
+
+	if root == 0 {
+		return build(0, 0)
+	}
+	for _, c := range categories {
+		if c.CategoryId == root {
+			c.Skills = skillsByCategory[c.CategoryId]
+			c.Children = build(c.CategoryId, 1)
+			return []instances.SkillCategory{c}
+		}
+	}
+	return nil
+}
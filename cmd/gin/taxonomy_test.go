@@ -0,0 +1,104 @@
+package main
+
+import (
+	"database/sql"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+func ptr(id int64) *int64 {
+	return &id
+}
+
+// Engineering(1) -> Backend(2) -> Go(3), Design(4)
+var testCategories = []instances.SkillCategory{
+	{CategoryId: 1, Name: "Engineering"},
+	{CategoryId: 2, Name: "Backend", ParentId: ptr(1)},
+	{CategoryId: 3, Name: "Go", ParentId: ptr(2)},
+	{CategoryId: 4, Name: "Design"},
+}
+
+func TestDescendantIds(t *testing.T) {
+	assert.ElementsMatch(t, []int64{1, 2, 3}, descendantIds(testCategories, 1))
+	assert.ElementsMatch(t, []int64{3}, descendantIds(testCategories, 3))
+}
+
+func TestCreatesCycle(t *testing.T) {
+	assert.True(t, createsCycle(testCategories, 1, ptr(3)), "moving below a grandchild")
+	assert.True(t, createsCycle(testCategories, 2, ptr(2)), "moving below itself")
+	assert.False(t, createsCycle(testCategories, 3, ptr(4)))
+	assert.False(t, createsCycle(testCategories, 3, nil))
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	skills := []instances.Skill{
+		{SkillId: 1, Skill: "Go", CategoryId: ptr(3)},
+		{SkillId: 2, Skill: "Figma", CategoryId: ptr(4)},
+		{SkillId: 3, Skill: "Uncategorized"},
+	}
+
+	forest := buildCategoryTree(testCategories, skills, 0)
+	assert.Len(t, forest, 2)
+	assert.Equal(t, "Design", forest[0].Name)
+	assert.Equal(t, "Engineering", forest[1].Name)
+	assert.Equal(t, "Go", forest[1].Children[0].Children[0].Skills[0].Skill)
+
+	subtree := buildCategoryTree(testCategories, skills, 2)
+	assert.Len(t, subtree, 1)
+	assert.Equal(t, "Backend", subtree[0].Name)
+	assert.Len(t, subtree[0].Children, 1)
+
+	assert.Empty(t, buildCategoryTree(testCategories, skills, 99))
+}
+
+// valuesRow is a result row of the given values, nil for NULL
+type valuesRow []any
+
+func (r valuesRow) Scan(dest ...any) error {
+	if len(dest) != len(r) {
+		return fmt.Errorf("expected %d destination arguments, not %d", len(r), len(dest))
+	}
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(r[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if r[i] == nil {
+			return fmt.Errorf("converting NULL to %T is unsupported", d)
+		}
+		reflect.ValueOf(d).Elem().Set(reflect.ValueOf(r[i]))
+	}
+	return nil
+}
+
+func TestSearchScansNullableEmployeeColumns(t *testing.T) {
+	assert.Equal(t, "e.employee_id, e.name, e.lastname, e.focus_area, e.email, e.manager_id, e.department_id",
+		qualifiedColumns("e", employeeColumns))
+
+	var skill instances.Skill
+	emp, err := scanEmployee(valuesRow{int64(3), "Ken", "Thompson", nil, nil, int64(1), int64(2), 7, "Programming", "Go", 4},
+		&skill.SkillId, &skill.SkillClass, &skill.Skill, &skill.SkillLevel)
+	if assert.NoError(t, err) {
+		assert.Equal(t, instances.Employee{EmployeeId: 3, Name: "Ken", Lastname: "Thompson", ManagerId: ptr(1),
+			DepartmentId: ptr(2)}, emp)
+		assert.Equal(t, instances.Skill{SkillId: 7, SkillClass: "Programming", Skill: "Go", SkillLevel: 4}, skill)
+	}
+}
+
+func TestPatchScansNullableProjectAndClientColumns(t *testing.T) {
+	proj, err := scanProject(valuesRow{int64(5), nil, nil, nil, nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, instances.Project{ProjectId: 5}, proj)
+	}
+	assert.False(t, nullInt64(int64(proj.ClientId)).Valid, "a project without a client is written back without one")
+
+	client, err := scanClient(valuesRow{int64(2), "Initech", nil})
+	if assert.NoError(t, err) {
+		assert.Equal(t, instances.Client{ID: 2, Name: "Initech"}, client)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// withTeamDefaults applies the defaults of the optional fields of a team request
+func withTeamDefaults(req instances.TeamRequest) instances.TeamRequest {
+	if req.Allocation == 0 {
+		req.Allocation = 100
+	}
+	if req.MaxAllocation == 0 {
+		req.MaxAllocation = 100
+	}
+	if req.Proposals == 0 {
+		req.Proposals = 1
+	}
+	if req.Days == 0 {
+		req.Days = 30
+	}
+	for i := range req.Requirements {
+		if req.Requirements[i].Headcount == 0 {
+			req.Requirements[i].Headcount = 1
+		}
+	}
+	return req
+}
+
+// missingHeadcount is how many more employees the requirements need with team
+func missingHeadcount(reqs []instances.ProjectRequirement, team []instances.EmployeeMatch) int {
+	missing := 0
+	for _, cov := range coverage(0, reqs, team).Requirements {
+		missing += cov.Missing
+	}
+	return missing
+}
+
+// proposeTeams assembles up to req.Proposals different teams for the requirements of req from the employees of pool.
+// allocation maps employees to their average allocation over the period.
+//
+// Each team is built greedily: starting from the must-include members, the employee who fills the most missing
+// headcount joins next, ties going to the one over-allocated the least, then the least allocated one, then the lowest
+// id. Alternative teams are built the same way without the first employee picked for each earlier team, so the
+// proposals are the same for the same data.
+func proposeTeams(req instances.TeamRequest, pool []instances.EmployeeMatch, allocation map[int64]float64) []instances.TeamProposal {
+	banned := make(map[int64]bool, len(req.Exclude))
+	for _, id := range req.Exclude {
+		banned[id] = true
+	}
+	proposals := []instances.TeamProposal{}
+	seen := make(map[string]bool)
+	for len(proposals) < req.Proposals {
+		team, first := assembleTeam(req, pool, allocation, banned)
+		if key := teamKey(team); !seen[key] {
+			seen[key] = true
+			proposals = append(proposals, proposal(req, team, allocation))
+		}
+		if first == 0 {
+			// every member is a must-include, there is nothing to vary
+			break
+		}
+		banned[first] = true
+	}
+	return proposals
+}
+
+// assembleTeam builds one team greedily and returns it with the first member it picked, 0 if it picked none
+func assembleTeam(req instances.TeamRequest, pool []instances.EmployeeMatch, allocation map[int64]float64,
+	banned map[int64]bool) ([]instances.EmployeeMatch, int64) {
+	mustInclude := make(map[int64]bool, len(req.MustInclude))
+	for _, id := range req.MustInclude {
+		mustInclude[id] = true
+	}
+	var team []instances.EmployeeMatch
+	inTeam := make(map[int64]bool)
+	for _, m := range pool {
+		if mustInclude[m.Employee.EmployeeId] {
+			team = append(team, m)
+			inTeam[m.Employee.EmployeeId] = true
+		}
+	}
+
+	var first int64
+	missing := missingHeadcount(req.Requirements, team)
+	for missing > 0 && (req.TeamSize == 0 || len(team) < req.TeamSize) {
+		best, bestGain := -1, 0
+		var bestOver, bestLoad float64
+		for i, m := range pool {
+			id := m.Employee.EmployeeId
+			load := allocation[id]
+			if inTeam[id] || banned[id] || load+float64(req.Allocation) > float64(req.MaxAllocation) {
+				continue
+			}
+			gain := missing - missingHeadcount(req.Requirements, append(team[:len(team):len(team)], m))
+			if gain <= 0 {
+				continue
+			}
+			over := max(0, load+float64(req.Allocation)-100)
+			if best < 0 || gain > bestGain || gain == bestGain && (over < bestOver || over == bestOver && load < bestLoad) {
+				best, bestGain, bestOver, bestLoad = i, gain, over, load
+			}
+		}
+		if best < 0 {
+			break
+		}
+		team = append(team, pool[best])
+		inTeam[pool[best].Employee.EmployeeId] = true
+		if first == 0 {
+			first = pool[best].Employee.EmployeeId
+		}
+		missing -= bestGain
+	}
+	return team, first
+}
+
+// teamKey identifies a team by its members regardless of their order
+func teamKey(team []instances.EmployeeMatch) string {
+	ids := make([]string, len(team))
+	for i, m := range team {
+		ids[i] = strconv.FormatInt(m.Employee.EmployeeId, 10)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func proposal(req instances.TeamRequest, team []instances.EmployeeMatch, allocation map[int64]float64) instances.TeamProposal {
+	mustInclude := make(map[int64]bool, len(req.MustInclude))
+	for _, id := range req.MustInclude {
+		mustInclude[id] = true
+	}
+	p := instances.TeamProposal{
+		Members:  make([]instances.TeamMember, 0, len(team)),
+		Coverage: coverage(0, req.Requirements, team),
+	}
+	for _, m := range team {
+		load := allocation[m.Employee.EmployeeId]
+		member := instances.TeamMember{
+			Employee:           m.Employee,
+			Allocation:         round1(load),
+			ProposedAllocation: round1(load + float64(req.Allocation)),
+			MustInclude:        mustInclude[m.Employee.EmployeeId],
+			Skills:             m.Skills,
+		}
+		if member.Skills == nil {
+			member.Skills = []instances.Skill{}
+		}
+		p.OverAllocation += max(0, load+float64(req.Allocation)-100)
+		p.Members = append(p.Members, member)
+	}
+	p.OverAllocation = round1(p.OverAllocation)
+	return p
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func memberIds(p instances.TeamProposal) []int64 {
+	ids := make([]int64, len(p.Members))
+	for i, m := range p.Members {
+		ids[i] = m.Employee.EmployeeId
+	}
+	return ids
+}
+
+func TestProposeTeams(t *testing.T) {
+	const golang, terraform = 1, 2
+	pool := []instances.EmployeeMatch{
+		member(10, instances.Skill{SkillId: golang, SkillLevel: 5}, instances.Skill{SkillId: terraform, SkillLevel: 3}),
+		member(11, instances.Skill{SkillId: golang, SkillLevel: 4}),
+		member(12, instances.Skill{SkillId: golang, SkillLevel: 4}),
+		member(13, instances.Skill{SkillId: terraform, SkillLevel: 2}),
+		member(14),
+	}
+	allocation := map[int64]float64{11: 50, 13: 60}
+	req := withTeamDefaults(instances.TeamRequest{
+		Requirements: []instances.ProjectRequirement{
+			{SkillId: golang, MinLevel: 4, Headcount: 2},
+			{SkillId: terraform, MinLevel: 2},
+		},
+		Allocation:    50,
+		MaxAllocation: 120,
+		Proposals:     3,
+	})
+
+	proposals := proposeTeams(req, pool, allocation)
+	assert.Len(t, proposals, 3)
+	// 10 fills Go and Terraform, 12 is free unlike 11
+	assert.Equal(t, []int64{10, 12}, memberIds(proposals[0]))
+	assert.True(t, proposals[0].Coverage.Complete)
+	assert.Zero(t, proposals[0].OverAllocation)
+	// without 10, the free Go developers come first and 13 is over-allocated for Terraform
+	assert.Equal(t, []int64{12, 11, 13}, memberIds(proposals[1]))
+	assert.Equal(t, 10.0, proposals[1].OverAllocation)
+	// without 10 and 12 there is one Go developer short
+	assert.Equal(t, []int64{11, 13}, memberIds(proposals[2]))
+	assert.False(t, proposals[2].Coverage.Complete)
+
+	// must-include members join regardless of their skills, and the team size is capped
+	req.MustInclude = []int64{14}
+	req.TeamSize = 2
+	req.Proposals = 1
+	proposals = proposeTeams(req, pool, allocation)
+	assert.Equal(t, []int64{14, 10}, memberIds(proposals[0]))
+	assert.True(t, proposals[0].Members[0].MustInclude)
+	assert.Equal(t, 1, proposals[0].Coverage.Gaps[0].Missing)
+
+	// the allocation cap and exclusions keep employees out
+	req = withTeamDefaults(instances.TeamRequest{
+		Requirements: []instances.ProjectRequirement{{SkillId: golang, MinLevel: 4, Headcount: 3}},
+		Exclude:      []int64{10},
+	})
+	proposals = proposeTeams(req, pool, allocation)
+	assert.Equal(t, []int64{12}, memberIds(proposals[0]))
+}
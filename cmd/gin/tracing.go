@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"os"
+	"strings"
+)
+
+const (
+	serviceName = "esm-server"
+	tracerName  = "esmAPI/cmd/gin"
+)
+
+// setupTracing installs the global tracer provider and the W3C trace context propagator.
+// exporter selects where spans go:
+//   - "none" disables tracing (spans are still propagated, but not recorded)
+//   - "otlp" sends spans to a collector over OTLP/HTTP, configured by the standard OTEL_EXPORTER_OTLP_* variables
+//   - "stdout" pretty-prints spans to stdout
+//   - "file" writes spans as JSON lines to file, so tracing can be used without a collector
+//
+// The returned function flushes pending spans and must be called on shutdown.
+func setupTracing(ctx context.Context, exporter, file string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var closeFile func() error
+	switch strings.ToLower(exporter) {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating otlp exporter: %v", err)
+		}
+		spanExporter = exp
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("creating stdout exporter: %v", err)
+		}
+		spanExporter = exp
+	case "file":
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening trace file: %v", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("creating file exporter: %v", err)
+		}
+		spanExporter = exp
+		closeFile = f.Close
+	default:
+		return nil, fmt.Errorf("invalid traces exporter %q, expected none, otlp, stdout or file", exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %v", err)
+	}
+	// resource.Default already honours OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES, let them win over our defaults
+	res, err = resource.Merge(res, resource.Environment())
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFile != nil {
+			if cerr := closeFile(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStoreSpansAreChildrenOfRequestSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	store := instrumentSkillStore(failingSkillStore{}, newMetrics(prometheus.NewRegistry()))
+	eng := gin.New()
+	eng.Use(otelgin.Middleware(serviceName))
+	eng.GET("/skills", func(context *gin.Context) {
+		_, _ = store.List(context.Request.Context())
+		context.Status(http.StatusOK)
+	})
+
+	// the incoming W3C trace context is continued
+	req, _ := http.NewRequest("GET", "/skills", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	eng.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	storeSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "skills.List", storeSpan.Name())
+	assert.Equal(t, "/skills", serverSpan.Name())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), storeSpan.Parent().SpanID())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+}
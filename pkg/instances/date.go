@@ -0,0 +1,92 @@
+package instances
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// DateLayout is how a Date is written in JSON and sent to the database
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time of day, e.g. the expiry of a certification. It is written as 2006-01-02
+// in JSON and maps to a DATE column.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the date of year, month and day
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a date written as 2006-01-02
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("%q is not a date, expected YYYY-MM-DD", s)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return fmt.Errorf("%s is not a date, expected \"YYYY-MM-DD\"", b)
+	}
+	parsed, err := ParseDate(string(b[1 : len(b)-1]))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// UnmarshalParam reads a date from a query parameter
+func (d *Date) UnmarshalParam(param string) error {
+	parsed, err := ParseDate(param)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Scan reads a DATE column, with or without the driver's parseTime option
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+		return nil
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		parsed, err := ParseDate(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into a Date", src)
+	}
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
@@ -0,0 +1,41 @@
+package instances
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	var v struct {
+		Issued  Date  `json:"issued"`
+		Expires *Date `json:"expires"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"issued": "2024-02-29", "expires": null}`), &v))
+	assert.Equal(t, NewDate(2024, time.February, 29), v.Issued)
+	assert.Nil(t, v.Expires)
+
+	out, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"issued": "2024-02-29", "expires": null}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"issued": "2024-02-30"}`), &v))
+	assert.Error(t, json.Unmarshal([]byte(`{"issued": 20240229}`), &v))
+}
+
+func TestDateScan(t *testing.T) {
+	var d Date
+	assert.NoError(t, d.Scan(time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)))
+	assert.Equal(t, "2025-03-01", d.String())
+	assert.NoError(t, d.Scan([]byte("2025-03-02")))
+	assert.Equal(t, "2025-03-02", d.String())
+	assert.Error(t, d.Scan(42))
+}
+
+func TestDateParam(t *testing.T) {
+	var d Date
+	assert.NoError(t, d.UnmarshalParam("2025-01-31"))
+	assert.Equal(t, NewDate(2025, time.January, 31), d)
+	assert.Error(t, d.UnmarshalParam("31.01.2025"))
+}
@@ -2,24 +2,103 @@ package instances
 
 //Define structs to be used for representing the db data
 //For now, I assume that struct EmployeeFull will be the "highest in hierarchy", combining all data
+//The validate tags declare the rules a payload has to satisfy, they are checked by the validation package
+
+import (
+	"encoding/json"
+	"time"
+)
 
 type Skill struct {
+	SkillId    int    `json:"skill_id" validate:"gte=0"`
+	SkillClass string `json:"skill_class" validate:"required,max=255"`
+	Skill      string `json:"skill" validate:"required,max=255"`
+	SkillLevel int    `json:"skill_level"`
+	// SkillLevelLabel is the name of SkillLevel on the scale of the skill class, e.g. B2. Only set for skills
+	// of an employee.
+	SkillLevelLabel string `json:"skill_level_label,omitempty"`
+	// CategoryId places the skill in the taxonomy, nil if it is not categorized
+	CategoryId *int64 `json:"category_id,omitempty" validate:"omitempty,gt=0"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	// ReplacedBy points a deprecated skill to the skill to use instead
+	ReplacedBy *int64 `json:"replaced_by,omitempty" validate:"omitempty,gte=0"`
+	// Aliases are alternative names of the skill, e.g. golang for Go. Read only, managed through their own endpoints.
+	Aliases []string `json:"aliases,omitempty"`
+	// LastAssessed is when SkillLevel was last assessed. Only set for skills of an employee.
+	LastAssessed *time.Time `json:"last_assessed,omitempty"`
+}
+
+// SkillCategory is a node of the skill taxonomy. Children and Skills are only filled when a tree is requested.
+type SkillCategory struct {
+	CategoryId  int64           `json:"category_id" validate:"gte=0"`
+	Name        string          `json:"name" validate:"required,max=255"`
+	ParentId    *int64          `json:"parent_id,omitempty" validate:"omitempty,gt=0"`
+	Description string          `json:"description" validate:"max=65535"`
+	Children    []SkillCategory `json:"children,omitempty"`
+	Skills      []Skill         `json:"skills,omitempty"`
+}
+
+type SkillAlias struct {
+	Alias string `json:"alias" validate:"required,max=255"`
+}
+
+// SkillAddOptions are the query parameters of adding a skill. Force adds a skill even if it resembles existing ones.
+type SkillAddOptions struct {
+	Force bool `form:"force"`
+}
+
+// SkillSuggest asks for up to Limit skills whose name or alias starts with Prefix
+type SkillSuggest struct {
+	Prefix string `form:"prefix" validate:"required,max=255"`
+	Limit  int    `form:"limit" validate:"gte=0,lte=50"`
+}
+
+// SkillSuggestion is a skill offered for a name typed by a user. Alias is set if the skill was found by an alias
+// rather than its name.
+type SkillSuggestion struct {
 	SkillId    int    `json:"skill_id"`
-	SkillClass string `json:"skill_class"`
 	Skill      string `json:"skill"`
-	SkillLevel int    `json:"skill_level"`
+	SkillClass string `json:"skill_class"`
+	Alias      string `json:"alias,omitempty"`
+}
+
+// SkillSearch selects employees by their skills. Skill matches a skill by name or alias, including deprecated
+// skills replaced by it; CategoryId matches every skill in the category or any of its subcategories.
+type SkillSearch struct {
+	Skill      string `form:"skill" validate:"max=255"`
+	CategoryId int64  `form:"category" validate:"gte=0"`
+	MinLevel   int64  `form:"min_level" validate:"gte=0"`
+	OrgFilter
+}
+
+// OrgFilter limits a search or report to a part of the organisation. Manager selects the manager and everyone
+// reporting to them directly or indirectly, Department the members of a department.
+type OrgFilter struct {
+	Manager    *int64 `form:"manager" validate:"omitempty,gte=0"`
+	Department int64  `form:"department" validate:"gte=0"`
+}
+
+// IsSet reports whether the filter limits anything
+func (f OrgFilter) IsSet() bool {
+	return f.Manager != nil || f.Department != 0
+}
+
+// EmployeeMatch is an employee found by a SkillSearch, with the skills that matched
+type EmployeeMatch struct {
+	Employee Employee `json:"employee"`
+	Skills   []Skill  `json:"matched_skills"`
 }
 type Client struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          int64  `json:"id" validate:"gte=0"`
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=65535"`
 }
 
 type Project struct {
-	ProjectId   int64  `json:"project_id"`
-	ClientId    int    `json:"client_id"`
-	FocusArea   string `json:"focus_area"`
-	Description string `json:"description"`
+	ProjectId   int64  `json:"project_id" validate:"gte=0"`
+	ClientId    int    `json:"client_id" validate:"gte=0"`
+	FocusArea   string `json:"focus_area" validate:"max=255"`
+	Description string `json:"description" validate:"max=65535"`
 	IsSecret    bool   `json:"isSecret"`
 }
 
@@ -28,28 +107,522 @@ type Project struct {
 type ProjectFull struct {
 	EmployeeRole string  `json:"employee_role"`
 	Project      Project `json:"project"`
+	AssignmentId int64   `json:"assignment_id"`
+	StartDate    *Date   `json:"start_date"`
+	EndDate      *Date   `json:"end_date"`
+	Allocation   int     `json:"allocation"`
 }
 
 type Employee struct {
-	EmployeeId int64  `json:"employee_id"`
-	Name       string `json:"name"`
-	Lastname   string `json:"lastname"`
-	FocusArea  string `json:"focus_area"`
-	Email      string `json:"email"`
+	EmployeeId int64  `json:"employee_id" validate:"gte=0"`
+	Name       string `json:"name" validate:"required,max=255"`
+	Lastname   string `json:"lastname" validate:"required,max=255"`
+	FocusArea  string `json:"focus_area" validate:"max=255"`
+	Email      string `json:"email" validate:"omitempty,email,max=255"`
+	// ManagerId is the employee this employee reports to, nil at the top of the organisation
+	ManagerId    *int64 `json:"manager_id,omitempty" validate:"omitempty,gte=0"`
+	DepartmentId *int64 `json:"department_id,omitempty" validate:"omitempty,gt=0"`
 }
 
 type EmployeeFull struct {
-	Employee Employee      `json:"employee"`
-	Skills   []Skill       `json:"skills"`
-	Projects []ProjectFull `json:"projects"`
+	Employee       Employee        `json:"employee"`
+	Skills         []Skill         `json:"skills"`
+	Projects       []ProjectFull   `json:"projects"`
+	Certifications []Certification `json:"certifications"`
 }
 
+// EmployeeSkill assigns a skill to an employee. The valid levels depend on the SkillScale of the skill's class.
+// Every assigned level is recorded as a SkillAssessment, Source, Assessor and Note describe that assessment.
 type EmployeeSkill struct {
+	SkillId    int64  `json:"skill_id" validate:"gte=0"`
+	SkillLevel int64  `json:"skill_level" validate:"gte=0"`
+	Source     string `json:"source,omitempty" validate:"omitempty,oneof=self manager peer certification"`
+	Assessor   string `json:"assessor,omitempty" validate:"max=255"`
+	Note       string `json:"note,omitempty" validate:"max=65535"`
+}
+
+// The sources of a SkillAssessment
+const (
+	AssessmentSelf          = "self"
+	AssessmentManager       = "manager"
+	AssessmentPeer          = "peer"
+	AssessmentCertification = "certification"
+	// AssessmentUnknown is recorded for levels sent without a source, it cannot be sent itself
+	AssessmentUnknown = "unknown"
+)
+
+// SkillAssessment is a dated level of an employee skill
+type SkillAssessment struct {
+	AssessmentId    int64     `json:"assessment_id"`
+	SkillLevel      int64     `json:"skill_level"`
+	SkillLevelLabel string    `json:"skill_level_label,omitempty"`
+	Source          string    `json:"source"`
+	Assessor        string    `json:"assessor,omitempty"`
+	Note            string    `json:"note,omitempty"`
+	AssessedAt      time.Time `json:"assessed_at"`
+}
+
+// SkillHistory lists the assessments of an employee skill, oldest first
+type SkillHistory struct {
+	EmployeeId int64  `json:"employee_id"`
+	SkillId    int64  `json:"skill_id"`
+	Skill      string `json:"skill"`
+	// SkillLevel is the current level, nil if the skill was removed from the employee
+	SkillLevel   *int64     `json:"skill_level"`
+	LastAssessed *time.Time `json:"last_assessed,omitempty"`
+	// Change is the difference between the latest and the first assessed level
+	Change      int64             `json:"change"`
+	Assessments []SkillAssessment `json:"assessments"`
+	// ChangeRequests are the self-assessed levels that went through approval, including pending and rejected ones
+	ChangeRequests []SkillChangeRequest `json:"change_requests,omitempty"`
+}
+
+// The states of a SkillChangeRequest
+const (
+	ChangePending  = "pending"
+	ChangeApproved = "approved"
+	ChangeRejected = "rejected"
+)
+
+// SkillChangeRequest is a self-assessed skill level waiting for, or having passed, review by a skill owner
+type SkillChangeRequest struct {
+	RequestId  int64 `json:"request_id"`
+	EmployeeId int64 `json:"employee_id"`
 	SkillId    int64 `json:"skill_id"`
 	SkillLevel int64 `json:"skill_level"`
+	// PreviousLevel is the level when the change was requested, nil if the employee did not have the skill
+	PreviousLevel *int64     `json:"previous_level"`
+	Note          string     `json:"note,omitempty"`
+	Status        string     `json:"status"`
+	RequestedAt   time.Time  `json:"requested_at"`
+	ReviewerId    *int64     `json:"reviewer_id,omitempty"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// SkillChangeFilter selects change requests, an empty Status selects pending ones
+type SkillChangeFilter struct {
+	Status     string `form:"status" validate:"omitempty,oneof=pending approved rejected"`
+	EmployeeId int64  `form:"employee_id" validate:"gte=0"`
+	SkillId    int64  `form:"skill_id" validate:"gte=0"`
+}
+
+// SkillReview approves or rejects a SkillChangeRequest. The reviewer has to own the skill or manage the employee.
+type SkillReview struct {
+	// ReviewerId is the authenticated caller, it is never read from the request
+	ReviewerId int64  `json:"-"`
+	Comment    string `json:"comment,omitempty" validate:"max=65535"`
 }
 
+// SkillOwner makes an employee responsible for reviewing the levels of a skill
+type SkillOwner struct {
+	EmployeeId int64 `json:"employee_id" validate:"gt=0"`
+}
+
+// EmployeeProject is a stint of an employee on a project. A role change ends one stint and starts the next.
 type EmployeeProject struct {
-	ProjectId   int64  `json:"project_id"`
-	ProjectRole string `json:"project_role"`
+	AssignmentId int64  `json:"assignment_id,omitempty" validate:"gte=0"`
+	ProjectId    int64  `json:"project_id" validate:"gte=0"`
+	ProjectRole  string `json:"project_role" validate:"required,max=64"`
+	// StartDate defaults to today, EndDate is nil while the stint is open-ended
+	StartDate *Date `json:"start_date,omitempty"`
+	EndDate   *Date `json:"end_date,omitempty"`
+	// Allocation is the percentage of the employee's time spent on the project, 100 unless given
+	Allocation int `json:"allocation,omitempty" validate:"gte=0,lte=100"`
+}
+
+// The states of an assignment relative to today
+const (
+	AssignmentCurrent  = "current"
+	AssignmentPast     = "past"
+	AssignmentUpcoming = "upcoming"
+)
+
+// AssignmentFilter selects the stints of an employee, an empty Projects selects all of them
+type AssignmentFilter struct {
+	Projects string `form:"projects" validate:"omitempty,oneof=current past upcoming"`
+}
+
+// SkillScale defines the levels of all skills of a skill class, e.g. CEFR for languages
+type SkillScale struct {
+	SkillClass  string            `json:"skill_class" validate:"required,max=255"`
+	Name        string            `json:"name" validate:"required,max=64"`
+	Description string            `json:"description" validate:"max=65535"`
+	Levels      []SkillScaleLevel `json:"levels" validate:"required,min=1,unique=Level,dive"`
+}
+
+type SkillScaleLevel struct {
+	Level       int    `json:"level" validate:"gte=0"`
+	Label       string `json:"label" validate:"required,max=64"`
+	Description string `json:"description" validate:"max=65535"`
+}
+
+// DefaultSkillScale applies to every skill class without a scale of its own
+var DefaultSkillScale = SkillScale{
+	Name:        "Default",
+	Description: "Generic five level scale",
+	Levels: []SkillScaleLevel{
+		{Level: 1, Label: "Novice", Description: "Basic knowledge, needs guidance"},
+		{Level: 2, Label: "Beginner", Description: "Handles simple tasks on their own"},
+		{Level: 3, Label: "Competent", Description: "Works independently on most tasks"},
+		{Level: 4, Label: "Proficient", Description: "Handles complex tasks and guides others"},
+		{Level: 5, Label: "Expert", Description: "Recognized authority on the skill"},
+	},
+}
+
+// Find returns the definition of level on the scale
+func (s SkillScale) Find(level int) (SkillScaleLevel, bool) {
+	for _, l := range s.Levels {
+		if l.Level == level {
+			return l, true
+		}
+	}
+	return SkillScaleLevel{}, false
+}
+
+// Certification is a credential held by an employee, e.g. AWS Solutions Architect. SkillId optionally links the
+// skill it proves.
+type Certification struct {
+	CertificationId int64  `json:"certification_id" validate:"gte=0"`
+	EmployeeId      int64  `json:"employee_id" validate:"gt=0"`
+	SkillId         *int64 `json:"skill_id,omitempty" validate:"omitempty,gt=0"`
+	Name            string `json:"name" validate:"required,max=255"`
+	Issuer          string `json:"issuer" validate:"required,max=255"`
+	CredentialId    string `json:"credential_id,omitempty" validate:"max=255"`
+	IssuedOn        Date   `json:"issued_on" validate:"required"`
+	// ExpiresOn is nil for certifications that do not expire
+	ExpiresOn *Date `json:"expires_on,omitempty"`
+	// Evidence references the stored certificate, e.g. a document URL
+	Evidence string `json:"evidence,omitempty" validate:"max=2048"`
+	// Expired is computed on read
+	Expired bool `json:"expired"`
+}
+
+// CertificationFilter selects certifications, zero values select everything
+type CertificationFilter struct {
+	EmployeeId int64  `form:"employee_id" validate:"gte=0"`
+	SkillId    int64  `form:"skill_id" validate:"gte=0"`
+	Name       string `form:"name" validate:"max=255"`
+}
+
+// ExpiringFilter selects the certifications expiring within Days from today, 90 unless given
+type ExpiringFilter struct {
+	Days int `form:"days,default=90" validate:"gte=0,lte=3650"`
+	// IncludeExpired adds the certifications that have already expired
+	IncludeExpired bool `form:"include_expired"`
+}
+
+// CertifiedHeadcount is the number of employees holding a valid certification
+type CertifiedHeadcount struct {
+	Name      string `json:"name"`
+	Issuer    string `json:"issuer"`
+	Employees int64  `json:"employees"`
+}
+
+// CapacityFilter selects the period of a capacity report, the current month unless given
+type CapacityFilter struct {
+	From *Date `form:"from"`
+	To   *Date `form:"to"`
+	// Overbooked limits the utilization report to the employees allocated above 100% on some day
+	Overbooked bool `form:"overbooked"`
+	// Threshold is the average allocation in percent up to which an employee is counted as on the bench
+	Threshold int `form:"threshold" validate:"gte=0,lte=100"`
+	OrgFilter
+}
+
+// Utilization is the allocation of an employee over the period of a capacity report, in percent
+type Utilization struct {
+	Employee Employee `json:"employee"`
+	// Allocation is the average over all days of the period
+	Allocation     float64 `json:"allocation"`
+	PeakAllocation int     `json:"peak_allocation"`
+	// OverbookedDays counts the days the employee is allocated above 100%
+	OverbookedDays int  `json:"overbooked_days"`
+	Overbooked     bool `json:"overbooked"`
+}
+
+// BenchEmployee is an employee with little or no allocation over the period of a capacity report
+type BenchEmployee struct {
+	Employee   Employee `json:"employee"`
+	Allocation float64  `json:"allocation"`
+	// TopSkills are the highest rated skills of the employee, to find work matching them
+	TopSkills []Skill `json:"top_skills"`
+}
+
+// FocusAreaUtilization is the staffing of the projects of a focus area in one month
+type FocusAreaUtilization struct {
+	Month     string `json:"month"`
+	FocusArea string `json:"focus_area"`
+	// FTE is the number of full-time employees the allocations add up to
+	FTE float64 `json:"fte"`
+	// Utilization is FTE as a percentage of all employees
+	Utilization float64 `json:"utilization"`
+}
+
+// ProjectRequirement is a skill a project needs, e.g. two Go developers of at least level 4
+type ProjectRequirement struct {
+	RequirementId int64 `json:"requirement_id" validate:"gte=0"`
+	// ProjectId is taken from the path
+	ProjectId int64 `json:"project_id" validate:"gte=0"`
+	SkillId   int64 `json:"skill_id" validate:"gt=0"`
+	// Skill is the name of the skill, read only
+	Skill string `json:"skill,omitempty"`
+	// MinLevel is the lowest skill level filling the requirement, 0 for any level
+	MinLevel int64 `json:"min_level" validate:"gte=0"`
+	// Headcount is how many employees the project needs with the skill, 1 unless given
+	Headcount int `json:"headcount" validate:"gte=0,lte=1000"`
+}
+
+// RequirementCoverage is how many members of a project's team fill a requirement
+type RequirementCoverage struct {
+	Requirement ProjectRequirement `json:"requirement"`
+	Covered     int                `json:"covered"`
+	// Missing is how many more employees the requirement needs
+	Missing int `json:"missing"`
+	// Employees are the members of the team filling the requirement
+	Employees []Employee `json:"employees"`
+}
+
+// ProjectCoverage compares the requirements of a project against the skills of the employees currently on it
+type ProjectCoverage struct {
+	// ProjectId is 0 for the coverage of a proposed team
+	ProjectId int64 `json:"project_id,omitempty"`
+	// Complete is true if the team fills every requirement
+	Complete     bool                  `json:"complete"`
+	Requirements []RequirementCoverage `json:"requirements"`
+	// Gaps are the requirements the team does not fill
+	Gaps []RequirementCoverage `json:"gaps"`
+}
+
+// CandidateFilter selects the period the availability of candidates is judged on, the next 30 days unless given,
+// and how many candidates are listed
+type CandidateFilter struct {
+	From  *Date `form:"from"`
+	Days  int   `form:"days,default=30" validate:"gte=1,lte=366"`
+	Limit int   `form:"limit,default=20" validate:"gte=1,lte=500"`
+	// IncludeTeam keeps the employees currently on the project in the ranking
+	IncludeTeam bool `form:"include_team"`
+}
+
+// SkillMatch compares the level of an employee with the minimum level a requirement asks for
+type SkillMatch struct {
+	SkillId  int64  `json:"skill_id"`
+	Skill    string `json:"skill"`
+	MinLevel int64  `json:"min_level"`
+	// Level is the employee's level of the skill, 0 if they do not have it
+	Level int64 `json:"level"`
+}
+
+// Candidate is an employee ranked for the open roles of a project. Score is the sum of the partial scores, which
+// explain how it came about.
+type Candidate struct {
+	Employee          Employee `json:"employee"`
+	Score             float64  `json:"score"`
+	SkillScore        float64  `json:"skill_score"`
+	ExperienceScore   float64  `json:"experience_score"`
+	AvailabilityScore float64  `json:"availability_score"`
+	// Matched are the requirements the employee fills, Missing those they do not
+	Matched []SkillMatch `json:"matched_skills"`
+	Missing []SkillMatch `json:"missing_skills"`
+	// RelevantProjects are the other projects of the same focus area the employee worked on, except secret ones
+	RelevantProjects []int64 `json:"relevant_projects"`
+	// Availability is the percentage of the employee's time not allocated over the period
+	Availability float64 `json:"availability"`
+}
+
+// TeamRequest asks for teams filling a set of requirements. Availability is judged on the period of Days from From,
+// the next 30 days unless given.
+type TeamRequest struct {
+	Requirements []ProjectRequirement `json:"requirements" validate:"required,min=1,max=50,dive"`
+	// TeamSize caps the number of members, 0 for as many as the requirements need
+	TeamSize int `json:"team_size" validate:"gte=0,lte=100"`
+	// Allocation is the percentage of their time the members spend on the team, 100 unless given
+	Allocation int `json:"allocation" validate:"gte=0,lte=100"`
+	// MaxAllocation caps the allocation of a member including the team, 100 unless given. Must-include members
+	// join regardless.
+	MaxAllocation int     `json:"max_allocation" validate:"gte=0,lte=200"`
+	Exclude       []int64 `json:"exclude" validate:"unique"`
+	MustInclude   []int64 `json:"must_include" validate:"unique,max=100"`
+	// Proposals is how many alternative teams to propose at most, 1 unless given
+	Proposals int   `json:"proposals" validate:"gte=0,lte=10"`
+	From      *Date `json:"from"`
+	Days      int   `json:"days" validate:"gte=0,lte=366"`
+}
+
+// TeamMember is an employee in a proposed team
+type TeamMember struct {
+	Employee Employee `json:"employee"`
+	// Allocation is the average allocation of the employee over the period before joining the team, in percent
+	Allocation float64 `json:"allocation"`
+	// ProposedAllocation is Allocation with the team's share added
+	ProposedAllocation float64 `json:"proposed_allocation"`
+	MustInclude        bool    `json:"must_include,omitempty"`
+	// Skills are the skills of the employee the requirements ask for
+	Skills []Skill `json:"skills"`
+}
+
+// TeamProposal is a team proposed for a TeamRequest with how it covers the requirements
+type TeamProposal struct {
+	Members  []TeamMember    `json:"members"`
+	Coverage ProjectCoverage `json:"coverage"`
+	// OverAllocation sums the percentage points the members are allocated above 100 with the team
+	OverAllocation float64 `json:"over_allocation"`
+}
+
+// Department is an org unit employees belong to
+type Department struct {
+	DepartmentId int64  `json:"department_id" validate:"gte=0"`
+	Name         string `json:"name" validate:"required,max=255"`
+	Description  string `json:"description" validate:"max=65535"`
+}
+
+// OrgNode is an employee with everyone reporting to them
+type OrgNode struct {
+	Employee Employee  `json:"employee"`
+	Reports  []OrgNode `json:"reports,omitempty"`
+}
+
+// The formats graphs are exported in
+const (
+	GraphDOT     = "dot"
+	GraphMermaid = "mermaid"
+	GraphGEXF    = "gexf"
+)
+
+// GraphFilter selects what a graph export shows. Client and Project keep the employees staffed on them, Projects
+// limits the assignments considered like AssignmentFilter.
+type GraphFilter struct {
+	OrgFilter
+	Client   *int64 `form:"client" validate:"omitempty,gte=0"`
+	Project  *int64 `form:"project" validate:"omitempty,gte=0"`
+	Projects string `form:"projects" validate:"omitempty,oneof=current past upcoming"`
+	Format   string `form:"format" validate:"omitempty,oneof=dot mermaid gexf"`
+}
+
+// The formats CVs are rendered in
+const (
+	CVMarkdown = "markdown"
+	CVHTML     = "html"
+	CVPDF      = "pdf"
+)
+
+// CVOptions selects the format of a CV, the Accept header decides if it is empty
+type CVOptions struct {
+	Format string `form:"format" validate:"omitempty,oneof=markdown html pdf"`
+}
+
+// CVBatch asks for the CVs of several employees in one ZIP archive
+type CVBatch struct {
+	EmployeeIds []int64 `json:"employee_ids" validate:"required,min=1,max=100,unique,dive,gte=0"`
+	Format      string  `json:"format" validate:"omitempty,oneof=markdown html pdf"`
+}
+
+// CV is the data CV templates are executed with
+type CV struct {
+	Employee       Employee
+	SkillGroups    []CVSkillGroup
+	Projects       []CVProject
+	Certifications []Certification
+}
+
+// CVSkillGroup lists the skills of one skill class, the strongest first
+type CVSkillGroup struct {
+	SkillClass string
+	Skills     []Skill
+}
+
+// CVProject is a stint on a project that is not secret, Client is empty for projects without one
+type CVProject struct {
+	ProjectId   int64
+	Role        string
+	Client      string
+	FocusArea   string
+	Description string
+	StartDate   *Date
+	EndDate     *Date
+}
+
+// The kinds of entities the search finds
+const (
+	SearchEmployee = "employee"
+	SearchSkill    = "skill"
+	SearchProject  = "project"
+	SearchClient   = "client"
+)
+
+// SearchQuery searches all entities, or those of Kind, for the words of Q
+type SearchQuery struct {
+	Q     string `form:"q" validate:"required,max=255"`
+	Kind  string `form:"kind" validate:"omitempty,oneof=employee skill project client"`
+	Limit int    `form:"limit" validate:"gte=0,lte=100"`
+}
+
+// SearchHit is an entity matching a search, the best matches score highest
+type SearchHit struct {
+	Kind  string  `json:"kind"`
+	Id    int64   `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+	// Fields are the fields the query matched
+	Fields []string `json:"matched_fields"`
+}
+
+// The kinds of records that can be merged
+const (
+	MergeSkill    = "skill"
+	MergeEmployee = "employee"
+	MergeClient   = "client"
+)
+
+// The policies resolving conflicts of a merge, i.e. rows both the merged and the kept record have
+const (
+	// MergeKeepMax keeps the higher skill level or headcount, overlapping stints are joined into one
+	MergeKeepMax = "keep_max"
+	// MergeKeepTarget keeps the values and the stints of the record merged into
+	MergeKeepTarget = "keep_target"
+	// MergeKeepSource keeps the values and the stints of the merged record
+	MergeKeepSource = "keep_source"
+)
+
+// MergeRequest merges a duplicate record into the one to keep. Policy defaults to keep_max, MergedBy names who
+// merged for the audit trail.
+type MergeRequest struct {
+	Policy   string `json:"policy" validate:"omitempty,oneof=keep_max keep_target keep_source"`
+	MergedBy string `json:"merged_by" validate:"required,max=255"`
+	Note     string `json:"note,omitempty" validate:"max=65535"`
+}
+
+// Merge is an entry of the audit trail of merges. Source is the merged record as it was before the merge deleted
+// it. Moved counts the references repointed from the source to the target by table and column, Conflicts the rows
+// both had.
+type Merge struct {
+	MergeId   int64            `json:"merge_id"`
+	Entity    string           `json:"entity"`
+	SourceId  int64            `json:"source_id"`
+	TargetId  int64            `json:"target_id"`
+	Policy    string           `json:"policy"`
+	MergedBy  string           `json:"merged_by"`
+	Note      string           `json:"note,omitempty"`
+	Source    json.RawMessage  `json:"source"`
+	Moved     map[string]int64 `json:"moved"`
+	Conflicts int64            `json:"conflicts"`
+	MergedAt  time.Time        `json:"merged_at"`
+}
+
+// MergeFilter selects entries of the audit trail. Id matches merges from or into the record.
+type MergeFilter struct {
+	Entity string `form:"entity" validate:"omitempty,oneof=skill employee client"`
+	Id     int64  `form:"id" validate:"gte=0"`
+}
+
+// RekeyRequest changes the id of a record to NewId
+type RekeyRequest struct {
+	NewId int64 `json:"new_id" validate:"gte=0"`
+}
+
+// Rekey is a changed id. Moved counts the references repointed to the new id by table and column.
+type Rekey struct {
+	OldId int64            `json:"old_id"`
+	NewId int64            `json:"new_id"`
+	Moved map[string]int64 `json:"moved"`
 }
@@ -0,0 +1,62 @@
+package instances
+
+// JSONResumeSchema is the version of the JSON Resume schema (jsonresume.org) exported resumes follow
+const JSONResumeSchema = "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json"
+
+// Resume is a JSON Resume document. Only the sections the API has data for are modelled, others are ignored on
+// import and left out on export.
+type Resume struct {
+	Schema       string              `json:"$schema,omitempty"`
+	Basics       ResumeBasics        `json:"basics"`
+	Work         []ResumeWork        `json:"work,omitempty" validate:"max=500,dive"`
+	Skills       []ResumeSkill       `json:"skills,omitempty" validate:"max=500,dive"`
+	Certificates []ResumeCertificate `json:"certificates,omitempty" validate:"max=500,dive"`
+}
+
+// ResumeBasics identifies the person. Label is their job title, the focus area of an employee.
+type ResumeBasics struct {
+	Name  string `json:"name" validate:"required,max=511"`
+	Label string `json:"label,omitempty" validate:"max=255"`
+	Email string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+}
+
+// ResumeWork is a position, Name is the client of the project. Dates are ISO 8601, e.g. 2023-04-01.
+type ResumeWork struct {
+	Name      string `json:"name,omitempty"`
+	Position  string `json:"position,omitempty"`
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+	Summary   string `json:"summary,omitempty"`
+}
+
+// ResumeSkill is a skill with its level, either a level number or a label on the scale of the skill, e.g. B2.
+// Keywords hold the skill class on export and are ignored on import.
+type ResumeSkill struct {
+	Name     string   `json:"name" validate:"required,max=255"`
+	Level    string   `json:"level,omitempty" validate:"max=64"`
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type ResumeCertificate struct {
+	Name   string `json:"name"`
+	Date   string `json:"date,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// UnmappedSkill is a skill of an imported resume that was not assigned, Reason tells why. Unknown skills can be
+// mapped by adding them as aliases of existing skills and importing again.
+type UnmappedSkill struct {
+	Name   string `json:"name"`
+	Level  string `json:"level,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// ResumeImport is the outcome of importing a resume. Skills needing approval are listed in PendingRequests.
+type ResumeImport struct {
+	EmployeeId      int64           `json:"employee_id"`
+	Created         bool            `json:"created"`
+	Imported        []EmployeeSkill `json:"imported_skills"`
+	PendingRequests []int64         `json:"pending_requests,omitempty"`
+	Unmapped        []UnmappedSkill `json:"unmapped_skills"`
+}
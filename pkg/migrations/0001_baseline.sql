@@ -0,0 +1,48 @@
+-- Baseline schema, identical to sql/esm-createdata.sql. Tables are only created when missing,
+-- so databases set up with that script are adopted as they are.
+CREATE TABLE IF NOT EXISTS Clients (
+    id INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS Projects (
+    project_id INT PRIMARY KEY,
+    client_id INT,
+    focus_area VARCHAR(255),
+    description TEXT,
+    isSecret BOOLEAN,
+    FOREIGN KEY (client_id) REFERENCES Clients(id)
+);
+
+CREATE TABLE IF NOT EXISTS Employees (
+    employee_id INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    lastname VARCHAR(255) NOT NULL,
+    focus_area VARCHAR(255),
+    email VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS ProjectDetails (
+    project_id INT,
+    employee_id INT,
+    PRIMARY KEY (project_id, employee_id),
+    employee_role VARCHAR(64),
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id),
+    FOREIGN KEY (project_id) REFERENCES Projects(project_id)
+);
+
+CREATE TABLE IF NOT EXISTS Skills (
+    skill_id INT PRIMARY KEY,
+    skill_class VARCHAR(255),
+    skill VARCHAR(255)
+);
+
+CREATE TABLE IF NOT EXISTS EmployeeSkills (
+    employee_id INT,
+    skill_id INT,
+    PRIMARY KEY (skill_id, employee_id),
+    skill_level INT,
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id),
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id)
+);
@@ -0,0 +1,27 @@
+-- Skill scales give the levels of a skill class a meaning. Classes without a scale use the default 1-5 scale
+-- defined in the instances package.
+CREATE TABLE IF NOT EXISTS SkillScales (
+    skill_class VARCHAR(255) PRIMARY KEY,       -- the skill class the scale applies to
+    name VARCHAR(64) NOT NULL,                  -- name of the scale, e.g. CEFR
+    description TEXT
+);
+
+CREATE TABLE IF NOT EXISTS SkillScaleLevels (
+    skill_class VARCHAR(255),
+    level INT,                                  -- the numeric level stored in EmployeeSkills.skill_level
+    label VARCHAR(64) NOT NULL,                 -- e.g. B2
+    description TEXT,
+    PRIMARY KEY (skill_class, level),
+    FOREIGN KEY (skill_class) REFERENCES SkillScales(skill_class) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO SkillScales (skill_class, name, description) VALUES
+('Language', 'CEFR', 'Common European Framework of Reference for Languages');
+
+INSERT IGNORE INTO SkillScaleLevels (skill_class, level, label, description) VALUES
+('Language', 1, 'A1', 'Beginner'),
+('Language', 2, 'A2', 'Elementary'),
+('Language', 3, 'B1', 'Intermediate'),
+('Language', 4, 'B2', 'Upper intermediate'),
+('Language', 5, 'C1', 'Advanced'),
+('Language', 6, 'C2', 'Proficient');
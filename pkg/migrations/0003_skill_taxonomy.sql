@@ -0,0 +1,21 @@
+-- Skill taxonomy: nested categories, aliases and deprecation of skills
+CREATE TABLE IF NOT EXISTS SkillCategories (
+    category_id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    parent_id INT,                              -- NULL for top level categories
+    description TEXT,
+    FOREIGN KEY (parent_id) REFERENCES SkillCategories(category_id)
+);
+
+ALTER TABLE Skills
+    ADD COLUMN category_id INT,
+    ADD COLUMN deprecated BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN replaced_by INT,                 -- the skill to use instead of a deprecated one
+    ADD FOREIGN KEY (category_id) REFERENCES SkillCategories(category_id),
+    ADD FOREIGN KEY (replaced_by) REFERENCES Skills(skill_id);
+
+CREATE TABLE IF NOT EXISTS SkillAliases (
+    alias VARCHAR(255) PRIMARY KEY,             -- unique regardless of case, e.g. golang
+    skill_id INT NOT NULL,
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id) ON DELETE CASCADE
+);
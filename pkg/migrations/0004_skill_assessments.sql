@@ -0,0 +1,19 @@
+-- Skill assessments: every level given to an employee skill, EmployeeSkills keeps the current one
+CREATE TABLE IF NOT EXISTS SkillAssessments (
+    assessment_id INT PRIMARY KEY AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    skill_id INT NOT NULL,
+    skill_level INT NOT NULL,
+    source ENUM('self', 'manager', 'peer', 'certification') NOT NULL DEFAULT 'manager',
+    assessor VARCHAR(255),                      -- who assessed the level, NULL if unknown
+    note TEXT,
+    assessed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (employee_id, skill_id, assessed_at),
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id) ON DELETE CASCADE,
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id) ON DELETE CASCADE
+);
+
+-- levels assigned before assessments were recorded become the first assessment
+INSERT INTO SkillAssessments (employee_id, skill_id, skill_level, note)
+    SELECT employee_id, skill_id, skill_level, 'recorded before assessment history' FROM EmployeeSkills
+    WHERE skill_level IS NOT NULL;
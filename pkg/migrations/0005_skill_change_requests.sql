@@ -0,0 +1,26 @@
+-- Approval workflow: owners of a skill review the levels employees assess themselves
+CREATE TABLE IF NOT EXISTS SkillOwners (
+    skill_id INT NOT NULL,
+    employee_id INT NOT NULL,
+    PRIMARY KEY (skill_id, employee_id),
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id) ON DELETE CASCADE,
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS SkillChangeRequests (
+    request_id INT PRIMARY KEY AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    skill_id INT NOT NULL,
+    skill_level INT NOT NULL,
+    previous_level INT,                         -- NULL if the employee did not have the skill yet
+    note TEXT,
+    status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    requested_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    reviewer_id INT,
+    review_comment TEXT,
+    reviewed_at DATETIME,
+    INDEX (status, requested_at),
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id) ON DELETE CASCADE,
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES Employees(employee_id) ON DELETE SET NULL
+);
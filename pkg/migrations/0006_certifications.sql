@@ -0,0 +1,15 @@
+-- Certifications of employees, optionally proving a skill
+CREATE TABLE IF NOT EXISTS Certifications (
+    certification_id INT PRIMARY KEY AUTO_INCREMENT,
+    employee_id INT NOT NULL,
+    skill_id INT,                               -- the skill the certification proves, if any
+    name VARCHAR(255) NOT NULL,                 -- e.g. Certified Kubernetes Administrator
+    issuer VARCHAR(255) NOT NULL,
+    credential_id VARCHAR(255),
+    issued_on DATE NOT NULL,
+    expires_on DATE,                            -- NULL for certifications that do not expire
+    evidence VARCHAR(2048),                     -- reference to the stored certificate, e.g. a document URL
+    INDEX (expires_on),
+    FOREIGN KEY (employee_id) REFERENCES Employees(employee_id) ON DELETE CASCADE,
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id) ON DELETE SET NULL
+);
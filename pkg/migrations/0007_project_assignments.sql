@@ -0,0 +1,11 @@
+-- Staffing: an employee can work on a project in several consecutive stints, each with its own role, dates and
+-- allocation. Assignments made before have no known start.
+ALTER TABLE ProjectDetails
+    ADD COLUMN assignment_id INT NOT NULL AUTO_INCREMENT FIRST,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (assignment_id),
+    ADD INDEX (project_id, employee_id),
+    ADD INDEX (employee_id, start_date),
+    ADD COLUMN start_date DATE,                 -- NULL if the start is unknown
+    ADD COLUMN end_date DATE,                   -- NULL while the assignment is open-ended
+    ADD COLUMN allocation TINYINT NOT NULL DEFAULT 100; -- percent of the employee's time
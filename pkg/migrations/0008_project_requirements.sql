@@ -0,0 +1,11 @@
+-- Skills a project needs, e.g. two Go developers of at least level 4
+CREATE TABLE IF NOT EXISTS ProjectRequirements (
+    requirement_id INT PRIMARY KEY AUTO_INCREMENT,
+    project_id INT NOT NULL,
+    skill_id INT NOT NULL,
+    min_level INT NOT NULL DEFAULT 0,           -- the lowest skill level that fills the requirement
+    headcount INT NOT NULL DEFAULT 1,           -- how many employees the project needs with the skill
+    UNIQUE (project_id, skill_id, min_level),
+    FOREIGN KEY (project_id) REFERENCES Projects(project_id) ON DELETE CASCADE,
+    FOREIGN KEY (skill_id) REFERENCES Skills(skill_id)
+);
@@ -0,0 +1,12 @@
+-- Org structure: employees belong to a department and report to a manager
+CREATE TABLE IF NOT EXISTS Departments (
+    department_id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT
+);
+
+ALTER TABLE Employees
+    ADD COLUMN manager_id INT,                  -- NULL at the top of the organisation
+    ADD COLUMN department_id INT,
+    ADD FOREIGN KEY (manager_id) REFERENCES Employees(employee_id) ON DELETE SET NULL,
+    ADD FOREIGN KEY (department_id) REFERENCES Departments(department_id);
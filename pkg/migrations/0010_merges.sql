@@ -0,0 +1,16 @@
+-- Audit trail of merged duplicates. A merge deletes the merged record, so a copy of it is kept here.
+CREATE TABLE IF NOT EXISTS Merges (
+    merge_id INT PRIMARY KEY AUTO_INCREMENT,
+    entity ENUM('skill', 'employee', 'client') NOT NULL,
+    source_id INT NOT NULL,                     -- the merged record, gone after the merge
+    target_id INT NOT NULL,                     -- the record kept
+    policy VARCHAR(32) NOT NULL,                -- how conflicts were resolved, e.g. keep_max
+    merged_by VARCHAR(255) NOT NULL,
+    note TEXT,
+    source JSON NOT NULL,                       -- the merged record as it was
+    moved JSON NOT NULL,                        -- references repointed to the target, by table and column
+    conflicts INT NOT NULL DEFAULT 0,
+    merged_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX (entity, source_id),
+    INDEX (entity, target_id)
+);
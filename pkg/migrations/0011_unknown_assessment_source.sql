@@ -0,0 +1,7 @@
+-- Assessments recorded without a source are of an unknown source rather than the manager's
+ALTER TABLE SkillAssessments
+    MODIFY source ENUM('self', 'manager', 'peer', 'certification', 'unknown') NOT NULL DEFAULT 'unknown';
+
+-- the levels backfilled when assessments were introduced were never given a source
+UPDATE SkillAssessments SET source = 'unknown'
+    WHERE assessor IS NULL AND note = 'recorded before assessment history';
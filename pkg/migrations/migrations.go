@@ -0,0 +1,164 @@
+// Package migrations holds the versioned database schema changes and applies them.
+//
+// Every migration is a file named <version>_<name>.sql, e.g. 0002_skill_scales.sql. Versions are applied in
+// ascending order and recorded in the schema_migrations table, so each runs exactly once per database.
+// MySQL commits DDL implicitly, so a migration is not atomic: keep them small and make them safe to re-run
+// where possible (IF NOT EXISTS and the like).
+package migrations
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// lockName is the MySQL named lock held while migrating, so that two servers starting at once don't race
+const lockName = "esm_schema_migrations"
+
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+// All returns the embedded migrations ordered by version
+func All() ([]Migration, error) {
+	return load(files)
+}
+
+// Latest returns the version of the newest embedded migration, i.e. the schema version this build expects
+func Latest() int {
+	all, err := All()
+	if err != nil || len(all) == 0 {
+		return 0
+	}
+	return all[len(all)-1].Version
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, name := range names {
+		prefix, rest, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must look like 0001_name.sql", name)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: rest, Statements: splitStatements(string(content))})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements splits a SQL script into single statements. A statement ends with a line ending in a semicolon,
+// lines starting with -- are comments. This is enough for our migrations and avoids enabling multiStatements
+// on the connection.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(line, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Current returns the highest version applied to db, 0 if none is
+func Current(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("reading schema version: %v", err)
+	}
+	return version, nil
+}
+
+// Apply runs all migrations newer than the current version of db and returns the versions it applied
+func Apply(ctx context.Context, db *sql.DB) ([]int, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+
+	// named locks are bound to a connection, so everything has to run on the same one
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", lockName).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("acquiring migration lock: %v", err)
+	}
+	if locked.Int64 != 1 {
+		return nil, fmt.Errorf("acquiring migration lock: timed out")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	_, err = conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version INT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)")
+	if err != nil {
+		return nil, fmt.Errorf("creating schema_migrations: %v", err)
+	}
+
+	var current int
+	err = conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema version: %v", err)
+	}
+
+	var applied []int
+	for _, m := range all {
+		if m.Version <= current {
+			continue
+		}
+		for i, stmt := range m.Statements {
+			if _, err := conn.ExecContext(ctx, stmt); err != nil {
+				return applied, fmt.Errorf("migration %04d_%s, statement %d: %v", m.Version, m.Name, i+1, err)
+			}
+		}
+		_, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES (?, ?)", m.Version, m.Name)
+		if err != nil {
+			return applied, fmt.Errorf("recording migration %04d_%s: %v", m.Version, m.Name, err)
+		}
+		applied = append(applied, m.Version)
+	}
+	return applied, nil
+}
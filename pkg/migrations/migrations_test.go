@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- a comment
+CREATE TABLE A (
+    id INT PRIMARY KEY
+);
+
+ALTER TABLE A ADD COLUMN name VARCHAR(64);
+INSERT INTO A (id) VALUES (1)`
+
+	statements := splitStatements(script)
+	assert.Equal(t, []string{
+		"CREATE TABLE A (\nid INT PRIMARY KEY\n)",
+		"ALTER TABLE A ADD COLUMN name VARCHAR(64)",
+		"INSERT INTO A (id) VALUES (1)",
+	}, statements)
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_later.sql":  {Data: []byte("SELECT 10;")},
+		"0002_second.sql": {Data: []byte("SELECT 2;")},
+		"0001_first.sql":  {Data: []byte("SELECT 1;")},
+	}
+	all, err := load(fsys)
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, []int{1, 2, 10}, []int{all[0].Version, all[1].Version, all[2].Version})
+	assert.Equal(t, "later", all[2].Name)
+}
+
+func TestLoadRejectsBadNames(t *testing.T) {
+	_, err := load(fstest.MapFS{"baseline.sql": {Data: []byte("SELECT 1;")}})
+	assert.Error(t, err)
+
+	_, err = load(fstest.MapFS{
+		"0001_a.sql": {Data: []byte("SELECT 1;")},
+		"01_b.sql":   {Data: []byte("SELECT 1;")},
+	})
+	assert.Error(t, err)
+}
+
+func TestEmbeddedMigrationsAreValid(t *testing.T) {
+	all, err := All()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, all)
+	for i, m := range all {
+		assert.Equal(t, i+1, m.Version, "migration versions must have no gaps")
+		assert.NotEmpty(t, m.Statements)
+	}
+	assert.Equal(t, all[len(all)-1].Version, Latest())
+}
@@ -0,0 +1,349 @@
+// Package patch applies JSON Merge Patch (RFC 7386) and JSON Patch (RFC 6902) documents to JSON documents.
+//
+// Numbers are decoded as float64, so integers are exact up to 2^53.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The media types of the patch formats
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedType is returned for patches of any other media type
+	ErrUnsupportedType = errors.New("patches must be application/merge-patch+json or application/json-patch+json")
+	// ErrInvalid is returned, wrapped, for malformed patches and operations on paths that do not exist
+	ErrInvalid = errors.New("invalid patch")
+	// ErrTestFailed is returned, wrapped, when a test operation finds another value
+	ErrTestFailed = errors.New("patch test failed")
+)
+
+// Apply patches doc with patch of the media type contentType
+func Apply(contentType string, doc []byte, patch []byte) ([]byte, error) {
+	switch contentType {
+	case MergePatchType:
+		return MergePatch(doc, patch)
+	case JSONPatchType:
+		return JSONPatch(doc, patch)
+	default:
+		return nil, ErrUnsupportedType
+	}
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalid, fmt.Sprintf(format, args...))
+}
+
+// MergePatch applies a JSON Merge Patch: members of patch replace those of doc, recursively for objects, and null
+// members remove them
+func MergePatch(doc []byte, patch []byte) ([]byte, error) {
+	var target, p any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, invalid("%v", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], value)
+		}
+	}
+	return t
+}
+
+// Operation is an operation of a JSON Patch
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch applies the operations of a JSON Patch in order. If one fails, the others are not applied either.
+func JSONPatch(doc []byte, patch []byte) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, invalid("%v", err)
+	}
+	for i, op := range ops {
+		var err error
+		if root, err = apply(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func apply(root any, op Operation) (any, error) {
+	path, err := pointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, invalid("%s needs a value", op.Op)
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, invalid("%v", err)
+		}
+	case "move", "copy":
+		from, err := pointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, invalid("cannot move %s into itself", op.From)
+			}
+			return move(root, from, path)
+		}
+		if value, err = get(root, from); err != nil {
+			return nil, err
+		}
+		return add(root, path, clone(value))
+	}
+
+	switch op.Op {
+	case "add":
+		return add(root, path, value)
+	case "remove":
+		root, _, err := remove(root, path)
+		return root, err
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		return walk(root, path, func(parent any, key string) (any, error) {
+			switch p := parent.(type) {
+			case map[string]any:
+				if _, ok := p[key]; !ok {
+					return nil, invalid("%s does not exist", op.Path)
+				}
+				p[key] = value
+				return p, nil
+			case []any:
+				i, err := index(key, len(p), false)
+				if err != nil {
+					return nil, err
+				}
+				p[i] = value
+				return p, nil
+			}
+			return nil, invalid("%s is not in an object or array", op.Path)
+		})
+	case "test":
+		current, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("%w: %s", ErrTestFailed, op.Path)
+		}
+		return root, nil
+	default:
+		return nil, invalid("unknown op %q", op.Op)
+	}
+}
+
+// pointer splits a JSON Pointer into its reference tokens
+func pointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, invalid("pointer %q does not start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// index reads an array index of an array of n elements. end allows "-" and n, the index past the last element.
+func index(token string, n int, end bool) (int, error) {
+	if end && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, invalid("%q is not an array index", token)
+	}
+	if i > n || i == n && !end {
+		return 0, invalid("index %d is out of range", i)
+	}
+	return i, nil
+}
+
+// walk calls leaf on the parent of the value path points to and the last token of path, and returns node with the
+// parent replaced by what leaf returns
+func walk(node any, path []string, leaf func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return leaf(node, path[0])
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, invalid("%s does not exist", path[0])
+		}
+		c, err := walk(child, path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = c
+		return n, nil
+	case []any:
+		i, err := index(path[0], len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		c, err := walk(n[i], path[1:], leaf)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = c
+		return n, nil
+	}
+	return nil, invalid("%s is not an object or array", path[0])
+}
+
+func get(root any, path []string) (any, error) {
+	node := root
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, invalid("%s does not exist", token)
+			}
+			node = child
+		case []any:
+			i, err := index(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, invalid("%s is not in an object or array", token)
+		}
+	}
+	return node, nil
+}
+
+func add(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return walk(root, path, func(parent any, key string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[key] = value
+			return p, nil
+		case []any:
+			i, err := index(key, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], append([]any{value}, p[i:]...)...), nil
+		}
+		return nil, invalid("%s is not in an object or array", key)
+	})
+}
+
+func remove(root any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, invalid("cannot remove the whole document")
+	}
+	var removed any
+	root, err := walk(root, path, func(parent any, key string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			value, ok := p[key]
+			if !ok {
+				return nil, invalid("%s does not exist", key)
+			}
+			removed = value
+			delete(p, key)
+			return p, nil
+		case []any:
+			i, err := index(key, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, invalid("%s is not in an object or array", key)
+	})
+	return root, removed, err
+}
+
+func move(root any, from []string, path []string) (any, error) {
+	root, value, err := remove(root, from)
+	if err != nil {
+		return nil, err
+	}
+	return add(root, path, value)
+}
+
+// clone copies a decoded JSON value, so a copied value does not share objects and arrays with the original
+func clone(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for name, member := range v {
+			c[name] = clone(member)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, element := range v {
+			c[i] = clone(element)
+		}
+		return c
+	}
+	return value
+}
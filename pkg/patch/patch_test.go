@@ -0,0 +1,103 @@
+package patch
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const doc = `{"name":"Ada","tags":["a","b"],"manager":{"id":1,"name":"Grace"},"end":"2024-01-31"}`
+
+func TestApply(t *testing.T) {
+	_, err := Apply("application/json", []byte(doc), []byte(`{}`))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	out, err := Apply(MergePatchType, []byte(doc), []byte(`{"name":"Grace"}`))
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"name":"Grace"`)
+}
+
+func TestMergePatch(t *testing.T) {
+	// the examples of RFC 7386, appendix A
+	for _, c := range []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		out, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if assert.NoError(t, err, c.patch) {
+			assert.JSONEq(t, c.want, string(out), c.patch)
+		}
+	}
+
+	_, err := MergePatch([]byte(doc), []byte(`{"name":`))
+	assert.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestJSONPatch(t *testing.T) {
+	for _, c := range []struct{ patch, want string }{
+		{`[{"op":"replace","path":"/name","value":"Grace"}]`,
+			`{"name":"Grace","tags":["a","b"],"manager":{"id":1,"name":"Grace"},"end":"2024-01-31"}`},
+		{`[{"op":"remove","path":"/end"},{"op":"add","path":"/tags/-","value":"c"}]`,
+			`{"name":"Ada","tags":["a","b","c"],"manager":{"id":1,"name":"Grace"}}`},
+		{`[{"op":"add","path":"/tags/0","value":"z"},{"op":"remove","path":"/tags/2"}]`,
+			`{"name":"Ada","tags":["z","a"],"manager":{"id":1,"name":"Grace"},"end":"2024-01-31"}`},
+		{`[{"op":"add","path":"/end","value":null}]`,
+			`{"name":"Ada","tags":["a","b"],"manager":{"id":1,"name":"Grace"},"end":null}`},
+		{`[{"op":"move","from":"/manager/name","path":"/boss"}]`,
+			`{"name":"Ada","tags":["a","b"],"manager":{"id":1},"boss":"Grace","end":"2024-01-31"}`},
+		{`[{"op":"copy","from":"/manager","path":"/mentor"},{"op":"replace","path":"/mentor/id","value":2}]`,
+			`{"name":"Ada","tags":["a","b"],"manager":{"id":1,"name":"Grace"},"mentor":{"id":2,"name":"Grace"},"end":"2024-01-31"}`},
+		{`[{"op":"test","path":"/manager/id","value":1},{"op":"replace","path":"/manager/id","value":2}]`,
+			`{"name":"Ada","tags":["a","b"],"manager":{"id":2,"name":"Grace"},"end":"2024-01-31"}`},
+		{`[{"op":"replace","path":"","value":{"a":1}}]`, `{"a":1}`},
+	} {
+		out, err := JSONPatch([]byte(doc), []byte(c.patch))
+		if assert.NoError(t, err, c.patch) {
+			assert.JSONEq(t, c.want, string(out), c.patch)
+		}
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	for patch, want := range map[string]error{
+		`{"op":"remove","path":"/end"}`:                            ErrInvalid,
+		`[{"op":"rename","path":"/end"}]`:                          ErrInvalid,
+		`[{"op":"replace","path":"/nickname","value":"A"}]`:        ErrInvalid,
+		`[{"op":"remove","path":"/tags/2"}]`:                       ErrInvalid,
+		`[{"op":"add","path":"/tags/01","value":"c"}]`:             ErrInvalid,
+		`[{"op":"add","path":"/a/b","value":1}]`:                   ErrInvalid,
+		`[{"op":"replace","path":"/name"}]`:                        ErrInvalid,
+		`[{"op":"replace","path":"name","value":"A"}]`:             ErrInvalid,
+		`[{"op":"move","from":"/manager","path":"/manager/boss"}]`: ErrInvalid,
+		`[{"op":"test","path":"/manager/id","value":"1"}]`:         ErrTestFailed,
+	} {
+		_, err := JSONPatch([]byte(doc), []byte(patch))
+		assert.True(t, errors.Is(err, want), "%s: %v", patch, err)
+	}
+}
+
+func TestJSONPatchIsAtomic(t *testing.T) {
+	out, err := JSONPatch([]byte(doc), []byte(`[{"op":"remove","path":"/end"},{"op":"test","path":"/name","value":"Grace"}]`))
+	assert.ErrorIs(t, err, ErrTestFailed)
+	assert.Nil(t, out)
+}
+
+func TestPointerEscapes(t *testing.T) {
+	out, err := JSONPatch([]byte(`{"a/b":1,"m~n":2}`), []byte(`[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`))
+	if assert.NoError(t, err) {
+		assert.JSONEq(t, `{"m~n":3}`, string(out))
+	}
+}
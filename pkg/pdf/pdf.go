@@ -0,0 +1,223 @@
+// Package pdf writes simple text documents as PDF. It knows just enough of the format to lay out headings, bullet
+// lists and wrapped paragraphs on A4 pages with the standard Helvetica fonts, so no fonts have to be embedded.
+//
+// Text is encoded with WinAnsiEncoding; characters outside of it are printed as '?'.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Style is the typographic role of a line
+type Style int
+
+const (
+	Body Style = iota
+	Title
+	Heading
+	Subheading
+	Bullet
+)
+
+// Block is a paragraph of text, wrapped to the width of the page when rendered
+type Block struct {
+	Style Style
+	Text  string
+}
+
+// page geometry in points, A4 with 2cm margins
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 56
+	// avgGlyphWidth approximates the width of a Helvetica glyph in ems, used to wrap lines
+	avgGlyphWidth = 0.5
+	bulletIndent  = 14
+)
+
+type font struct {
+	resource string
+	size     float64
+	// spaceBefore is the gap above a block of this style in points
+	spaceBefore float64
+}
+
+var fonts = map[Style]font{
+	Body:       {resource: "F1", size: 10, spaceBefore: 4},
+	Title:      {resource: "F2", size: 20, spaceBefore: 0},
+	Heading:    {resource: "F2", size: 14, spaceBefore: 14},
+	Subheading: {resource: "F2", size: 11, spaceBefore: 8},
+	Bullet:     {resource: "F1", size: 10, spaceBefore: 2},
+}
+
+// Render lays out blocks on as many pages as they need and returns the PDF document
+func Render(title string, blocks []Block) []byte {
+	var pages []string
+	var content bytes.Buffer
+	y := float64(pageHeight - margin)
+	for _, block := range blocks {
+		f := fonts[block.Style]
+		lineHeight := f.size * 1.3
+		x, width := float64(margin), float64(pageWidth-2*margin)
+		if block.Style == Bullet {
+			x, width = x+bulletIndent, width-bulletIndent
+		}
+		y -= f.spaceBefore
+		for i, line := range wrap(block.Text, int(width/(f.size*avgGlyphWidth))) {
+			if y-lineHeight < margin {
+				pages = append(pages, content.String())
+				content.Reset()
+				y = pageHeight - margin
+			}
+			y -= lineHeight
+			if block.Style == Bullet && i == 0 {
+				fmt.Fprintf(&content, "BT /%s %g Tf %g %g Td (%s) Tj ET\n", f.resource, f.size, x-bulletIndent+4, y,
+					escape("•"))
+			}
+			fmt.Fprintf(&content, "BT /%s %g Tf %g %g Td (%s) Tj ET\n", f.resource, f.size, x, y, escape(line))
+		}
+	}
+	pages = append(pages, content.String())
+	return assemble(title, pages)
+}
+
+// wrap breaks text into lines of at most width characters, at spaces where possible
+func wrap(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	var lines []string
+	var line []rune
+	for _, word := range words {
+		w := []rune(word)
+		for len(w) > width {
+			if len(line) > 0 {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		switch {
+		case len(line) == 0:
+			line = w
+		case len(line)+1+len(w) <= width:
+			line = append(append(line, ' '), w...)
+		default:
+			lines = append(lines, string(line))
+			line = w
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// winAnsi maps the characters of WinAnsiEncoding outside of Latin-1 to their codes
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95, '–': 0x96, '—': 0x97, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '™': 0x99,
+}
+
+// escape encodes s as the contents of a PDF string literal
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// assemble writes the objects of the document followed by the cross-reference table
+func assemble(title string, pages []string) []byte {
+	// objects 1 to 4 are the catalog, the page tree and the two fonts, then each page is followed by its content
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (esmAPI) >>", escape(title)),
+	)
+	for i, content := range pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R "+
+				"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> >>", pageWidth, pageHeight, 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(content), content),
+		)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// FromMarkdown converts the subset of Markdown used by plain documents into blocks: "#" to "###" headings, "-" and
+// "*" bullets and paragraphs separated by blank lines. Emphasis markers are dropped.
+func FromMarkdown(md string) []Block {
+	var blocks []Block
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, Block{Style: Body, Text: strings.Join(paragraph, " ")})
+			paragraph = nil
+		}
+	}
+	plain := strings.NewReplacer("**", "", "__", "", "`", "")
+	for _, line := range strings.Split(md, "\n") {
+		line = plain.Replace(strings.TrimSpace(line))
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "# "):
+			flush()
+			blocks = append(blocks, Block{Style: Title, Text: line[2:]})
+		case strings.HasPrefix(line, "## "):
+			flush()
+			blocks = append(blocks, Block{Style: Heading, Text: line[3:]})
+		case strings.HasPrefix(line, "### "):
+			flush()
+			blocks = append(blocks, Block{Style: Subheading, Text: line[4:]})
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			flush()
+			blocks = append(blocks, Block{Style: Bullet, Text: line[2:]})
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+	return blocks
+}
@@ -0,0 +1,55 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	blocks := FromMarkdown("# Ada Lovelace\n\n**Focus area:** Math\nand engines\n\n## Skills\n### Languages\n- English: 5\n* French\n")
+	assert.Equal(t, []Block{
+		{Style: Title, Text: "Ada Lovelace"},
+		{Style: Body, Text: "Focus area: Math and engines"},
+		{Style: Heading, Text: "Skills"},
+		{Style: Subheading, Text: "Languages"},
+		{Style: Bullet, Text: "English: 5"},
+		{Style: Bullet, Text: "French"},
+	}, blocks)
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"the quick", "brown fox"}, wrap("the quick brown fox", 10))
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, wrap("abcdefghij", 4), "words longer than a line are split")
+	assert.Nil(t, wrap("   ", 10))
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\(b\)\\ \351 \200 ?`, escape("a(b)\\ é € 日"))
+}
+
+func TestRender(t *testing.T) {
+	var blocks []Block
+	for i := 0; i < 100; i++ {
+		blocks = append(blocks, Block{Style: Bullet, Text: fmt.Sprintf("item %d", i)})
+	}
+	doc := Render("Long (list)", blocks)
+	assert.True(t, bytes.HasPrefix(doc, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(doc, []byte("%%EOF\n")))
+	assert.Contains(t, string(doc), "/Count 3", "100 bullets need three pages")
+	assert.Contains(t, string(doc), `/Title (Long \(list\))`)
+
+	// every entry of the cross-reference table points at its object
+	s := string(doc)
+	xref := s[strings.LastIndex(s, "xref\n"):]
+	for i, line := range strings.Split(xref, "\n")[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		var offset int
+		fmt.Sscanf(line, "%d", &offset)
+		assert.True(t, strings.HasPrefix(s[offset:], fmt.Sprintf("%d 0 obj", i+1)), "object %d", i+1)
+	}
+}
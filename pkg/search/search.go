@@ -0,0 +1,314 @@
+// Package search is an in-memory full-text index with typo tolerance. Documents are split into lower case terms;
+// a query term matches a document term exactly, as a prefix, or within a small edit distance. Hits are ranked by how
+// well and how many query terms match, weighted by the rarity of the matched terms and the weight of their fields.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Document is something to find. Kind and Id identify it, Title names it in results.
+type Document struct {
+	Kind   string
+	Id     int64
+	Title  string
+	Fields []Field
+}
+
+// Field is a text of a document, matches in fields with a higher Weight rank higher
+type Field struct {
+	Name   string
+	Text   string
+	Weight float64
+}
+
+// Hit is a document matching a query
+type Hit struct {
+	Kind  string
+	Id    int64
+	Title string
+	Score float64
+	// Fields are the names of the fields that matched
+	Fields []string
+}
+
+// how much the kinds of matches count, relative to an exact match
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.8
+	fuzzyMatch  = 0.6
+	// minPrefix is the shortest query term matched as a prefix
+	minPrefix = 2
+)
+
+type key struct {
+	kind string
+	id   int64
+}
+
+type posting struct {
+	doc    key
+	field  int
+	weight float64
+}
+
+// Index is safe for concurrent use
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[key]Document
+	postings map[string][]posting
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{docs: make(map[key]Document), postings: make(map[string][]posting)}
+}
+
+// Terms splits text into lower case words of letters and digits
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Put adds doc, replacing the document of the same kind and id
+func (idx *Index) Put(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.put(doc)
+}
+
+func (idx *Index) put(doc Document) {
+	k := key{doc.Kind, doc.Id}
+	idx.remove(k)
+	idx.docs[k] = doc
+	for i, f := range doc.Fields {
+		seen := make(map[string]bool)
+		for _, term := range Terms(f.Text) {
+			if !seen[term] {
+				seen[term] = true
+				idx.postings[term] = append(idx.postings[term], posting{doc: k, field: i, weight: f.Weight})
+			}
+		}
+	}
+}
+
+// Delete removes the document of kind and id if it is indexed
+func (idx *Index) Delete(kind string, id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(key{kind, id})
+}
+
+// Replace swaps all documents of kind for docs
+func (idx *Index) Replace(kind string, docs []Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for k := range idx.docs {
+		if k.kind == kind {
+			idx.remove(k)
+		}
+	}
+	for _, doc := range docs {
+		doc.Kind = kind
+		idx.put(doc)
+	}
+}
+
+func (idx *Index) remove(k key) {
+	doc, ok := idx.docs[k]
+	if !ok {
+		return
+	}
+	delete(idx.docs, k)
+	for _, f := range doc.Fields {
+		for _, term := range Terms(f.Text) {
+			list := idx.postings[term]
+			kept := list[:0]
+			for _, p := range list {
+				if p.doc != k {
+					kept = append(kept, p)
+				}
+			}
+			if len(kept) == 0 {
+				delete(idx.postings, term)
+			} else {
+				idx.postings[term] = kept
+			}
+		}
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// maxEdits is how many typos a query term of n letters tolerates
+func maxEdits(n int) int {
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// matchQuality rates how well the document term matches the query term, 0 if it does not
+func matchQuality(query string, term string) float64 {
+	if query == term {
+		return exactMatch
+	}
+	if len(query) >= minPrefix && strings.HasPrefix(term, query) {
+		return prefixMatch
+	}
+	q, t := []rune(query), []rune(term)
+	edits := maxEdits(len(q))
+	if edits == 0 {
+		return 0
+	}
+	if d := distance(q, t, edits); d <= edits {
+		return fuzzyMatch / float64(d)
+	}
+	return 0
+}
+
+// Distance returns how many insertions, deletions, substitutions and transpositions of adjacent letters turn a into
+// b, or limit+1 once that exceeds limit
+func Distance(a string, b string, limit int) int {
+	return distance([]rune(a), []rune(b), limit)
+}
+
+// distance returns the optimal string alignment distance of a and b, or limit+1 once it exceeds limit
+func distance(a []rune, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		best := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			best = min(best, curr[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Search returns up to limit documents matching any term of query, the best first. kinds limits the kinds of the
+// documents, none means all.
+func (idx *Index) Search(query string, limit int, kinds ...string) []Hit {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+	terms := Terms(query)
+	type result struct {
+		score   float64
+		matched int
+		fields  map[int]bool
+	}
+	results := make(map[key]*result)
+	for _, q := range terms {
+		// the best match of the query term in each document
+		best := make(map[key]float64)
+		fields := make(map[key]map[int]bool)
+		for term, list := range idx.postings {
+			quality := matchQuality(q, term)
+			if quality == 0 {
+				continue
+			}
+			idf := math.Log(1 + float64(len(idx.docs))/float64(len(list)))
+			for _, p := range list {
+				if len(wanted) > 0 && !wanted[p.doc.kind] {
+					continue
+				}
+				if score := quality * p.weight * idf; score > best[p.doc] {
+					best[p.doc] = score
+				}
+				if fields[p.doc] == nil {
+					fields[p.doc] = make(map[int]bool)
+				}
+				fields[p.doc][p.field] = true
+			}
+		}
+		for k, score := range best {
+			r := results[k]
+			if r == nil {
+				r = &result{fields: make(map[int]bool)}
+				results[k] = r
+			}
+			r.score += score
+			r.matched++
+			for f := range fields[k] {
+				r.fields[f] = true
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(results))
+	for k, r := range results {
+		doc := idx.docs[k]
+		hit := Hit{
+			Kind:  k.kind,
+			Id:    k.id,
+			Title: doc.Title,
+			// documents matching more of the query rank higher
+			Score: math.Round(r.score*float64(r.matched)/float64(len(terms))*1000) / 1000,
+		}
+		for i, f := range doc.Fields {
+			if r.fields[i] {
+				hit.Fields = append(hit.Fields, f.Name)
+			}
+		}
+		hits = append(hits, hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Kind != hits[j].Kind {
+			return hits[i].Kind < hits[j].Kind
+		}
+		return hits[i].Id < hits[j].Id
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
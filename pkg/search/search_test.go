@@ -0,0 +1,84 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func testIndex() *Index {
+	idx := NewIndex()
+	idx.Replace("employee", []Document{
+		{Id: 1, Title: "Ada Lovelace", Fields: []Field{{Name: "name", Text: "Ada", Weight: 3}, {Name: "lastname", Text: "Lovelace", Weight: 3}, {Name: "focus_area", Text: "Kubernetes platform", Weight: 1}}},
+		{Id: 2, Title: "Grace Hopper", Fields: []Field{{Name: "name", Text: "Grace", Weight: 3}, {Name: "lastname", Text: "Hopper", Weight: 3}, {Name: "focus_area", Text: "Compilers", Weight: 1}}},
+	})
+	idx.Replace("skill", []Document{
+		{Id: 10, Title: "Kubernetes", Fields: []Field{{Name: "skill", Text: "Kubernetes", Weight: 3}}},
+		{Id: 11, Title: "Go", Fields: []Field{{Name: "skill", Text: "Go", Weight: 3}}},
+	})
+	return idx
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"ada", "lovelace", "ada", "example", "com"}, Terms("Ada Lovelace <ada@example.com>"))
+	assert.Empty(t, Terms(" -- "))
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, distance([]rune("go"), []rune("go"), 2))
+	assert.Equal(t, 1, distance([]rune("kubernets"), []rune("kubernetes"), 2))
+	assert.Equal(t, 1, distance([]rune("hopepr"), []rune("hopper"), 2), "a transposition is one edit")
+	assert.Equal(t, 3, distance([]rune("abc"), []rune("xyzabc"), 2), "stops past the limit")
+}
+
+func TestSearchRanking(t *testing.T) {
+	hits := testIndex().Search("kubernetes", 0)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, "skill", hits[0].Kind, "the name outweighs the focus area")
+		assert.Equal(t, []string{"skill"}, hits[0].Fields)
+		assert.Equal(t, int64(1), hits[1].Id)
+		assert.Equal(t, []string{"focus_area"}, hits[1].Fields)
+	}
+}
+
+func TestSearchTypos(t *testing.T) {
+	idx := testIndex()
+	for query, id := range map[string]int64{"lovelcae": 1, "Hoper": 2, "grac": 2, "kubernets": 10} {
+		hits := idx.Search(query, 1, "employee", "skill")
+		if assert.NotEmpty(t, hits, query) {
+			assert.Equal(t, id, hits[0].Id, query)
+		}
+	}
+	assert.Empty(t, idx.Search("ga", 0), "short terms need no typos")
+}
+
+func TestSearchAllTermsRankFirst(t *testing.T) {
+	hits := testIndex().Search("ada lovelace hopper", 0)
+	if assert.Len(t, hits, 2) {
+		assert.Equal(t, int64(1), hits[0].Id, "more matched terms")
+	}
+}
+
+func TestSearchKindsAndLimit(t *testing.T) {
+	idx := testIndex()
+	hits := idx.Search("kubernetes", 0, "employee")
+	if assert.Len(t, hits, 1) {
+		assert.Equal(t, "employee", hits[0].Kind)
+	}
+	assert.Len(t, idx.Search("kubernetes", 1), 1)
+}
+
+func TestDeleteAndReplace(t *testing.T) {
+	idx := testIndex()
+	idx.Delete("employee", 2)
+	assert.Empty(t, idx.Search("hopper", 0))
+	assert.Equal(t, 3, idx.Len())
+
+	idx.Replace("skill", []Document{{Id: 12, Title: "Rust", Fields: []Field{{Name: "skill", Text: "Rust", Weight: 3}}}})
+	assert.Equal(t, 2, idx.Len())
+	assert.Empty(t, idx.Search("go", 0))
+	assert.Len(t, idx.Search("rust", 0), 1)
+
+	idx.Put(Document{Kind: "employee", Id: 1, Title: "Ada King", Fields: []Field{{Name: "lastname", Text: "King", Weight: 3}}})
+	assert.Empty(t, idx.Search("lovelace", 0), "Put replaces the document")
+	assert.Len(t, idx.Search("king", 0), 1)
+}
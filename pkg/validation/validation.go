@@ -0,0 +1,154 @@
+// Package validation checks the payloads of the API against the rules declared in the `validate` struct tags
+// of the instances types. It does not depend on the transport, so every API surface reports invalid input
+// with the same field-level errors.
+package validation
+
+import (
+	"errors"
+	"esmAPI/pkg/instances"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+)
+
+// FieldError describes one violated rule. Field is the JSON name of the offending field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors is the list of all violations found in a payload
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// report fields by the names clients know them by
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Struct validates v and returns Errors listing every violated rule, or nil if v is valid
+func Struct(v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	result := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		result = append(result, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(fe),
+		})
+	}
+	return result
+}
+
+// fieldPath strips the top level type from the namespace, e.g. Employee.email becomes email
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+func message(fe validator.FieldError) string {
+	field := fieldPath(fe)
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters long", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters long", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "unique":
+		if fe.Param() != "" {
+			return fmt.Sprintf("%s must not repeat a %s", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must not contain duplicates", field)
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
+
+// SkillLevel checks that level is defined on scale. The error lists the valid levels, so clients can correct
+// the request without looking the scale up first.
+func SkillLevel(scale instances.SkillScale, level int64) error {
+	return ScaleLevel("skill_level", scale, level)
+}
+
+// ScaleLevel is SkillLevel for a level sent in another field than skill_level, e.g. the min_level of a requirement
+func ScaleLevel(field string, scale instances.SkillScale, level int64) error {
+	if _, ok := scale.Find(int(level)); ok {
+		return nil
+	}
+	valid := make([]string, len(scale.Levels))
+	for i, l := range scale.Levels {
+		valid[i] = fmt.Sprintf("%d (%s)", l.Level, l.Label)
+	}
+	return Errors{{
+		Field: field,
+		Rule:  "scale",
+		Param: scale.Name,
+		Message: fmt.Sprintf("%s %d is not defined on the %s scale, valid levels are %s",
+			field, level, scale.Name, strings.Join(valid, ", ")),
+	}}
+}
+
+// Period checks that a period does not end before it starts. A nil end is an open period and always valid.
+func Period(startField string, start instances.Date, endField string, end *instances.Date) error {
+	if end == nil || !end.Before(start.Time) {
+		return nil
+	}
+	return Errors{{
+		Field:   endField,
+		Rule:    "period",
+		Param:   startField,
+		Message: fmt.Sprintf("%s must not be before %s", endField, startField),
+	}}
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"errors"
+	"esmAPI/pkg/instances"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidEmployee(t *testing.T) {
+	emp := instances.Employee{Name: "John", Lastname: "Doe", Email: "john.doe@company.co"}
+	assert.NoError(t, Struct(emp))
+}
+
+func TestInvalidEmployeeListsEveryField(t *testing.T) {
+	emp := instances.Employee{EmployeeId: -1, Lastname: strings.Repeat("x", 256), Email: "not-an-email"}
+	err := Struct(emp)
+
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	fields := map[string]string{}
+	for _, fe := range verrs {
+		fields[fe.Field] = fe.Rule
+	}
+	assert.Equal(t, map[string]string{
+		"employee_id": "gte",
+		"name":        "required",
+		"lastname":    "max",
+		"email":       "email",
+	}, fields)
+}
+
+// the upper bound depends on the scale of the skill class, see TestSkillLevelOnScale
+func TestEmployeeSkillLevelNotNegative(t *testing.T) {
+	assert.NoError(t, Struct(instances.EmployeeSkill{SkillId: 1, SkillLevel: 5}))
+
+	err := Struct(instances.EmployeeSkill{SkillId: 1, SkillLevel: -2})
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	assert.Len(t, verrs, 1)
+	assert.Equal(t, "skill_level", verrs[0].Field)
+	assert.Equal(t, "skill_level must be greater than or equal to 0", verrs[0].Message)
+}
+
+func TestAssessmentSource(t *testing.T) {
+	assert.NoError(t, Struct(instances.EmployeeSkill{SkillId: 1, SkillLevel: 3, Source: instances.AssessmentPeer}))
+
+	err := Struct(instances.EmployeeSkill{SkillId: 1, SkillLevel: 3, Source: "boss"})
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	assert.Equal(t, "source must be one of self, manager, peer, certification", verrs[0].Message)
+}
+
+func TestOtherPayloads(t *testing.T) {
+	assert.Error(t, Struct(instances.Skill{SkillClass: "Cloud"}))
+	assert.Error(t, Struct(instances.Client{}))
+	assert.Error(t, Struct(instances.Project{ClientId: -1}))
+	assert.Error(t, Struct(instances.EmployeeProject{ProjectId: 1}))
+	assert.NoError(t, Struct(instances.EmployeeProject{ProjectId: 1, ProjectRole: "Lead Developer"}))
+}
+
+func TestSkillLevelOnScale(t *testing.T) {
+	cefr := instances.SkillScale{SkillClass: "Language", Name: "CEFR", Levels: []instances.SkillScaleLevel{
+		{Level: 1, Label: "A1"}, {Level: 2, Label: "A2"}, {Level: 3, Label: "B1"},
+	}}
+	assert.NoError(t, SkillLevel(cefr, 3))
+
+	err := SkillLevel(cefr, 4)
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	assert.Equal(t, "skill_level 4 is not defined on the CEFR scale, valid levels are 1 (A1), 2 (A2), 3 (B1)",
+		verrs[0].Message)
+
+	assert.NoError(t, SkillLevel(instances.DefaultSkillScale, 5))
+	assert.Error(t, SkillLevel(instances.DefaultSkillScale, 0))
+
+	assert.True(t, errors.As(ScaleLevel("min_level", cefr, 4), &verrs))
+	assert.Equal(t, "min_level", verrs[0].Field)
+}
+
+func TestSkillScaleRejectsDuplicateLevels(t *testing.T) {
+	scale := instances.SkillScale{SkillClass: "Language", Name: "CEFR", Levels: []instances.SkillScaleLevel{
+		{Level: 1, Label: "A1"}, {Level: 1, Label: "A2"},
+	}}
+	err := Struct(scale)
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	assert.Equal(t, "levels", verrs[0].Field)
+	assert.Equal(t, "unique", verrs[0].Rule)
+}
+
+func TestCertification(t *testing.T) {
+	issued := instances.NewDate(2024, time.May, 1)
+	cert := instances.Certification{EmployeeId: 1, Name: "CKA", Issuer: "CNCF", IssuedOn: issued}
+	assert.NoError(t, Struct(cert))
+	assert.Error(t, Struct(instances.Certification{EmployeeId: 1, Name: "CKA", Issuer: "CNCF"}), "issued_on is required")
+
+	expires := instances.NewDate(2024, time.April, 30)
+	err := Period("issued_on", issued, "expires_on", &expires)
+	var verrs Errors
+	assert.True(t, errors.As(err, &verrs))
+	assert.Equal(t, "expires_on must not be before issued_on", verrs[0].Message)
+	assert.NoError(t, Period("issued_on", issued, "expires_on", nil))
+}
@@ -1,9 +1,21 @@
+DROP TABLE IF EXISTS schema_migrations;
+DROP TABLE IF EXISTS Merges;
+DROP TABLE IF EXISTS ProjectRequirements;
+DROP TABLE IF EXISTS Certifications;
+DROP TABLE IF EXISTS SkillChangeRequests;
+DROP TABLE IF EXISTS SkillOwners;
+DROP TABLE IF EXISTS SkillAssessments;
+DROP TABLE IF EXISTS SkillAliases;
+DROP TABLE IF EXISTS SkillScaleLevels;
+DROP TABLE IF EXISTS SkillScales;
 DROP TABLE IF EXISTS ProjectDetails;
 DROP TABLE IF EXISTS Projects; 
 DROP TABLE IF EXISTS Clients; 
 DROP TABLE IF EXISTS EmployeeSkills;
 DROP TABLE IF EXISTS Employees;
+DROP TABLE IF EXISTS Departments;
 DROP TABLE IF EXISTS Skills;
+DROP TABLE IF EXISTS SkillCategories;
 -- Create Clients Table
 CREATE TABLE Clients (
     id INT PRIMARY KEY,